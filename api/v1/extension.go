@@ -1,10 +1,226 @@
 package v1
 
 import (
+	"encoding/json"
+
+	externalRef0 "github.com/kubev2v/migration-planner/api/v1alpha1"
+
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 )
 
 func (a *AgentStatus) FromModel(m models.AgentStatus) {
+	a.Id = m.ID
+	a.SourceId = m.SourceID
 	a.ConsoleConnection = AgentStatusConsoleConnection(m.Console.Current)
 	a.Mode = AgentStatusMode(m.Console.Target)
+	a.CollectorStatus = AgentStatusCollectorStatus(m.Collector)
+
+	if m.Version != "" {
+		a.Version = &m.Version
+	}
+	if m.Console.StopReason != "" {
+		reason := AgentStatusStopReason(m.Console.StopReason)
+		a.StopReason = &reason
+	}
+	if m.Console.Error != nil {
+		e := m.Console.Error.Error()
+		a.Error = &e
+	}
+	if !m.Console.LastStatusUpdate.IsZero() {
+		a.LastStatusUpdate = &m.Console.LastStatusUpdate
+	}
+	if !m.Console.LastInventoryUpdate.IsZero() {
+		a.LastInventoryUpdate = &m.Console.LastInventoryUpdate
+	}
+	a.StatusBackoff.FromModel(m.Console.StatusBackoff)
+	a.InventoryBackoff.FromModel(m.Console.InventoryBackoff)
+}
+
+func (b *PushBackoff) FromModel(m models.BackoffStatus) {
+	b.ConsecutiveFailures = m.ConsecutiveFailures
+	b.BackoffSeconds = m.Delay.Seconds()
+	if m.Delay > 0 {
+		b.NextAttemptAt = &m.NextAttempt
+	}
+}
+
+// FromModel sets the data-sharing consent held by the agent settings.
+func (c *Consent) FromModel(m models.AgentSettings) {
+	c.Granted = m.DataSharingAllowed
+	if m.ConsentTermsVersion != "" {
+		c.TermsVersion = &m.ConsentTermsVersion
+	}
+	if !m.ConsentSetAt.IsZero() {
+		c.UpdatedAt = &m.ConsentSetAt
+	}
+	if m.ConsentSetBy != "" {
+		c.UpdatedBy = &m.ConsentSetBy
+	}
+}
+
+// FromModel sets the requests sent next to console. The status is decoded from the body of the status request.
+func (o *Outbox) FromModel(m models.Outbox) error {
+	if err := json.Unmarshal(m.Status.Body, &o.Status); err != nil {
+		return err
+	}
+	o.StatusUrl = m.Status.URL
+
+	if m.Inventory != nil {
+		o.Inventory = m.Inventory.Body
+		o.InventoryUrl = &m.Inventory.URL
+		o.InventoryCompressed = &m.Inventory.Compressed
+		o.InventoryHash = &m.InventoryHash
+	}
+	if m.InventoryWithheld != "" {
+		reason := OutboxInventoryWithheldReason(m.InventoryWithheld)
+		o.InventoryWithheldReason = &reason
+	}
+	if m.LastUploadHash != "" {
+		o.LastUploadHash = &m.LastUploadHash
+	}
+	if !m.LastUploadAt.IsZero() {
+		o.LastUploadAt = &m.LastUploadAt
+	}
+	return nil
+}
+
+// FromModel sets the snapshot metadata. The inventory is decoded from the data when present.
+func (s *InventorySnapshot) FromModel(m models.Inventory) error {
+	s.Id = m.ID
+	s.StartedAt = m.StartedAt
+	s.CompletedAt = m.CompletedAt
+	s.VcenterUrl = m.VCenterURL
+	s.Hash = m.Hash
+
+	if len(m.Data) > 0 {
+		var inventory externalRef0.Inventory
+		if err := json.Unmarshal(m.Data, &inventory); err != nil {
+			return err
+		}
+		s.Inventory = &inventory
+	}
+	return nil
+}
+
+func (d *InventoryDiff) FromModel(m models.InventoryDiff) {
+	d.From = m.From
+	d.To = m.To
+	d.VmsCompared = m.VMsCompared
+	d.Counts = InventoryCounts{
+		Vms:        CountChange{From: m.VMs.From, To: m.VMs.To},
+		Hosts:      CountChange{From: m.Hosts.From, To: m.Hosts.To},
+		Clusters:   CountChange{From: m.Clusters.From, To: m.Clusters.To},
+		Datastores: CountChange{From: m.Datastores.From, To: m.Datastores.To},
+	}
+
+	d.AddedVms = make([]VmSummary, 0, len(m.AddedVMs))
+	for _, vm := range m.AddedVMs {
+		d.AddedVms = append(d.AddedVms, VmSummary{Id: vm.ID, Name: vm.Name})
+	}
+	d.RemovedVms = make([]VmSummary, 0, len(m.RemovedVMs))
+	for _, vm := range m.RemovedVMs {
+		d.RemovedVms = append(d.RemovedVms, VmSummary{Id: vm.ID, Name: vm.Name})
+	}
+	d.ChangedVms = make([]VmChange, 0, len(m.ChangedVMs))
+	for _, vm := range m.ChangedVMs {
+		change := VmChange{Id: vm.ID, Name: vm.Name, Changes: make([]FieldChange, 0, len(vm.Changes))}
+		for _, c := range vm.Changes {
+			change.Changes = append(change.Changes, FieldChange{Field: c.Field, From: c.From, To: c.To})
+		}
+		d.ChangedVms = append(d.ChangedVms, change)
+	}
+}
+
+func (p *CollectorProgress) FromModel(m models.CollectorProgress) {
+	p.Phase = CollectorProgressPhase(m.Phase)
+	p.StartedAt = m.StartedAt
+	p.ElapsedSeconds = int64(m.Elapsed.Seconds())
+	p.Vms = m.VMs
+	p.Hosts = m.Hosts
+	p.Datastores = m.Datastores
+
+	if !m.EstimatedCompletion.IsZero() {
+		p.EstimatedCompletion = &m.EstimatedCompletion
+	}
+}
+
+func (p *Preflight) FromModel(m models.Preflight) {
+	p.CheckedAt = m.CheckedAt
+	p.Passed = m.Passed()
+	p.MissingPrivileges = make([]MissingPrivileges, 0, len(m.MissingPrivileges))
+	for _, missing := range m.MissingPrivileges {
+		p.MissingPrivileges = append(p.MissingPrivileges, MissingPrivileges{
+			Entity:     missing.Entity,
+			EntityType: missing.EntityType,
+			EntityName: missing.EntityName,
+			Privileges: missing.Privileges,
+		})
+	}
+}
+
+func (s *CollectionSchedule) FromModel(m models.CollectionSchedule) {
+	s.NextRunAt = m.NextRun
+
+	if m.Interval > 0 {
+		interval := m.Interval.String()
+		s.Interval = &interval
+	}
+	if m.Cron != "" {
+		s.Cron = &m.Cron
+	}
+	if m.LastRun != nil {
+		s.LastRun = &ScheduledRun{
+			StartedAt: m.LastRun.StartedAt,
+			Status:    ScheduledRunStatus(m.LastRun.Status),
+		}
+		if m.LastRun.Error != "" {
+			s.LastRun.Error = &m.LastRun.Error
+		}
+	}
+}
+
+func (r *CollectionRun) FromModel(m models.CollectionRun) {
+	r.Id = m.ID
+	r.Trigger = CollectionRunTrigger(m.Trigger)
+	r.VcenterUrl = m.VCenterURL
+	r.State = CollectionRunState(m.State)
+	r.StartedAt = m.StartedAt
+	r.Vms = m.VMs
+	r.Hosts = m.Hosts
+	r.Datastores = m.Datastores
+	r.Incremental = m.Incremental
+
+	if m.Error != "" {
+		r.Error = &m.Error
+	}
+	if !m.EndedAt.IsZero() {
+		r.EndedAt = &m.EndedAt
+	}
+	if m.SnapshotID != 0 {
+		r.SnapshotId = &m.SnapshotID
+	}
+}
+
+func (v *VCenter) FromModel(m models.VCenter) {
+	v.Name = m.Name
+	v.Url = m.Credentials.URL
+	v.Username = m.Credentials.Username
+	v.State = VCenterState(m.State)
+	v.CreatedAt = m.CreatedAt
+	v.UpdatedAt = m.UpdatedAt
+
+	if m.Error != "" {
+		v.Error = &m.Error
+	}
+	if !m.CollectedAt.IsZero() {
+		v.CollectedAt = &m.CollectedAt
+	}
+	if m.Progress != nil {
+		v.Progress = &CollectorProgress{}
+		v.Progress.FromModel(*m.Progress)
+	}
+	if m.Preflight != nil {
+		v.Preflight = &Preflight{}
+		v.Preflight.FromModel(*m.Preflight)
+	}
 }
@@ -4,7 +4,11 @@
 package v1
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/oapi-codegen/runtime"
 )
 
 // ServerInterface represents all server handlers.
@@ -15,6 +19,18 @@ type ServerInterface interface {
 	// Change agent mode
 	// (POST /agent)
 	SetAgentMode(c *gin.Context)
+	// Get data-sharing consent
+	// (GET /agent/consent)
+	GetAgentConsent(c *gin.Context)
+	// Grant or revoke data-sharing consent
+	// (PUT /agent/consent)
+	SetAgentConsent(c *gin.Context)
+	// Preview the inventory sent to console
+	// (GET /agent/inventory/preview)
+	GetInventoryPreview(c *gin.Context)
+	// Show what the agent sends next to console
+	// (GET /agent/outbox)
+	GetAgentOutbox(c *gin.Context)
 	// Stop collection
 	// (DELETE /collector)
 	StopCollector(c *gin.Context)
@@ -23,10 +39,70 @@ type ServerInterface interface {
 	GetCollectorStatus(c *gin.Context)
 	// Start inventory collection
 	// (POST /collector)
-	StartCollector(c *gin.Context)
+	StartCollector(c *gin.Context, params StartCollectorParams)
 	// Get collected inventory
 	// (GET /collector/inventory)
 	GetInventory(c *gin.Context)
+	// Compare two inventory snapshots
+	// (GET /collector/inventory/diff)
+	GetInventoryDiff(c *gin.Context, params GetInventoryDiffParams)
+	// List inventory snapshots
+	// (GET /collector/inventory/snapshots)
+	ListInventorySnapshots(c *gin.Context)
+	// Get an inventory snapshot
+	// (GET /collector/inventory/snapshots/{id})
+	GetInventorySnapshot(c *gin.Context, id int64)
+	// List collection runs
+	// (GET /collector/runs)
+	ListCollectionRuns(c *gin.Context, params ListCollectionRunsParams)
+	// Remove the collection schedule
+	// (DELETE /collector/schedule)
+	DeleteCollectionSchedule(c *gin.Context)
+	// Get the collection schedule
+	// (GET /collector/schedule)
+	GetCollectionSchedule(c *gin.Context)
+	// Set the collection schedule
+	// (PUT /collector/schedule)
+	SetCollectionSchedule(c *gin.Context)
+	// Verify vCenter credentials and privileges
+	// (POST /collector/verify)
+	VerifyCollector(c *gin.Context)
+	// List vCenters
+	// (GET /vcenters)
+	ListVCenters(c *gin.Context)
+	// Add a vCenter
+	// (POST /vcenters)
+	CreateVCenter(c *gin.Context)
+	// Remove a vCenter
+	// (DELETE /vcenters/{name})
+	DeleteVCenter(c *gin.Context, name string)
+	// Get a vCenter
+	// (GET /vcenters/{name})
+	GetVCenter(c *gin.Context, name string)
+	// Update the credentials of a vCenter
+	// (PUT /vcenters/{name})
+	UpdateVCenter(c *gin.Context, name string)
+	// Stop the collection of a vCenter
+	// (DELETE /vcenters/{name}/collect)
+	StopVCenterCollection(c *gin.Context, name string)
+	// Collect a vCenter
+	// (POST /vcenters/{name}/collect)
+	CollectVCenter(c *gin.Context, name string, params CollectVCenterParams)
+	// Get the inventory of a vCenter
+	// (GET /vcenters/{name}/inventory)
+	GetVCenterInventory(c *gin.Context, name string)
+	// Compare two inventory snapshots of a vCenter
+	// (GET /vcenters/{name}/inventory/diff)
+	GetVCenterInventoryDiff(c *gin.Context, name string, params GetVCenterInventoryDiffParams)
+	// List the inventory snapshots of a vCenter
+	// (GET /vcenters/{name}/inventory/snapshots)
+	ListVCenterInventorySnapshots(c *gin.Context, name string)
+	// Get an inventory snapshot of a vCenter
+	// (GET /vcenters/{name}/inventory/snapshots/{id})
+	GetVCenterInventorySnapshot(c *gin.Context, name string, id int64)
+	// List the collection runs of a vCenter
+	// (GET /vcenters/{name}/runs)
+	ListVCenterCollectionRuns(c *gin.Context, name string, params ListVCenterCollectionRunsParams)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -64,6 +140,58 @@ func (siw *ServerInterfaceWrapper) SetAgentMode(c *gin.Context) {
 	siw.Handler.SetAgentMode(c)
 }
 
+// GetAgentConsent operation middleware
+func (siw *ServerInterfaceWrapper) GetAgentConsent(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAgentConsent(c)
+}
+
+// SetAgentConsent operation middleware
+func (siw *ServerInterfaceWrapper) SetAgentConsent(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SetAgentConsent(c)
+}
+
+// GetInventoryPreview operation middleware
+func (siw *ServerInterfaceWrapper) GetInventoryPreview(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetInventoryPreview(c)
+}
+
+// GetAgentOutbox operation middleware
+func (siw *ServerInterfaceWrapper) GetAgentOutbox(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetAgentOutbox(c)
+}
+
 // StopCollector operation middleware
 func (siw *ServerInterfaceWrapper) StopCollector(c *gin.Context) {
 
@@ -93,6 +221,19 @@ func (siw *ServerInterfaceWrapper) GetCollectorStatus(c *gin.Context) {
 // StartCollector operation middleware
 func (siw *ServerInterfaceWrapper) StartCollector(c *gin.Context) {
 
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params StartCollectorParams
+
+	// ------------- Optional query parameter "full_resync" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "full_resync", c.Request.URL.Query(), &params.FullResync)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter full_resync: %w", err), http.StatusBadRequest)
+		return
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -100,7 +241,7 @@ func (siw *ServerInterfaceWrapper) StartCollector(c *gin.Context) {
 		}
 	}
 
-	siw.Handler.StartCollector(c)
+	siw.Handler.StartCollector(c, params)
 }
 
 // GetInventory operation middleware
@@ -116,6 +257,515 @@ func (siw *ServerInterfaceWrapper) GetInventory(c *gin.Context) {
 	siw.Handler.GetInventory(c)
 }
 
+// GetInventoryDiff operation middleware
+func (siw *ServerInterfaceWrapper) GetInventoryDiff(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetInventoryDiffParams
+
+	// ------------- Required query parameter "from" -------------
+
+	if paramValue := c.Query("from"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument from is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "to" -------------
+
+	if paramValue := c.Query("to"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument to is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetInventoryDiff(c, params)
+}
+
+// ListInventorySnapshots operation middleware
+func (siw *ServerInterfaceWrapper) ListInventorySnapshots(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListInventorySnapshots(c)
+}
+
+// GetInventorySnapshot operation middleware
+func (siw *ServerInterfaceWrapper) GetInventorySnapshot(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int64
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetInventorySnapshot(c, id)
+}
+
+// ListCollectionRuns operation middleware
+func (siw *ServerInterfaceWrapper) ListCollectionRuns(c *gin.Context) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListCollectionRunsParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListCollectionRuns(c, params)
+}
+
+// DeleteCollectionSchedule operation middleware
+func (siw *ServerInterfaceWrapper) DeleteCollectionSchedule(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteCollectionSchedule(c)
+}
+
+// GetCollectionSchedule operation middleware
+func (siw *ServerInterfaceWrapper) GetCollectionSchedule(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetCollectionSchedule(c)
+}
+
+// SetCollectionSchedule operation middleware
+func (siw *ServerInterfaceWrapper) SetCollectionSchedule(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.SetCollectionSchedule(c)
+}
+
+// VerifyCollector operation middleware
+func (siw *ServerInterfaceWrapper) VerifyCollector(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.VerifyCollector(c)
+}
+
+// ListVCenters operation middleware
+func (siw *ServerInterfaceWrapper) ListVCenters(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListVCenters(c)
+}
+
+// CreateVCenter operation middleware
+func (siw *ServerInterfaceWrapper) CreateVCenter(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CreateVCenter(c)
+}
+
+// DeleteVCenter operation middleware
+func (siw *ServerInterfaceWrapper) DeleteVCenter(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteVCenter(c, name)
+}
+
+// GetVCenter operation middleware
+func (siw *ServerInterfaceWrapper) GetVCenter(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetVCenter(c, name)
+}
+
+// UpdateVCenter operation middleware
+func (siw *ServerInterfaceWrapper) UpdateVCenter(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.UpdateVCenter(c, name)
+}
+
+// StopVCenterCollection operation middleware
+func (siw *ServerInterfaceWrapper) StopVCenterCollection(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.StopVCenterCollection(c, name)
+}
+
+// CollectVCenter operation middleware
+func (siw *ServerInterfaceWrapper) CollectVCenter(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CollectVCenterParams
+
+	// ------------- Optional query parameter "full_resync" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "full_resync", c.Request.URL.Query(), &params.FullResync)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter full_resync: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.CollectVCenter(c, name, params)
+}
+
+// GetVCenterInventory operation middleware
+func (siw *ServerInterfaceWrapper) GetVCenterInventory(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetVCenterInventory(c, name)
+}
+
+// GetVCenterInventoryDiff operation middleware
+func (siw *ServerInterfaceWrapper) GetVCenterInventoryDiff(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetVCenterInventoryDiffParams
+
+	// ------------- Required query parameter "from" -------------
+
+	if paramValue := c.Query("from"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument from is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "from", c.Request.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter from: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "to" -------------
+
+	if paramValue := c.Query("to"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument to is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "to", c.Request.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter to: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetVCenterInventoryDiff(c, name, params)
+}
+
+// ListVCenterInventorySnapshots operation middleware
+func (siw *ServerInterfaceWrapper) ListVCenterInventorySnapshots(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListVCenterInventorySnapshots(c, name)
+}
+
+// GetVCenterInventorySnapshot operation middleware
+func (siw *ServerInterfaceWrapper) GetVCenterInventorySnapshot(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Path parameter "id" -------------
+	var id int64
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", c.Param("id"), &id, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter id: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetVCenterInventorySnapshot(c, name, id)
+}
+
+// ListVCenterCollectionRuns operation middleware
+func (siw *ServerInterfaceWrapper) ListVCenterCollectionRuns(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "name", c.Param("name"), &name, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter name: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListVCenterCollectionRunsParams
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", c.Request.URL.Query(), &params.Offset)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListVCenterCollectionRuns(c, name, params)
+}
+
 // GinServerOptions provides options for the Gin server.
 type GinServerOptions struct {
 	BaseURL      string
@@ -145,8 +795,32 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 
 	router.GET(options.BaseURL+"/agent", wrapper.GetAgentStatus)
 	router.POST(options.BaseURL+"/agent", wrapper.SetAgentMode)
+	router.GET(options.BaseURL+"/agent/consent", wrapper.GetAgentConsent)
+	router.PUT(options.BaseURL+"/agent/consent", wrapper.SetAgentConsent)
+	router.GET(options.BaseURL+"/agent/inventory/preview", wrapper.GetInventoryPreview)
+	router.GET(options.BaseURL+"/agent/outbox", wrapper.GetAgentOutbox)
 	router.DELETE(options.BaseURL+"/collector", wrapper.StopCollector)
 	router.GET(options.BaseURL+"/collector", wrapper.GetCollectorStatus)
 	router.POST(options.BaseURL+"/collector", wrapper.StartCollector)
 	router.GET(options.BaseURL+"/collector/inventory", wrapper.GetInventory)
+	router.GET(options.BaseURL+"/collector/inventory/diff", wrapper.GetInventoryDiff)
+	router.GET(options.BaseURL+"/collector/inventory/snapshots", wrapper.ListInventorySnapshots)
+	router.GET(options.BaseURL+"/collector/inventory/snapshots/:id", wrapper.GetInventorySnapshot)
+	router.GET(options.BaseURL+"/collector/runs", wrapper.ListCollectionRuns)
+	router.DELETE(options.BaseURL+"/collector/schedule", wrapper.DeleteCollectionSchedule)
+	router.GET(options.BaseURL+"/collector/schedule", wrapper.GetCollectionSchedule)
+	router.PUT(options.BaseURL+"/collector/schedule", wrapper.SetCollectionSchedule)
+	router.POST(options.BaseURL+"/collector/verify", wrapper.VerifyCollector)
+	router.GET(options.BaseURL+"/vcenters", wrapper.ListVCenters)
+	router.POST(options.BaseURL+"/vcenters", wrapper.CreateVCenter)
+	router.DELETE(options.BaseURL+"/vcenters/:name", wrapper.DeleteVCenter)
+	router.GET(options.BaseURL+"/vcenters/:name", wrapper.GetVCenter)
+	router.PUT(options.BaseURL+"/vcenters/:name", wrapper.UpdateVCenter)
+	router.DELETE(options.BaseURL+"/vcenters/:name/collect", wrapper.StopVCenterCollection)
+	router.POST(options.BaseURL+"/vcenters/:name/collect", wrapper.CollectVCenter)
+	router.GET(options.BaseURL+"/vcenters/:name/inventory", wrapper.GetVCenterInventory)
+	router.GET(options.BaseURL+"/vcenters/:name/inventory/diff", wrapper.GetVCenterInventoryDiff)
+	router.GET(options.BaseURL+"/vcenters/:name/inventory/snapshots", wrapper.ListVCenterInventorySnapshots)
+	router.GET(options.BaseURL+"/vcenters/:name/inventory/snapshots/:id", wrapper.GetVCenterInventorySnapshot)
+	router.GET(options.BaseURL+"/vcenters/:name/runs", wrapper.ListVCenterCollectionRuns)
 }
@@ -3,12 +3,31 @@
 // Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.3.0 DO NOT EDIT.
 package v1
 
+import (
+	"encoding/json"
+	"time"
+
+	externalRef0 "github.com/kubev2v/migration-planner/api/v1alpha1"
+	externalRef1 "github.com/kubev2v/migration-planner/api/v1alpha1/agent"
+)
+
 // Defines values for AgentModeRequestMode.
 const (
 	AgentModeRequestModeConnected    AgentModeRequestMode = "connected"
 	AgentModeRequestModeDisconnected AgentModeRequestMode = "disconnected"
 )
 
+// Defines values for AgentStatusCollectorStatus.
+const (
+	AgentStatusCollectorStatusCancelled  AgentStatusCollectorStatus = "cancelled"
+	AgentStatusCollectorStatusCollected  AgentStatusCollectorStatus = "collected"
+	AgentStatusCollectorStatusCollecting AgentStatusCollectorStatus = "collecting"
+	AgentStatusCollectorStatusConnected  AgentStatusCollectorStatus = "connected"
+	AgentStatusCollectorStatusConnecting AgentStatusCollectorStatus = "connecting"
+	AgentStatusCollectorStatusError      AgentStatusCollectorStatus = "error"
+	AgentStatusCollectorStatusReady      AgentStatusCollectorStatus = "ready"
+)
+
 // Defines values for AgentStatusConsoleConnection.
 const (
 	AgentStatusConsoleConnectionConnected    AgentStatusConsoleConnection = "connected"
@@ -23,8 +42,37 @@ const (
 	AgentStatusModeDisconnected AgentStatusMode = "disconnected"
 )
 
+// Defines values for AgentStatusStopReason.
+const (
+	AgentStatusStopReasonSourceGone   AgentStatusStopReason = "source_gone"
+	AgentStatusStopReasonUnauthorized AgentStatusStopReason = "unauthorized"
+)
+
+// Defines values for CollectionRunState.
+const (
+	CollectionRunStateCancelled CollectionRunState = "cancelled"
+	CollectionRunStateFailed    CollectionRunState = "failed"
+	CollectionRunStateRunning   CollectionRunState = "running"
+	CollectionRunStateSucceeded CollectionRunState = "succeeded"
+)
+
+// Defines values for CollectionRunTrigger.
+const (
+	CollectionRunTriggerManual    CollectionRunTrigger = "manual"
+	CollectionRunTriggerScheduled CollectionRunTrigger = "scheduled"
+)
+
+// Defines values for CollectorProgressPhase.
+const (
+	CollectorProgressPhaseBuildingInventory CollectorProgressPhase = "building_inventory"
+	CollectorProgressPhaseConnecting        CollectorProgressPhase = "connecting"
+	CollectorProgressPhasePersisting        CollectorProgressPhase = "persisting"
+	CollectorProgressPhaseSyncing           CollectorProgressPhase = "syncing"
+)
+
 // Defines values for CollectorStatusStatus.
 const (
+	CollectorStatusStatusCancelled  CollectorStatusStatus = "cancelled"
 	CollectorStatusStatusCollected  CollectorStatusStatus = "collected"
 	CollectorStatusStatusCollecting CollectorStatusStatus = "collecting"
 	CollectorStatusStatusConnected  CollectorStatusStatus = "connected"
@@ -33,6 +81,48 @@ const (
 	CollectorStatusStatusReady      CollectorStatusStatus = "ready"
 )
 
+// Defines values for ErrorCode.
+const (
+	ErrorCodeCertificateUntrusted   ErrorCode = "certificate_untrusted"
+	ErrorCodeCollectionInProgress   ErrorCode = "collection_in_progress"
+	ErrorCodeDnsFailure             ErrorCode = "dns_failure"
+	ErrorCodeInsufficientPrivileges ErrorCode = "insufficient_privileges"
+	ErrorCodeInternalError          ErrorCode = "internal_error"
+	ErrorCodeInvalidCredentials     ErrorCode = "invalid_credentials"
+	ErrorCodeInvalidRequest         ErrorCode = "invalid_request"
+	ErrorCodeNotVcenter             ErrorCode = "not_vcenter"
+	ErrorCodeTimeout                ErrorCode = "timeout"
+	ErrorCodeVcenterExists          ErrorCode = "vcenter_exists"
+	ErrorCodeVcenterUnreachable     ErrorCode = "vcenter_unreachable"
+)
+
+// Defines values for OutboxInventoryWithheldReason.
+const (
+	OutboxInventoryWithheldReasonNoConsent    OutboxInventoryWithheldReason = "no_consent"
+	OutboxInventoryWithheldReasonNotCollected OutboxInventoryWithheldReason = "not_collected"
+	OutboxInventoryWithheldReasonUnchanged    OutboxInventoryWithheldReason = "unchanged"
+)
+
+// Defines values for ScheduledRunStatus.
+const (
+	ScheduledRunStatusCancelled ScheduledRunStatus = "cancelled"
+	ScheduledRunStatusFailed    ScheduledRunStatus = "failed"
+	ScheduledRunStatusRunning   ScheduledRunStatus = "running"
+	ScheduledRunStatusSkipped   ScheduledRunStatus = "skipped"
+	ScheduledRunStatusSucceeded ScheduledRunStatus = "succeeded"
+)
+
+// Defines values for VCenterState.
+const (
+	VCenterStateCancelled  VCenterState = "cancelled"
+	VCenterStateCollected  VCenterState = "collected"
+	VCenterStateCollecting VCenterState = "collecting"
+	VCenterStateConnected  VCenterState = "connected"
+	VCenterStateConnecting VCenterState = "connecting"
+	VCenterStateError      VCenterState = "error"
+	VCenterStateReady      VCenterState = "ready"
+)
+
 // AgentModeRequest defines model for AgentModeRequest.
 type AgentModeRequest struct {
 	Mode AgentModeRequestMode `json:"mode"`
@@ -43,22 +133,176 @@ type AgentModeRequestMode string
 
 // AgentStatus defines model for AgentStatus.
 type AgentStatus struct {
+	// CollectorStatus Current collector status
+	CollectorStatus AgentStatusCollectorStatus `json:"collector_status"`
+
 	// ConsoleConnection Current console connection status
 	ConsoleConnection AgentStatusConsoleConnection `json:"console_connection"`
 
+	// Error Last error returned by console
+	Error *string `json:"error,omitempty"`
+
+	// Id Agent identifier
+	Id string `json:"id"`
+
+	// InventoryBackoff Backoff of the updates sent to console after consecutive failures
+	InventoryBackoff PushBackoff `json:"inventory_backoff"`
+
+	// LastInventoryUpdate Time of the last successful inventory update sent to console
+	LastInventoryUpdate *time.Time `json:"last_inventory_update,omitempty"`
+
+	// LastStatusUpdate Time of the last successful status update sent to console
+	LastStatusUpdate *time.Time `json:"last_status_update,omitempty"`
+
 	// Mode Target mode for the agent
 	Mode AgentStatusMode `json:"mode"`
+
+	// SourceId Source identifier
+	SourceId string `json:"source_id"`
+
+	// StatusBackoff Backoff of the updates sent to console after consecutive failures
+	StatusBackoff PushBackoff `json:"status_backoff"`
+
+	// StopReason Reason the agent stopped sending updates to console, until it is switched to connected mode again
+	StopReason *AgentStatusStopReason `json:"stop_reason,omitempty"`
+
+	// Version Agent version reported to console
+	Version *string `json:"version,omitempty"`
 }
 
+// AgentStatusCollectorStatus Current collector status
+type AgentStatusCollectorStatus string
+
 // AgentStatusConsoleConnection Current console connection status
 type AgentStatusConsoleConnection string
 
 // AgentStatusMode Target mode for the agent
 type AgentStatusMode string
 
+// AgentStatusStopReason Reason the agent stopped sending updates to console, until it is switched to connected mode again
+type AgentStatusStopReason string
+
+// Certificate Certificate presented by vCenter
+type Certificate struct {
+	Host   string `json:"host"`
+	Issuer string `json:"issuer"`
+
+	NotAfter time.Time `json:"not_after"`
+
+	// Sha256 SHA-256 fingerprint
+	Sha256  string `json:"sha256"`
+	Subject string `json:"subject"`
+
+	// Thumbprint SHA-1 fingerprint, to be sent back as thumbprint to trust the certificate
+	Thumbprint string `json:"thumbprint"`
+}
+
+// CertificateError Error response, with the certificate presented by vCenter when the code is certificate_untrusted
+type CertificateError struct {
+	// Certificate Certificate presented by vCenter
+	Certificate *Certificate `json:"certificate,omitempty"`
+
+	// Code Machine readable error code
+	Code  ErrorCode `json:"code"`
+	Error string    `json:"error"`
+}
+
+// CollectionRun defines model for CollectionRun.
+type CollectionRun struct {
+	// Datastores Number of datastores collected
+	Datastores int `json:"datastores"`
+
+	// EndedAt Unset while the collection is running
+	EndedAt *time.Time `json:"ended_at,omitempty"`
+	Error   *string    `json:"error,omitempty"`
+
+	// Hosts Number of hosts collected
+	Hosts int   `json:"hosts"`
+	Id    int64 `json:"id"`
+
+	// Incremental Whether the collection only applied the vCenter changes since the previous one. The first collection after an agent restart is a full one
+	Incremental bool `json:"incremental"`
+
+	// SnapshotId Id of the inventory snapshot saved by the collection
+	SnapshotId *int64             `json:"snapshot_id,omitempty"`
+	StartedAt  time.Time          `json:"started_at"`
+	State      CollectionRunState `json:"state"`
+
+	// Trigger What started the collection
+	Trigger    CollectionRunTrigger `json:"trigger"`
+	VcenterUrl string               `json:"vcenter_url"`
+
+	// Vms Number of VMs collected
+	Vms int `json:"vms"`
+}
+
+// CollectionRunState defines model for CollectionRun.State.
+type CollectionRunState string
+
+// CollectionRunTrigger What started the collection
+type CollectionRunTrigger string
+
+// CollectionRunList defines model for CollectionRunList.
+type CollectionRunList struct {
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+	Runs   []CollectionRun `json:"runs"`
+
+	// Total Total number of runs
+	Total int `json:"total"`
+}
+
+// CollectionSchedule defines model for CollectionSchedule.
+type CollectionSchedule struct {
+	// Cron Cron expression
+	Cron *string `json:"cron,omitempty"`
+
+	// Interval Interval between collections
+	Interval *string `json:"interval,omitempty"`
+
+	// LastRun Outcome of the last scheduled collection
+	LastRun   *ScheduledRun `json:"last_run,omitempty"`
+	NextRunAt time.Time     `json:"next_run_at"`
+}
+
+// CollectionScheduleRequest Exactly one of interval and cron must be set
+type CollectionScheduleRequest struct {
+	// Cron Standard 5 fields cron expression, e.g. "0 2 * * *"
+	Cron *string `json:"cron,omitempty"`
+
+	// Interval Interval between collections, e.g. 6h. Must be at least 1m
+	Interval *string `json:"interval,omitempty"`
+}
+
+// CollectorProgress Progress of the running collection, set while collecting
+type CollectorProgress struct {
+	// Datastores Number of datastores synced so far
+	Datastores     int   `json:"datastores"`
+	ElapsedSeconds int64 `json:"elapsed_seconds"`
+
+	// EstimatedCompletion Estimated completion based on the previous collection of the same vCenter
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"`
+
+	// Hosts Number of hosts synced so far
+	Hosts     int                    `json:"hosts"`
+	Phase     CollectorProgressPhase `json:"phase"`
+	StartedAt time.Time              `json:"started_at"`
+
+	// Vms Number of VMs synced so far
+	Vms int `json:"vms"`
+}
+
+// CollectorProgressPhase defines model for CollectorProgress.Phase.
+type CollectorProgressPhase string
+
 // CollectorStartRequest defines model for CollectorStartRequest.
 type CollectorStartRequest struct {
-	Password string `json:"password"`
+	// CaCert PEM encoded CA bundle used to verify the vCenter certificate. The system roots are used when not set
+	CaCert   *string `json:"ca_cert,omitempty"`
+	Password string  `json:"password"`
+
+	// Thumbprint SHA-1 or SHA-256 fingerprint of the vCenter certificate, trusted when it cannot be verified with the CAs
+	Thumbprint *string `json:"thumbprint,omitempty"`
 
 	// Url vCenter URL
 	Url      string `json:"url"`
@@ -71,15 +315,304 @@ type CollectorStatus struct {
 	Error *string `json:"error,omitempty"`
 
 	// HasCredentials Whether vCenter credentials are configured
-	HasCredentials bool                  `json:"hasCredentials"`
-	Status         CollectorStatusStatus `json:"status"`
+	HasCredentials bool `json:"hasCredentials"`
+
+	// Preflight Result of the vCenter privilege check run before a collection
+	Preflight *Preflight `json:"preflight,omitempty"`
+
+	// Progress Progress of the running collection, set while collecting
+	Progress *CollectorProgress    `json:"progress,omitempty"`
+	Status   CollectorStatusStatus `json:"status"`
 }
 
 // CollectorStatusStatus defines model for CollectorStatus.Status.
 type CollectorStatusStatus string
 
+// Consent defines model for Consent.
+type Consent struct {
+	// Granted Whether the inventory may be sent to console
+	Granted bool `json:"granted"`
+
+	// TermsVersion Version of the terms the consent was given for
+	TermsVersion *string `json:"terms_version,omitempty"`
+
+	// UpdatedAt Time the consent was granted or revoked, absent when it was never set
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// UpdatedBy What granted or revoked the consent
+	UpdatedBy *string `json:"updated_by,omitempty"`
+}
+
+// ConsentRequest defines model for ConsentRequest.
+type ConsentRequest struct {
+	Granted bool `json:"granted"`
+
+	// TermsVersion Version of the terms the consent is given for, required to grant the consent
+	TermsVersion *string `json:"terms_version,omitempty"`
+}
+
+// CountChange defines model for CountChange.
+type CountChange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// ErrorCode Machine readable error code
+type ErrorCode string
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	// Code Machine readable error code
+	Code  ErrorCode `json:"code"`
+	Error string    `json:"error"`
+}
+
+// FieldChange defines model for FieldChange.
+type FieldChange struct {
+	// Field One of name, cpu, memoryMB, disksGB, nics, powerState, host or datastores
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// InventoryCounts defines model for InventoryCounts.
+type InventoryCounts struct {
+	Clusters   CountChange `json:"clusters"`
+	Datastores CountChange `json:"datastores"`
+	Hosts      CountChange `json:"hosts"`
+	Vms        CountChange `json:"vms"`
+}
+
+// InventoryDiff defines model for InventoryDiff.
+type InventoryDiff struct {
+	AddedVms   []VmSummary     `json:"added_vms"`
+	ChangedVms []VmChange      `json:"changed_vms"`
+	Counts     InventoryCounts `json:"counts"`
+	From       int64           `json:"from"`
+	RemovedVms []VmSummary     `json:"removed_vms"`
+	To         int64           `json:"to"`
+
+	// VmsCompared False when a snapshot was taken without VM details, in which case only counts are compared
+	VmsCompared bool `json:"vms_compared"`
+}
+
+// InventorySnapshot defines model for InventorySnapshot.
+type InventorySnapshot struct {
+	// CompletedAt Time the collection completed
+	CompletedAt time.Time `json:"completed_at"`
+
+	// Hash SHA-256 of the inventory
+	Hash      string                  `json:"hash"`
+	Id        int64                   `json:"id"`
+	Inventory *externalRef0.Inventory `json:"inventory,omitempty"`
+
+	// StartedAt Time the collection started
+	StartedAt time.Time `json:"started_at"`
+
+	// VcenterUrl URL of the vCenter the inventory was collected from
+	VcenterUrl string `json:"vcenter_url"`
+}
+
+// InventorySnapshotList defines model for InventorySnapshotList.
+type InventorySnapshotList struct {
+	Snapshots []InventorySnapshot `json:"snapshots"`
+}
+
+// MissingPrivileges Required privileges not granted on an inventory object
+type MissingPrivileges struct {
+	// Entity Managed object id
+	Entity     string   `json:"entity"`
+	EntityName string   `json:"entity_name"`
+	EntityType string   `json:"entity_type"`
+	Privileges []string `json:"privileges"`
+}
+
+// Outbox defines model for Outbox.
+type Outbox struct {
+	// Inventory Body of the next inventory upload, absent when the inventory is withheld
+	Inventory json.RawMessage `json:"inventory,omitempty"`
+
+	// InventoryCompressed Whether the inventory body is sent compressed with gzip, set along with the inventory
+	InventoryCompressed *bool `json:"inventory_compressed,omitempty"`
+
+	// InventoryHash Hash of the inventory body, set along with the inventory
+	InventoryHash *string `json:"inventory_hash,omitempty"`
+
+	// InventoryUrl URL the inventory is sent to, set along with the inventory
+	InventoryUrl *string `json:"inventory_url,omitempty"`
+
+	// InventoryWithheldReason Reason the inventory is not sent next
+	InventoryWithheldReason *OutboxInventoryWithheldReason `json:"inventory_withheld_reason,omitempty"`
+
+	// LastUploadAt Time of the last inventory delivered to console
+	LastUploadAt *time.Time `json:"last_upload_at,omitempty"`
+
+	// LastUploadHash Hash of the last inventory delivered to console
+	LastUploadHash *string                        `json:"last_upload_hash,omitempty"`
+	Status         externalRef1.AgentStatusUpdate `json:"status"`
+
+	// StatusUrl URL the status is sent to
+	StatusUrl string `json:"status_url"`
+}
+
+// OutboxInventoryWithheldReason Reason the inventory is not sent next
+type OutboxInventoryWithheldReason string
+
+// Preflight Result of the vCenter privilege check run before a collection
+type Preflight struct {
+	CheckedAt         time.Time           `json:"checked_at"`
+	MissingPrivileges []MissingPrivileges `json:"missing_privileges"`
+
+	// Passed Whether the user has all the required privileges
+	Passed bool `json:"passed"`
+}
+
+// PushBackoff Backoff of the updates sent to console after consecutive failures
+type PushBackoff struct {
+	// BackoffSeconds Delay before the next attempt, zero when the last update succeeded
+	BackoffSeconds float64 `json:"backoff_seconds"`
+
+	// ConsecutiveFailures Number of consecutive failed updates, reset by the next successful update
+	ConsecutiveFailures int `json:"consecutive_failures"`
+
+	// NextAttemptAt Time of the next attempt while backing off
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// ScheduledRun Outcome of the last scheduled collection
+type ScheduledRun struct {
+	Error     *string            `json:"error,omitempty"`
+	StartedAt time.Time          `json:"started_at"`
+	Status    ScheduledRunStatus `json:"status"`
+}
+
+// ScheduledRunStatus defines model for ScheduledRun.Status.
+type ScheduledRunStatus string
+
+// VCenter defines model for VCenter.
+type VCenter struct {
+	// CollectedAt Completion time of the latest inventory snapshot
+	CollectedAt *time.Time `json:"collected_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Error Error message when state is error
+	Error *string `json:"error,omitempty"`
+	Name  string  `json:"name"`
+
+	// Preflight Result of the vCenter privilege check run before a collection
+	Preflight *Preflight `json:"preflight,omitempty"`
+
+	// Progress Progress of the running collection, set while collecting
+	Progress  *CollectorProgress `json:"progress,omitempty"`
+	State     VCenterState       `json:"state"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	Url       string             `json:"url"`
+	Username  string             `json:"username"`
+}
+
+// VCenterState defines model for VCenter.State.
+type VCenterState string
+
+// VCenterList defines model for VCenterList.
+type VCenterList struct {
+	Vcenters []VCenter `json:"vcenters"`
+}
+
+// VCenterRequest defines model for VCenterRequest.
+type VCenterRequest struct {
+	// CaCert PEM encoded CA bundle used to verify the vCenter certificate. The system roots are used when not set
+	CaCert *string `json:"ca_cert,omitempty"`
+
+	// Name Lowercase alphanumeric characters or '-', at most 63. Prefixes the cluster keys of the vCenter in the merged inventory
+	Name     string `json:"name"`
+	Password string `json:"password"`
+
+	// Thumbprint SHA-1 or SHA-256 fingerprint of the vCenter certificate, trusted when it cannot be verified with the CAs
+	Thumbprint *string `json:"thumbprint,omitempty"`
+
+	// Url vCenter URL
+	Url      string `json:"url"`
+	Username string `json:"username"`
+}
+
+// VmChange defines model for VmChange.
+type VmChange struct {
+	Changes []FieldChange `json:"changes"`
+	Id      string        `json:"id"`
+	Name    string        `json:"name"`
+}
+
+// VmSummary defines model for VmSummary.
+type VmSummary struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CollectVCenterParams defines parameters for CollectVCenter.
+type CollectVCenterParams struct {
+	// FullResync Collect the inventory from scratch instead of applying the vCenter changes since the previous collection
+	FullResync *bool `form:"full_resync,omitempty" json:"full_resync,omitempty"`
+}
+
+// GetInventoryDiffParams defines parameters for GetInventoryDiff.
+type GetInventoryDiffParams struct {
+	// From Id of the snapshot to compare from
+	From int64 `form:"from" json:"from"`
+
+	// To Id of the snapshot to compare to
+	To int64 `form:"to" json:"to"`
+}
+
+// GetVCenterInventoryDiffParams defines parameters for GetVCenterInventoryDiff.
+type GetVCenterInventoryDiffParams struct {
+	// From Id of the snapshot to compare from
+	From int64 `form:"from" json:"from"`
+
+	// To Id of the snapshot to compare to
+	To int64 `form:"to" json:"to"`
+}
+
+// ListCollectionRunsParams defines parameters for ListCollectionRuns.
+type ListCollectionRunsParams struct {
+	// Limit Maximum number of runs to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset Number of runs to skip
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// ListVCenterCollectionRunsParams defines parameters for ListVCenterCollectionRuns.
+type ListVCenterCollectionRunsParams struct {
+	// Limit Maximum number of runs to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset Number of runs to skip
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// StartCollectorParams defines parameters for StartCollector.
+type StartCollectorParams struct {
+	// FullResync Collect the inventory from scratch instead of applying the vCenter changes since the previous collection
+	FullResync *bool `form:"full_resync,omitempty" json:"full_resync,omitempty"`
+}
+
+// CreateVCenterJSONRequestBody defines body for CreateVCenter for application/json ContentType.
+type CreateVCenterJSONRequestBody = VCenterRequest
+
+// SetAgentConsentJSONRequestBody defines body for SetAgentConsent for application/json ContentType.
+type SetAgentConsentJSONRequestBody = ConsentRequest
+
 // SetAgentModeJSONRequestBody defines body for SetAgentMode for application/json ContentType.
 type SetAgentModeJSONRequestBody = AgentModeRequest
 
+// SetCollectionScheduleJSONRequestBody defines body for SetCollectionSchedule for application/json ContentType.
+type SetCollectionScheduleJSONRequestBody = CollectionScheduleRequest
+
 // StartCollectorJSONRequestBody defines body for StartCollector for application/json ContentType.
 type StartCollectorJSONRequestBody = CollectorStartRequest
+
+// UpdateVCenterJSONRequestBody defines body for UpdateVCenter for application/json ContentType.
+type UpdateVCenterJSONRequestBody = CollectorStartRequest
+
+// VerifyCollectorJSONRequestBody defines body for VerifyCollector for application/json ContentType.
+type VerifyCollectorJSONRequestBody = CollectorStartRequest
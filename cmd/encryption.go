@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+)
+
+const (
+	defaultKeyFile = "encryption.key"
+	saltFile       = "encryption.salt"
+)
+
+// loadKey returns the key used to encrypt the credentials.
+// The key is read from the key file or derived from the passphrase. When neither is set,
+// a key is generated and kept in the data folder, or in memory if there is no data folder.
+func loadKey(cfg config.Encryption, dataFolder string) (*encryption.Key, error) {
+	switch {
+	case cfg.KeyFile != "":
+		return encryption.LoadKeyFile(cfg.KeyFile)
+	case cfg.Passphrase != "":
+		salt, err := loadSalt(dataFolder)
+		if err != nil {
+			return nil, err
+		}
+		return encryption.DeriveKey(cfg.Passphrase, salt)
+	case dataFolder != "":
+		path := filepath.Join(dataFolder, defaultKeyFile)
+		zap.S().Warnw("encryption key not set, using key from data folder", "path", path)
+		return encryption.LoadOrCreateKeyFile(path)
+	default:
+		return encryption.GenerateKey()
+	}
+}
+
+func loadSalt(dataFolder string) ([]byte, error) {
+	if dataFolder == "" {
+		// in-memory database: the salt only needs to live as long as the process
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		return salt, nil
+	}
+	return encryption.LoadOrCreateSalt(filepath.Join(dataFolder, saltFile))
+}
+
+func validateEncryption(cfg config.Encryption) error {
+	if cfg.KeyFile != "" && cfg.Passphrase != "" {
+		return errors.New("encryption-key-file and encryption-passphrase are mutually exclusive")
+	}
+	return nil
+}
+
+func registerEncryptionFlags(flagSet *pflag.FlagSet, config *config.Configuration) {
+	flagSet.StringVar(&config.Encryption.KeyFile, "encryption-key-file", config.Encryption.KeyFile, "Path to the file holding the base64 encoded 32 bytes key used to encrypt credentials")
+	flagSet.StringVar(&config.Encryption.Passphrase, "encryption-passphrase", config.Encryption.Passphrase, "Passphrase from which the key used to encrypt credentials is derived")
+}
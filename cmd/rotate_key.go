@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+)
+
+func NewRotateKeyCommand(cfg *config.Configuration) *cobra.Command {
+	previous := config.Encryption{}
+
+	rotateCmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Re-encrypt stored credentials with a new key",
+		Long:  "Re-encrypt the credentials and the named vCenter passwords stored in the data folder with a new key. The agent must be stopped while the key is rotated.",
+		Example: `  # Rotate from the generated key to a key file
+  agent rotate-key --data-folder /var/lib/agent --previous-encryption-key-file /var/lib/agent/encryption.key --encryption-key-file /etc/agent/new.key
+
+  # Rotate from a passphrase to another
+  agent rotate-key --data-folder /var/lib/agent --previous-encryption-passphrase old --encryption-passphrase new`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.Agent.DataFolder == "" {
+				return errors.New("data-folder must be set")
+			}
+			if err := validateEncryption(cfg.Encryption); err != nil {
+				return err
+			}
+			if cfg.Encryption.KeyFile == "" && cfg.Encryption.Passphrase == "" {
+				return errors.New("encryption-key-file or encryption-passphrase must be set")
+			}
+			if previous.KeyFile != "" && previous.Passphrase != "" {
+				return errors.New("previous-encryption-key-file and previous-encryption-passphrase are mutually exclusive")
+			}
+
+			previousKey, err := loadKey(previous, cfg.Agent.DataFolder)
+			if err != nil {
+				return fmt.Errorf("failed to load previous encryption key: %w", err)
+			}
+			key, err := loadKey(cfg.Encryption, cfg.Agent.DataFolder)
+			if err != nil {
+				return fmt.Errorf("failed to load encryption key: %w", err)
+			}
+
+			db, err := store.NewDB(filepath.Join(cfg.Agent.DataFolder, "agent.duckdb"))
+			if err != nil {
+				return fmt.Errorf("failed to open database: %w", err)
+			}
+			s := store.NewStore(db, encryption.NewKeyring(key, previousKey))
+			defer s.Close()
+
+			if err := migrations.Run(cmd.Context(), db); err != nil {
+				return fmt.Errorf("failed to run migrations: %w", err)
+			}
+
+			credentials, vcenters, err := s.ReEncrypt(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if !credentials && vcenters == 0 {
+				zap.S().Info("credentials already use the new key or are not stored, nothing to rotate")
+				return nil
+			}
+
+			zap.S().Infow("credentials re-encrypted", "key_id", key.ID, "credentials", credentials, "vcenters", vcenters)
+			return nil
+		},
+	}
+
+	nfs := cobrautil.NewNamedFlagSets(rotateCmd)
+
+	agentFlagSet := nfs.FlagSet(color.New(color.FgBlue, color.Bold).Sprint("Agent"))
+	agentFlagSet.StringVar(&cfg.Agent.DataFolder, "data-folder", cfg.Agent.DataFolder, "Path to the persistent data folder")
+
+	encryptionFlagSet := nfs.FlagSet(color.New(color.FgBlue, color.Bold).Sprint("Encryption"))
+	registerEncryptionFlags(encryptionFlagSet, cfg)
+	encryptionFlagSet.StringVar(&previous.KeyFile, "previous-encryption-key-file", "", "Path to the key file the credentials are currently encrypted with")
+	encryptionFlagSet.StringVar(&previous.Passphrase, "previous-encryption-passphrase", "", "Passphrase the credentials are currently encrypted with")
+
+	nfs.AddFlagSets(rotateCmd)
+
+	return rotateCmd
+}
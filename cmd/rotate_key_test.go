@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+)
+
+var _ = Describe("rotate-key", func() {
+	var (
+		ctx        context.Context
+		dataFolder string
+		oldKeyFile string
+		newKeyFile string
+	)
+
+	// agentConfig returns the configuration of an agent using the key file.
+	agentConfig := func(keyFile string) *config.Configuration {
+		cfg := config.NewConfigurationWithOptionsAndDefaults()
+		cfg.Agent.DataFolder = dataFolder
+		cfg.Encryption.KeyFile = keyFile
+		return cfg
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dataFolder = GinkgoT().TempDir()
+		oldKeyFile = filepath.Join(dataFolder, "old.key")
+		newKeyFile = filepath.Join(dataFolder, "new.key")
+		for _, path := range []string{oldKeyFile, newKeyFile} {
+			_, err := encryption.LoadOrCreateKeyFile(path)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		s, err := openStore(ctx, agentConfig(oldKeyFile))
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = s.Close() }()
+
+		Expect(s.Credentials().Save(ctx, &models.Credentials{
+			URL:      "https://vcenter.example.com/sdk",
+			Username: "admin",
+			Password: "primary-secret",
+		})).To(Succeed())
+		Expect(s.VCenters().Create(ctx, &models.VCenter{
+			Name: "east",
+			Credentials: models.Credentials{
+				URL:      "https://east.example.com/sdk",
+				Username: "admin",
+				Password: "east-secret",
+			},
+			State: models.CollectorStateReady,
+		})).To(Succeed())
+	})
+
+	rotate := func() {
+		rotateCmd := NewRotateKeyCommand(agentConfig(""))
+		rotateCmd.SetArgs([]string{
+			"--data-folder", dataFolder,
+			"--previous-encryption-key-file", oldKeyFile,
+			"--encryption-key-file", newKeyFile,
+		})
+		Expect(rotateCmd.ExecuteContext(ctx)).To(Succeed())
+	}
+
+	It("should let the agent read the credentials and the vCenters with the new key", func() {
+		rotate()
+
+		s, err := openStore(ctx, agentConfig(newKeyFile))
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = s.Close() }()
+
+		creds, err := s.Credentials().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.Password).To(Equal("primary-secret"))
+
+		vcenter, err := s.VCenters().Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vcenter.Credentials.Password).To(Equal("east-secret"))
+	})
+
+	It("should not let the agent start with the previous key", func() {
+		rotate()
+
+		_, err := openStore(ctx, agentConfig(oldKeyFile))
+		Expect(err).To(MatchError(encryption.ErrUnknownKey))
+	})
+})
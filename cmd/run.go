@@ -8,7 +8,6 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -31,6 +30,7 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
 	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 )
 
@@ -55,6 +55,7 @@ func NewRunCommand(cfg *config.Configuration) *cobra.Command {
 				"agent", helpers.Flatten(cfg.Agent.DebugMap()),
 				"server", helpers.Flatten(cfg.Server.DebugMap()),
 				"console", helpers.Flatten(cfg.Console.DebugMap()),
+				"encryption", helpers.Flatten(cfg.Encryption.DebugMap()),
 			)
 
 			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGQUIT)
@@ -62,54 +63,67 @@ func NewRunCommand(cfg *config.Configuration) *cobra.Command {
 			wg.Add(1)
 
 			// init store
-			dbPath := filepath.Join(cfg.Agent.DataFolder, "agent.duckdb")
 			if cfg.Agent.DataFolder == "" {
-				dbPath = ":memory:"
 				zap.S().Warn("data-folder not set, using in-memory database (data will not persist)")
 			}
-			db, err := store.NewDB(dbPath)
+			s, err := openStore(ctx, cfg)
 			if err != nil {
-				zap.S().Errorw("failed to initialize database", "error", err)
 				return err
 			}
-			s := store.NewStore(db)
 			defer s.Close()
-
-			if err := migrations.Run(ctx, db); err != nil {
-				zap.S().Errorw("failed to run migrations", "error", err)
-				return err
-			}
 			zap.S().Info("database initialized successfully")
 
 			// init scheduler
 			sched := scheduler.NewScheduler(cfg.Agent.NumWorkers)
 			defer sched.Close()
 
-			// read jwt token for agent
-			jwt := ""
+			// read jwt token for agent, reloaded when the file changes
+			consoleOpts := []console.ClientOption{console.WithCompression(cfg.Console.CompressInventory)}
 			if cfg.Auth.Enabled {
-				data, err := os.ReadFile(cfg.Auth.JWTFilePath)
+				jwtFile, err := console.NewJWTFile(cfg.Auth.JWTFilePath)
 				if err != nil {
-					return fmt.Errorf("failed to read agent's jwt: %v", err)
+					return err
 				}
-				if len(data) == 0 {
-					return errors.New("failed to read agent's jwt. the JWT is empty")
-				}
-				jwt = strings.TrimSpace(string(data)) // we assume the jwt is valid at this point
+				go func() {
+					if err := jwtFile.Watch(ctx); err != nil {
+						zap.S().Warnw("agent's jwt will not be reloaded when the file changes", "error", err)
+					}
+				}()
+				consoleOpts = append(consoleOpts, console.WithJWTFile(jwtFile))
 			}
 
 			// init console client
-			consoleClient, err := console.NewConsoleClient(cfg.Console.URL, jwt)
+			consoleClient, err := console.NewConsoleClient(cfg.Console.URL, "", consoleOpts...)
 			if err != nil {
 				return fmt.Errorf("failed to create console client: %v", err)
 			}
 
 			// create services
-			collectorSrv := services.NewCollectorService(sched, s, cfg.Agent.DataFolder)
-			consoleSrv := services.NewConsoleService(cfg.Agent, sched, consoleClient, collectorSrv, s)
+			collectorSrv := services.NewCollectorService(sched, s, cfg.Agent)
+			vcenterSrv := services.NewVCenterService(sched, s, cfg.Agent)
+
+			// the console receives the inventories of the named vCenters merged with the collector one when enabled
+			var consoleCollector services.Collector = collectorSrv
+			if cfg.Agent.MergeVCenterInventories {
+				consoleCollector = services.NewMergedCollector(collectorSrv, vcenterSrv)
+			}
+			consoleSrv := services.NewConsoleService(cfg.Agent, sched, consoleClient, consoleCollector, s)
+			scheduleSrv := services.NewScheduleService(sched, collectorSrv, vcenterSrv, s)
+			defer scheduleSrv.Close()
+
+			if cfg.Agent.CollectionSchedule != "" {
+				schedule, err := services.ParseSchedule(cfg.Agent.CollectionSchedule)
+				if err != nil {
+					return err
+				}
+				if _, err := scheduleSrv.Set(ctx, schedule); err != nil {
+					zap.S().Errorw("failed to set collection schedule", "error", err)
+					return err
+				}
+			}
 
 			// init handlers
-			h := handlers.New(consoleSrv, collectorSrv)
+			h := handlers.New(consoleSrv, collectorSrv, scheduleSrv, vcenterSrv)
 
 			srv, err := server.NewServer(cfg, func(router *gin.RouterGroup) {
 				v1.RegisterHandlers(router, h)
@@ -169,6 +183,9 @@ func registerFlags(cmd *cobra.Command, config *config.Configuration) {
 	consoleFlagSet := nfs.FlagSet(color.New(color.FgBlue, color.Bold).Sprint("Console"))
 	registerConsoleFlags(consoleFlagSet, config)
 
+	encryptionFlagSet := nfs.FlagSet(color.New(color.FgBlue, color.Bold).Sprint("Encryption"))
+	registerEncryptionFlags(encryptionFlagSet, config)
+
 	nfs.AddFlagSets(cmd)
 }
 
@@ -204,10 +221,48 @@ func validateConfiguration(cfg *config.Configuration) error {
 		return fmt.Errorf("invalid num-workers %d: must be at least 1", cfg.Agent.NumWorkers)
 	}
 
+	if cfg.Agent.CollectorTimeout <= 0 {
+		return fmt.Errorf("invalid collector-timeout %s: must be positive", cfg.Agent.CollectorTimeout)
+	}
+
+	if cfg.Agent.CollectionSchedule != "" {
+		if _, err := services.ParseSchedule(cfg.Agent.CollectionSchedule); err != nil {
+			return fmt.Errorf("invalid collection-schedule %q: %w", cfg.Agent.CollectionSchedule, err)
+		}
+	}
+
+	if cfg.Agent.BackoffInitial < 0 || cfg.Agent.BackoffMax < cfg.Agent.BackoffInitial {
+		return fmt.Errorf("invalid console backoff %s-%s: the initial delay must not be negative nor greater than the max", cfg.Agent.BackoffInitial, cfg.Agent.BackoffMax)
+	}
+
+	if cfg.Agent.BackoffMultiplier < 1 {
+		return fmt.Errorf("invalid console-backoff-multiplier %g: must be at least 1", cfg.Agent.BackoffMultiplier)
+	}
+
+	if cfg.Agent.BackoffJitter < 0 || cfg.Agent.BackoffJitter > 1 {
+		return fmt.Errorf("invalid console-backoff-jitter %g: must be between 0 and 1", cfg.Agent.BackoffJitter)
+	}
+
+	if cfg.Agent.InventoryMaxRetries < 0 {
+		return fmt.Errorf("invalid console-inventory-max-retries %d: must not be negative", cfg.Agent.InventoryMaxRetries)
+	}
+
+	if _, err := services.ParseAnonymizedFields(cfg.Agent.AnonymizeFields); err != nil {
+		return fmt.Errorf("invalid console-anonymize-fields: %w", err)
+	}
+
+	if cfg.Agent.SnapshotRetention < 1 {
+		return fmt.Errorf("invalid inventory-snapshot-retention %d: must be at least 1", cfg.Agent.SnapshotRetention)
+	}
+
 	if cfg.Auth.Enabled && cfg.Auth.JWTFilePath == "" {
 		return errors.New("authentication-jwt-filepath must be set when authentication is enabled")
 	}
 
+	if err := validateEncryption(cfg.Encryption); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -240,9 +295,66 @@ func registerAgentFlags(flagSet *pflag.FlagSet, config *config.Configuration) {
 	flagSet.StringVar(&config.Agent.Version, "version", config.Agent.Version, "Agent version to report to console")
 	flagSet.IntVar(&config.Agent.NumWorkers, "num-workers", config.Agent.NumWorkers, "Number of scheduler workers")
 	flagSet.StringVar(&config.Agent.DataFolder, "data-folder", config.Agent.DataFolder, "Path to the persistent data folder")
+	flagSet.IntVar(&config.Agent.SnapshotRetention, "inventory-snapshot-retention", config.Agent.SnapshotRetention, "Number of inventory snapshots to keep")
+	flagSet.DurationVar(&config.Agent.CollectorTimeout, "collector-timeout", config.Agent.CollectorTimeout, "Maximum duration of an inventory collection")
+	flagSet.StringVar(&config.Agent.CollectionSchedule, "collection-schedule", config.Agent.CollectionSchedule, "Re-collect the inventory periodically: either an interval (e.g. 6h) or a cron expression (e.g. \"0 2 * * *\"). Replaces the schedule set with the API")
+	flagSet.BoolVar(&config.Agent.ResumeCollection, "resume-interrupted-collection", config.Agent.ResumeCollection, "Start again a collection interrupted by an agent restart instead of reporting it as failed")
+	flagSet.BoolVar(&config.Agent.IncrementalCollection, "incremental-collection", config.Agent.IncrementalCollection, "Keep the vCenter session of the last collection open, with its forklift database in the data folder, so the next collections only apply the changes. The database is deleted when the agent stops, so the first collection after a restart is a full one")
+	flagSet.BoolVar(&config.Agent.MergeVCenterInventories, "merge-vcenter-inventories", config.Agent.MergeVCenterInventories, "Send to console the inventory merged with the inventories of the vCenters added with the /vcenters API")
 }
 
 func registerConsoleFlags(flagSet *pflag.FlagSet, config *config.Configuration) {
 	flagSet.StringVar(&config.Console.URL, "console-url", config.Console.URL, "URL of console.redhat.com")
+	flagSet.BoolVar(&config.Console.CompressInventory, "console-compress-inventory", config.Console.CompressInventory, "Send the inventory to console compressed with gzip, uncompressed if console does not support it")
 	flagSet.DurationVar(&config.Agent.UpdateInterval, "console-update-interval", config.Agent.UpdateInterval, "Interval for console status updates")
+	flagSet.DurationVar(&config.Agent.BackoffInitial, "console-backoff-initial", config.Agent.BackoffInitial, "Delay before retrying a failed console update")
+	flagSet.DurationVar(&config.Agent.BackoffMax, "console-backoff-max", config.Agent.BackoffMax, "Maximum delay between retries of failed console updates")
+	flagSet.Float64Var(&config.Agent.BackoffMultiplier, "console-backoff-multiplier", config.Agent.BackoffMultiplier, "Factor applied to the retry delay after each consecutive failure of a console update")
+	flagSet.Float64Var(&config.Agent.BackoffJitter, "console-backoff-jitter", config.Agent.BackoffJitter, "Fraction of the retry delay randomly added or removed, between 0 and 1")
+	flagSet.IntVar(&config.Agent.InventoryMaxRetries, "console-inventory-max-retries", config.Agent.InventoryMaxRetries, "Number of retries of a failed inventory upload before waiting for the console to recover, 0 for no limit")
+	flagSet.BoolVar(&config.Agent.AnonymizeInventory, "console-anonymize-inventory", config.Agent.AnonymizeInventory, "Replace the identifying fields of the inventory sent to console with salted hashes")
+	flagSet.StringSliceVar(&config.Agent.AnonymizeFields, "console-anonymize-fields", config.Agent.AnonymizeFields, fmt.Sprintf("Fields anonymized when the inventory is anonymized, among %v. All of them when empty", services.AnonymizedFields))
+}
+
+// openStore opens the database of the data folder, in memory without data folder, and runs the migrations.
+// The credentials written in plaintext by previous versions are encrypted.
+func openStore(ctx context.Context, cfg *config.Configuration) (*store.Store, error) {
+	dbPath := filepath.Join(cfg.Agent.DataFolder, "agent.duckdb")
+	if cfg.Agent.DataFolder == "" {
+		dbPath = ":memory:"
+	}
+	db, err := store.NewDB(dbPath)
+	if err != nil {
+		zap.S().Errorw("failed to initialize database", "error", err)
+		return nil, err
+	}
+
+	key, err := loadKey(cfg.Encryption, cfg.Agent.DataFolder)
+	if err != nil {
+		_ = db.Close()
+		zap.S().Errorw("failed to load encryption key", "error", err)
+		return nil, err
+	}
+	s := store.NewStore(db, encryption.NewKeyring(key))
+
+	if err := migrations.Run(ctx, db); err != nil {
+		_ = s.Close()
+		zap.S().Errorw("failed to run migrations", "error", err)
+		return nil, err
+	}
+
+	credentials, vcenters, err := s.ReEncrypt(ctx)
+	if err != nil {
+		_ = s.Close()
+		zap.S().Errorw("failed to encrypt credentials", "error", err)
+		return nil, err
+	}
+	if credentials {
+		zap.S().Info("stored credentials encrypted")
+	}
+	if vcenters > 0 {
+		zap.S().Infow("stored vcenter credentials encrypted", "count", vcenters)
+	}
+
+	return s, nil
 }
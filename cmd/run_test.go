@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+)
+
+var _ = Describe("run", func() {
+	// run executes the run command with the flags of a valid disconnected agent followed by the given ones.
+	run := func(args ...string) error {
+		runCmd := NewRunCommand(config.NewConfigurationWithOptionsAndDefaults())
+		runCmd.SetArgs(append([]string{
+			"--agent-id", "550e8400-e29b-41d4-a716-446655440000",
+			"--source-id", "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+			"--mode", "disconnected",
+			"--server-mode", "dev",
+		}, args...))
+		runCmd.SilenceUsage = true
+		runCmd.SilenceErrors = true
+		return runCmd.Execute()
+	}
+
+	It("should reject an unknown anonymized field", func() {
+		err := run("--console-anonymize-inventory", "--console-anonymize-fields", "cluster,serial")
+		Expect(err).To(MatchError(ContainSubstring(`invalid console-anonymize-fields: unknown field "serial"`)))
+	})
+
+	It("should reject an unknown anonymized field even when the inventory is not anonymized", func() {
+		err := run("--console-anonymize-fields", "serial")
+		Expect(err).To(MatchError(ContainSubstring("invalid console-anonymize-fields")))
+	})
+})
@@ -9,12 +9,13 @@ const (
 	ServerModeDev  ServerModeType = "dev"
 )
 
-//go:generate go run github.com/ecordell/optgen -output zz_generated.configuration.go . Configuration Server Agent Console Authentication
+//go:generate go run github.com/ecordell/optgen -output zz_generated.configuration.go . Configuration Server Agent Console Authentication Encryption
 type Configuration struct {
-	Server  Server         `debugmap:"visible"`
-	Agent   Agent          `debugmap:"visible"`
-	Auth    Authentication `debugmap:"visible"`
-	Console Console        `debugmap:"visible"`
+	Server     Server         `debugmap:"visible"`
+	Agent      Agent          `debugmap:"visible"`
+	Auth       Authentication `debugmap:"visible"`
+	Console    Console        `debugmap:"visible"`
+	Encryption Encryption     `debugmap:"visible"`
 
 	// Log
 	LogFormat string `debugmap:"visible"`
@@ -28,21 +29,40 @@ type Server struct {
 }
 
 type Agent struct {
-	Mode              string        `debugmap:"visible" default:"disconnected"`
-	ID                string        `debugmap:"visible"`
-	SourceID          string        `debugmap:"visible"`
-	Version           string        `debugmap:"visible"`
-	NumWorkers        int           `debugmap:"visible" default:"3"`
-	DataFolder        string        `debugmap:"visible"`
-	OpaPoliciesFolder string        `debugmap:"visible"`
-	UpdateInterval    time.Duration `debugmap:"visible" default:"5s"`
+	Mode                    string        `debugmap:"visible" default:"disconnected"`
+	ID                      string        `debugmap:"visible"`
+	SourceID                string        `debugmap:"visible"`
+	Version                 string        `debugmap:"visible"`
+	NumWorkers              int           `debugmap:"visible" default:"3"`
+	DataFolder              string        `debugmap:"visible"`
+	OpaPoliciesFolder       string        `debugmap:"visible"`
+	UpdateInterval          time.Duration `debugmap:"visible" default:"5s"`
+	BackoffInitial          time.Duration `debugmap:"visible" default:"5s"`
+	BackoffMax              time.Duration `debugmap:"visible" default:"5m"`
+	BackoffMultiplier       float64       `debugmap:"visible" default:"2"`
+	BackoffJitter           float64       `debugmap:"visible" default:"0.2"`
+	InventoryMaxRetries     int           `debugmap:"visible" default:"10"`
+	SnapshotRetention       int           `debugmap:"visible" default:"10"`
+	CollectorTimeout        time.Duration `debugmap:"visible" default:"5m"`
+	CollectionSchedule      string        `debugmap:"visible"`
+	ResumeCollection        bool          `debugmap:"visible"`
+	IncrementalCollection   bool          `debugmap:"visible"`
+	MergeVCenterInventories bool          `debugmap:"visible"`
+	AnonymizeInventory      bool          `debugmap:"visible"`
+	AnonymizeFields         []string      `debugmap:"visible"`
 }
 
 type Console struct {
-	URL string `debugmap:"visible" default:"http://localhost:7443"`
+	URL               string `debugmap:"visible" default:"http://localhost:7443"`
+	CompressInventory bool   `debugmap:"visible"`
 }
 
 type Authentication struct {
 	Enabled     bool   `debugmap:"visible" default:"true"`
 	JWTFilePath string `debugmap:"visible"`
 }
+
+type Encryption struct {
+	KeyFile    string `debugmap:"visible"`
+	Passphrase string `debugmap:"sensitive"`
+}
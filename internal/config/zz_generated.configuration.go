@@ -36,6 +36,7 @@ func (c *Configuration) ToOption() ConfigurationOption {
 		to.Agent = c.Agent
 		to.Auth = c.Auth
 		to.Console = c.Console
+		to.Encryption = c.Encryption
 		to.LogFormat = c.LogFormat
 		to.LogLevel = c.LogLevel
 	}
@@ -48,6 +49,7 @@ func (c *Configuration) DebugMap() map[string]any {
 	debugMap["Agent"] = helpers.DebugValue(c.Agent, false)
 	debugMap["Auth"] = helpers.DebugValue(c.Auth, false)
 	debugMap["Console"] = helpers.DebugValue(c.Console, false)
+	debugMap["Encryption"] = helpers.DebugValue(c.Encryption, false)
 	debugMap["LogFormat"] = helpers.DebugValue(c.LogFormat, false)
 	debugMap["LogLevel"] = helpers.DebugValue(c.LogLevel, false)
 	return debugMap
@@ -97,6 +99,13 @@ func WithConsole(console Console) ConfigurationOption {
 	}
 }
 
+// WithEncryption returns an option that can set Encryption on a Configuration
+func WithEncryption(encryption Encryption) ConfigurationOption {
+	return func(c *Configuration) {
+		c.Encryption = encryption
+	}
+}
+
 // WithLogFormat returns an option that can set LogFormat on a Configuration
 func WithLogFormat(logFormat string) ConfigurationOption {
 	return func(c *Configuration) {
@@ -219,6 +228,19 @@ func (a *Agent) ToOption() AgentOption {
 		to.DataFolder = a.DataFolder
 		to.OpaPoliciesFolder = a.OpaPoliciesFolder
 		to.UpdateInterval = a.UpdateInterval
+		to.BackoffInitial = a.BackoffInitial
+		to.BackoffMax = a.BackoffMax
+		to.BackoffMultiplier = a.BackoffMultiplier
+		to.BackoffJitter = a.BackoffJitter
+		to.InventoryMaxRetries = a.InventoryMaxRetries
+		to.SnapshotRetention = a.SnapshotRetention
+		to.CollectorTimeout = a.CollectorTimeout
+		to.CollectionSchedule = a.CollectionSchedule
+		to.ResumeCollection = a.ResumeCollection
+		to.IncrementalCollection = a.IncrementalCollection
+		to.MergeVCenterInventories = a.MergeVCenterInventories
+		to.AnonymizeInventory = a.AnonymizeInventory
+		to.AnonymizeFields = a.AnonymizeFields
 	}
 }
 
@@ -233,6 +255,19 @@ func (a *Agent) DebugMap() map[string]any {
 	debugMap["DataFolder"] = helpers.DebugValue(a.DataFolder, false)
 	debugMap["OpaPoliciesFolder"] = helpers.DebugValue(a.OpaPoliciesFolder, false)
 	debugMap["UpdateInterval"] = helpers.DebugValue(a.UpdateInterval, false)
+	debugMap["BackoffInitial"] = helpers.DebugValue(a.BackoffInitial, false)
+	debugMap["BackoffMax"] = helpers.DebugValue(a.BackoffMax, false)
+	debugMap["BackoffMultiplier"] = helpers.DebugValue(a.BackoffMultiplier, false)
+	debugMap["BackoffJitter"] = helpers.DebugValue(a.BackoffJitter, false)
+	debugMap["InventoryMaxRetries"] = helpers.DebugValue(a.InventoryMaxRetries, false)
+	debugMap["SnapshotRetention"] = helpers.DebugValue(a.SnapshotRetention, false)
+	debugMap["CollectorTimeout"] = helpers.DebugValue(a.CollectorTimeout, false)
+	debugMap["CollectionSchedule"] = helpers.DebugValue(a.CollectionSchedule, false)
+	debugMap["ResumeCollection"] = helpers.DebugValue(a.ResumeCollection, false)
+	debugMap["IncrementalCollection"] = helpers.DebugValue(a.IncrementalCollection, false)
+	debugMap["MergeVCenterInventories"] = helpers.DebugValue(a.MergeVCenterInventories, false)
+	debugMap["AnonymizeInventory"] = helpers.DebugValue(a.AnonymizeInventory, false)
+	debugMap["AnonymizeFields"] = helpers.DebugValue(a.AnonymizeFields, false)
 	return debugMap
 }
 
@@ -308,6 +343,104 @@ func WithUpdateInterval(updateInterval time.Duration) AgentOption {
 	}
 }
 
+// WithBackoffInitial returns an option that can set BackoffInitial on a Agent
+func WithBackoffInitial(backoffInitial time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.BackoffInitial = backoffInitial
+	}
+}
+
+// WithBackoffMax returns an option that can set BackoffMax on a Agent
+func WithBackoffMax(backoffMax time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.BackoffMax = backoffMax
+	}
+}
+
+// WithBackoffMultiplier returns an option that can set BackoffMultiplier on a Agent
+func WithBackoffMultiplier(backoffMultiplier float64) AgentOption {
+	return func(a *Agent) {
+		a.BackoffMultiplier = backoffMultiplier
+	}
+}
+
+// WithBackoffJitter returns an option that can set BackoffJitter on a Agent
+func WithBackoffJitter(backoffJitter float64) AgentOption {
+	return func(a *Agent) {
+		a.BackoffJitter = backoffJitter
+	}
+}
+
+// WithInventoryMaxRetries returns an option that can set InventoryMaxRetries on a Agent
+func WithInventoryMaxRetries(inventoryMaxRetries int) AgentOption {
+	return func(a *Agent) {
+		a.InventoryMaxRetries = inventoryMaxRetries
+	}
+}
+
+// WithSnapshotRetention returns an option that can set SnapshotRetention on a Agent
+func WithSnapshotRetention(snapshotRetention int) AgentOption {
+	return func(a *Agent) {
+		a.SnapshotRetention = snapshotRetention
+	}
+}
+
+// WithCollectorTimeout returns an option that can set CollectorTimeout on a Agent
+func WithCollectorTimeout(collectorTimeout time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.CollectorTimeout = collectorTimeout
+	}
+}
+
+// WithCollectionSchedule returns an option that can set CollectionSchedule on a Agent
+func WithCollectionSchedule(collectionSchedule string) AgentOption {
+	return func(a *Agent) {
+		a.CollectionSchedule = collectionSchedule
+	}
+}
+
+// WithResumeCollection returns an option that can set ResumeCollection on a Agent
+func WithResumeCollection(resumeCollection bool) AgentOption {
+	return func(a *Agent) {
+		a.ResumeCollection = resumeCollection
+	}
+}
+
+// WithIncrementalCollection returns an option that can set IncrementalCollection on a Agent
+func WithIncrementalCollection(incrementalCollection bool) AgentOption {
+	return func(a *Agent) {
+		a.IncrementalCollection = incrementalCollection
+	}
+}
+
+// WithMergeVCenterInventories returns an option that can set MergeVCenterInventories on a Agent
+func WithMergeVCenterInventories(mergeVCenterInventories bool) AgentOption {
+	return func(a *Agent) {
+		a.MergeVCenterInventories = mergeVCenterInventories
+	}
+}
+
+// WithAnonymizeInventory returns an option that can set AnonymizeInventory on a Agent
+func WithAnonymizeInventory(anonymizeInventory bool) AgentOption {
+	return func(a *Agent) {
+		a.AnonymizeInventory = anonymizeInventory
+	}
+}
+
+// WithAnonymizeFields returns an option that can append AnonymizeFieldss to Agent.AnonymizeFields
+func WithAnonymizeFields(anonymizeFields string) AgentOption {
+	return func(a *Agent) {
+		a.AnonymizeFields = append(a.AnonymizeFields, anonymizeFields)
+	}
+}
+
+// SetAnonymizeFields returns an option that can set AnonymizeFields on a Agent
+func SetAnonymizeFields(anonymizeFields []string) AgentOption {
+	return func(a *Agent) {
+		a.AnonymizeFields = anonymizeFields
+	}
+}
+
 type ConsoleOption func(c *Console)
 
 // NewConsoleWithOptions creates a new Console with the passed in options set
@@ -333,6 +466,7 @@ func NewConsoleWithOptionsAndDefaults(opts ...ConsoleOption) *Console {
 func (c *Console) ToOption() ConsoleOption {
 	return func(to *Console) {
 		to.URL = c.URL
+		to.CompressInventory = c.CompressInventory
 	}
 }
 
@@ -340,6 +474,7 @@ func (c *Console) ToOption() ConsoleOption {
 func (c *Console) DebugMap() map[string]any {
 	debugMap := map[string]any{}
 	debugMap["URL"] = helpers.DebugValue(c.URL, false)
+	debugMap["CompressInventory"] = helpers.DebugValue(c.CompressInventory, false)
 	return debugMap
 }
 
@@ -366,6 +501,13 @@ func WithURL(uRL string) ConsoleOption {
 	}
 }
 
+// WithCompressInventory returns an option that can set CompressInventory on a Console
+func WithCompressInventory(compressInventory bool) ConsoleOption {
+	return func(c *Console) {
+		c.CompressInventory = compressInventory
+	}
+}
+
 type AuthenticationOption func(a *Authentication)
 
 // NewAuthenticationWithOptions creates a new Authentication with the passed in options set
@@ -432,3 +574,70 @@ func WithJWTFilePath(jWTFilePath string) AuthenticationOption {
 		a.JWTFilePath = jWTFilePath
 	}
 }
+
+type EncryptionOption func(e *Encryption)
+
+// NewEncryptionWithOptions creates a new Encryption with the passed in options set
+func NewEncryptionWithOptions(opts ...EncryptionOption) *Encryption {
+	e := &Encryption{}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// NewEncryptionWithOptionsAndDefaults creates a new Encryption with the passed in options set starting from the defaults
+func NewEncryptionWithOptionsAndDefaults(opts ...EncryptionOption) *Encryption {
+	e := &Encryption{}
+	defaults.MustSet(e)
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// ToOption returns a new EncryptionOption that sets the values from the passed in Encryption
+func (e *Encryption) ToOption() EncryptionOption {
+	return func(to *Encryption) {
+		to.KeyFile = e.KeyFile
+		to.Passphrase = e.Passphrase
+	}
+}
+
+// DebugMap returns a map form of Encryption for debugging
+func (e *Encryption) DebugMap() map[string]any {
+	debugMap := map[string]any{}
+	debugMap["KeyFile"] = helpers.DebugValue(e.KeyFile, false)
+	debugMap["Passphrase"] = helpers.SensitiveDebugValue(e.Passphrase)
+	return debugMap
+}
+
+// EncryptionWithOptions configures an existing Encryption with the passed in options set
+func EncryptionWithOptions(e *Encryption, opts ...EncryptionOption) *Encryption {
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// WithOptions configures the receiver Encryption with the passed in options set
+func (e *Encryption) WithOptions(opts ...EncryptionOption) *Encryption {
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// WithKeyFile returns an option that can set KeyFile on a Encryption
+func WithKeyFile(keyFile string) EncryptionOption {
+	return func(e *Encryption) {
+		e.KeyFile = keyFile
+	}
+}
+
+// WithPassphrase returns an option that can set Passphrase on a Encryption
+func WithPassphrase(passphrase string) EncryptionOption {
+	return func(e *Encryption) {
+		e.Passphrase = passphrase
+	}
+}
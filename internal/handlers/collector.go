@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 
@@ -12,6 +13,12 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/services"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	agentErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+const (
+	defaultRunsLimit = 20
+	maxRunsLimit     = 100
 )
 
 // GetCollectorStatus returns the collector status
@@ -26,52 +33,38 @@ func (h *Handler) GetCollectorStatus(c *gin.Context) {
 	if status.Error != "" {
 		resp.Error = &status.Error
 	}
+	if status.Progress != nil {
+		resp.Progress = &v1.CollectorProgress{}
+		resp.Progress.FromModel(*status.Progress)
+	}
+	if status.Preflight != nil {
+		resp.Preflight = &v1.Preflight{}
+		resp.Preflight.FromModel(*status.Preflight)
+	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
 // StartCollector starts inventory collection
 // (POST /collector)
-func (h *Handler) StartCollector(c *gin.Context) {
+func (h *Handler) StartCollector(c *gin.Context, params v1.StartCollectorParams) {
 	var req v1.CollectorStartRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
-		return
-	}
-
-	// Validate required fields
-	if req.Url == "" || req.Username == "" || req.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "url, username, and password are required"})
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, "invalid request body"))
 		return
 	}
 
-	// Validate URL format
-	parsedURL, err := url.Parse(req.Url)
-	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid url format"})
+	creds, err := credentialsFromRequest(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, err.Error()))
 		return
 	}
 
-	creds := &models.Credentials{
-		URL:      req.Url,
-		Username: req.Username,
-		Password: req.Password,
-	}
-
 	// Start collection (saves creds, verifies, starts async job)
-	if err := h.collector.Start(c.Request.Context(), creds); err != nil {
+	fullResync := params.FullResync != nil && *params.FullResync
+	if err := h.collector.Start(c.Request.Context(), creds, fullResync); err != nil {
 		zap.S().Errorw("failed to start collector", "error", err)
-
-		if errors.Is(err, services.ErrCollectionInProgress) {
-			c.JSON(http.StatusConflict, gin.H{"error": "collection already in progress"})
-			return
-		}
-		if errors.Is(err, services.ErrInvalidCredentials) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid vCenter credentials"})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start collector"})
+		c.JSON(collectorError(err))
 		return
 	}
 
@@ -83,10 +76,43 @@ func (h *Handler) StartCollector(c *gin.Context) {
 	})
 }
 
+// VerifyCollector verifies the vCenter credentials and the privileges of the user without starting a collection
+// (POST /collector/verify)
+func (h *Handler) VerifyCollector(c *gin.Context) {
+	var req v1.CollectorStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, "invalid request body"))
+		return
+	}
+
+	creds, err := credentialsFromRequest(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	preflight, err := h.collector.Verify(c.Request.Context(), creds)
+	if err != nil {
+		zap.S().Errorw("failed to verify vCenter credentials", "error", err)
+		c.JSON(collectorError(err))
+		return
+	}
+
+	var resp v1.Preflight
+	resp.FromModel(*preflight)
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // GetInventory returns the collected inventory
 // (GET /collector/inventory)
 func (h *Handler) GetInventory(c *gin.Context) {
-	inv, err := h.collector.GetInventory(c.Request.Context())
+	getInventory(c, h.collector)
+}
+
+// getInventory returns the latest inventory collected by the collector.
+func getInventory(c *gin.Context, collector *services.CollectorService) {
+	inv, err := collector.GetInventory(c.Request.Context())
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "inventory not found"})
@@ -101,6 +127,131 @@ func (h *Handler) GetInventory(c *gin.Context) {
 	c.Data(http.StatusOK, "application/json", inv.Data)
 }
 
+// GetInventoryDiff returns the changes between two inventory snapshots
+// (GET /collector/inventory/diff)
+func (h *Handler) GetInventoryDiff(c *gin.Context, params v1.GetInventoryDiffParams) {
+	getInventoryDiff(c, h.collector, params.From, params.To)
+}
+
+// getInventoryDiff returns the changes between two inventory snapshots of the collector.
+func getInventoryDiff(c *gin.Context, collector *services.CollectorService, from, to int64) {
+	diff, err := collector.DiffInventory(c.Request.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "inventory snapshot not found"})
+			return
+		}
+		zap.S().Errorw("failed to diff inventory snapshots", "error", err, "from", from, "to", to)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to diff inventory snapshots"})
+		return
+	}
+
+	var resp v1.InventoryDiff
+	resp.FromModel(*diff)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListInventorySnapshots returns the inventory snapshots metadata
+// (GET /collector/inventory/snapshots)
+func (h *Handler) ListInventorySnapshots(c *gin.Context) {
+	listInventorySnapshots(c, h.collector)
+}
+
+// listInventorySnapshots returns the inventory snapshots metadata of the collector.
+func listInventorySnapshots(c *gin.Context, collector *services.CollectorService) {
+	snapshots, err := collector.ListInventorySnapshots(c.Request.Context())
+	if err != nil {
+		zap.S().Errorw("failed to list inventory snapshots", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list inventory snapshots"})
+		return
+	}
+
+	resp := v1.InventorySnapshotList{Snapshots: make([]v1.InventorySnapshot, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		var s v1.InventorySnapshot
+		_ = s.FromModel(snapshot) // data is not loaded when listing
+		resp.Snapshots = append(resp.Snapshots, s)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetInventorySnapshot returns an inventory snapshot with its inventory
+// (GET /collector/inventory/snapshots/{id})
+func (h *Handler) GetInventorySnapshot(c *gin.Context, id int64) {
+	getInventorySnapshot(c, h.collector, id)
+}
+
+// getInventorySnapshot returns an inventory snapshot of the collector with its inventory.
+func getInventorySnapshot(c *gin.Context, collector *services.CollectorService, id int64) {
+	snapshot, err := collector.GetInventorySnapshot(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "inventory snapshot not found"})
+			return
+		}
+		zap.S().Errorw("failed to get inventory snapshot", "error", err, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get inventory snapshot"})
+		return
+	}
+
+	var resp v1.InventorySnapshot
+	if err := resp.FromModel(*snapshot); err != nil {
+		zap.S().Errorw("failed to decode inventory snapshot", "error", err, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get inventory snapshot"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListCollectionRuns returns a page of the collection history
+// (GET /collector/runs)
+func (h *Handler) ListCollectionRuns(c *gin.Context, params v1.ListCollectionRunsParams) {
+	listCollectionRuns(c, h.collector, params.Limit, params.Offset)
+}
+
+// listCollectionRuns returns a page of the collection history of the collector.
+func listCollectionRuns(c *gin.Context, collector *services.CollectorService, limitParam, offsetParam *int) {
+	limit, offset := defaultRunsLimit, 0
+	if limitParam != nil {
+		limit = *limitParam
+	}
+	if offsetParam != nil {
+		offset = *offsetParam
+	}
+	if limit < 1 || limit > maxRunsLimit {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, fmt.Sprintf("limit must be between 1 and %d", maxRunsLimit)))
+		return
+	}
+	if offset < 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, "offset must not be negative"))
+		return
+	}
+
+	runs, total, err := collector.ListRuns(c.Request.Context(), limit, offset)
+	if err != nil {
+		zap.S().Errorw("failed to list collection runs", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list collection runs"})
+		return
+	}
+
+	resp := v1.CollectionRunList{
+		Runs:   make([]v1.CollectionRun, 0, len(runs)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for _, run := range runs {
+		var r v1.CollectionRun
+		r.FromModel(run)
+		resp.Runs = append(resp.Runs, r)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // StopCollector stops the collection but keeps credentials for retry
 // (DELETE /collector)
 func (h *Handler) StopCollector(c *gin.Context) {
@@ -117,6 +268,83 @@ func (h *Handler) StopCollector(c *gin.Context) {
 	})
 }
 
+// credentialsFromRequest validates the request and returns the credentials it holds.
+func credentialsFromRequest(req v1.CollectorStartRequest) (*models.Credentials, error) {
+	if req.Url == "" || req.Username == "" || req.Password == "" {
+		return nil, errors.New("url, username, and password are required")
+	}
+
+	parsedURL, err := url.Parse(req.Url)
+	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return nil, errors.New("invalid url format")
+	}
+
+	creds := &models.Credentials{
+		URL:      req.Url,
+		Username: req.Username,
+		Password: req.Password,
+	}
+	if req.CaCert != nil && *req.CaCert != "" {
+		if err := services.ValidateCACert(*req.CaCert); err != nil {
+			return nil, err
+		}
+		creds.CACert = *req.CaCert
+	}
+	if req.Thumbprint != nil && *req.Thumbprint != "" {
+		thumbprint, err := services.NormalizeThumbprint(*req.Thumbprint)
+		if err != nil {
+			return nil, err
+		}
+		creds.Thumbprint = thumbprint
+	}
+
+	return creds, nil
+}
+
+// collectorError maps an error verifying the credentials or starting the collection to the HTTP status and response body.
+func collectorError(err error) (int, any) {
+	var (
+		certErr       *agentErrors.CertificateError
+		notVCenterErr *agentErrors.NotVCenterError
+	)
+
+	switch {
+	case errors.Is(err, services.ErrCollectionInProgress):
+		return http.StatusConflict, errorResponse(v1.ErrorCodeCollectionInProgress, "collection already in progress")
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return http.StatusUnauthorized, errorResponse(v1.ErrorCodeInvalidCredentials, "invalid vCenter credentials")
+	case agentErrors.IsInsufficientPrivilegesError(err):
+		return http.StatusForbidden, errorResponse(v1.ErrorCodeInsufficientPrivileges, err.Error())
+	case errors.As(err, &certErr):
+		return http.StatusUnprocessableEntity, v1.CertificateError{
+			Code:  v1.ErrorCodeCertificateUntrusted,
+			Error: "vCenter certificate is not trusted",
+			Certificate: &v1.Certificate{
+				Host:       certErr.Host,
+				Thumbprint: certErr.Thumbprint,
+				Sha256:     certErr.SHA256,
+				Subject:    certErr.Subject,
+				Issuer:     certErr.Issuer,
+				NotAfter:   certErr.NotAfter,
+			},
+		}
+	case errors.As(err, &notVCenterErr):
+		return http.StatusUnprocessableEntity, errorResponse(v1.ErrorCodeNotVcenter, notVCenterErr.Error())
+	case agentErrors.IsVCenterDNSError(err):
+		return http.StatusBadGateway, errorResponse(v1.ErrorCodeDnsFailure, err.Error())
+	case agentErrors.IsVCenterUnreachableError(err):
+		return http.StatusBadGateway, errorResponse(v1.ErrorCodeVcenterUnreachable, err.Error())
+	case agentErrors.IsVCenterTimeoutError(err):
+		return http.StatusGatewayTimeout, errorResponse(v1.ErrorCodeTimeout, err.Error())
+	default:
+		return http.StatusInternalServerError, errorResponse(v1.ErrorCodeInternalError, "failed to start collector")
+	}
+}
+
+func errorResponse(code v1.ErrorCode, msg string) v1.ErrorResponse {
+	return v1.ErrorResponse{Code: code, Error: msg}
+}
+
 // mapStateToAPIStatus converts internal state to API status.
 func mapStateToAPIStatus(state models.CollectorState) v1.CollectorStatusStatus {
 	switch state {
@@ -132,6 +360,8 @@ func mapStateToAPIStatus(state models.CollectorState) v1.CollectorStatusStatus {
 		return v1.CollectorStatusStatusCollected
 	case models.CollectorStateError:
 		return v1.CollectorStatusStatusError
+	case models.CollectorStateCancelled:
+		return v1.CollectorStatusStatusCancelled
 	default:
 		return v1.CollectorStatusStatusReady
 	}
@@ -1,21 +1,29 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
 )
 
 // GetAgentStatus returns the current agent status
 // (GET /agent)
 func (h *Handler) GetAgentStatus(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"msg": "not implemented"})
+	var resp v1.AgentStatus
+	resp.FromModel(h.consoleSrv.AgentStatus())
+
+	c.JSON(http.StatusOK, resp)
 }
 
-// SetAgentMode changes the agent mode
+// SetAgentMode changes the agent mode, restored when the agent restarts
 // (POST /agent)
 func (h *Handler) SetAgentMode(c *gin.Context) {
 	var req v1.AgentModeRequest
@@ -35,11 +43,98 @@ func (h *Handler) SetAgentMode(c *gin.Context) {
 		return
 	}
 
-	h.consoleSrv.SetMode(mode)
+	if err := h.consoleSrv.SaveMode(c.Request.Context(), mode, fmt.Sprintf("api from %s", c.ClientIP())); err != nil {
+		zap.S().Errorw("failed to save agent mode", "error", err, "mode", mode)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save agent mode"})
+		return
+	}
 
-	status := h.consoleSrv.Status()
 	var resp v1.AgentStatus
-	resp.FromModel(models.AgentStatus{Console: status})
+	resp.FromModel(h.consoleSrv.AgentStatus())
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetAgentConsent returns the data-sharing consent
+// (GET /agent/consent)
+func (h *Handler) GetAgentConsent(c *gin.Context) {
+	settings, err := h.consoleSrv.Consent(c.Request.Context())
+	if err != nil {
+		zap.S().Errorw("failed to get consent", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get consent"})
+		return
+	}
+
+	var resp v1.Consent
+	resp.FromModel(*settings)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetAgentConsent grants or revokes the data-sharing consent
+// (PUT /agent/consent)
+func (h *Handler) SetAgentConsent(c *gin.Context) {
+	var req v1.ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	var termsVersion string
+	if req.TermsVersion != nil {
+		termsVersion = strings.TrimSpace(*req.TermsVersion)
+	}
+	if req.Granted && termsVersion == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "terms_version is required to grant the consent"})
+		return
+	}
+
+	settings, err := h.consoleSrv.SetConsent(c.Request.Context(), req.Granted, termsVersion, fmt.Sprintf("api from %s", c.ClientIP()))
+	if err != nil {
+		zap.S().Errorw("failed to save consent", "error", err, "granted", req.Granted)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save consent"})
+		return
+	}
+
+	var resp v1.Consent
+	resp.FromModel(*settings)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetInventoryPreview returns the inventory exactly as it is sent to console
+// (GET /agent/inventory/preview)
+func (h *Handler) GetInventoryPreview(c *gin.Context) {
+	data, err := h.consoleSrv.InventoryPreview(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "inventory not collected yet"})
+			return
+		}
+		zap.S().Errorw("failed to preview inventory", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to preview inventory"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", data)
+}
+
+// GetAgentOutbox returns the requests the agent sends next to console
+// (GET /agent/outbox)
+func (h *Handler) GetAgentOutbox(c *gin.Context) {
+	outbox, err := h.consoleSrv.Outbox(c.Request.Context())
+	if err != nil {
+		zap.S().Errorw("failed to build outbox", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build outbox"})
+		return
+	}
+
+	var resp v1.Outbox
+	if err := resp.FromModel(*outbox); err != nil {
+		zap.S().Errorw("failed to decode outbox status", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build outbox"})
+		return
+	}
 
 	c.JSON(http.StatusOK, resp)
 }
@@ -7,11 +7,15 @@ import (
 type Handler struct {
 	consoleSrv *services.Console
 	collector  *services.CollectorService
+	schedule   *services.ScheduleService
+	vcenters   *services.VCenterService
 }
 
-func New(consoleSrv *services.Console, collector *services.CollectorService) *Handler {
+func New(consoleSrv *services.Console, collector *services.CollectorService, schedule *services.ScheduleService, vcenters *services.VCenterService) *Handler {
 	return &Handler{
 		consoleSrv: consoleSrv,
 		collector:  collector,
+		schedule:   schedule,
+		vcenters:   vcenters,
 	}
 }
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+// GetCollectionSchedule returns the collection schedule
+// (GET /collector/schedule)
+func (h *Handler) GetCollectionSchedule(c *gin.Context) {
+	schedule, err := h.schedule.Get()
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no collection schedule set"})
+			return
+		}
+		zap.S().Errorw("failed to get collection schedule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get collection schedule"})
+		return
+	}
+
+	var resp v1.CollectionSchedule
+	resp.FromModel(*schedule)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetCollectionSchedule sets the collection schedule
+// (PUT /collector/schedule)
+func (h *Handler) SetCollectionSchedule(c *gin.Context) {
+	var req v1.CollectionScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, "invalid request body"))
+		return
+	}
+
+	schedule := &models.CollectionSchedule{}
+	if req.Interval != nil && *req.Interval != "" {
+		interval, err := time.ParseDuration(*req.Interval)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, "invalid interval: "+err.Error()))
+			return
+		}
+		schedule.Interval = interval
+	}
+	if req.Cron != nil {
+		schedule.Cron = *req.Cron
+	}
+
+	saved, err := h.schedule.Set(c.Request.Context(), schedule)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, err.Error()))
+			return
+		}
+		zap.S().Errorw("failed to set collection schedule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set collection schedule"})
+		return
+	}
+
+	var resp v1.CollectionSchedule
+	resp.FromModel(*saved)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteCollectionSchedule removes the collection schedule
+// (DELETE /collector/schedule)
+func (h *Handler) DeleteCollectionSchedule(c *gin.Context) {
+	if err := h.schedule.Delete(c.Request.Context()); err != nil {
+		zap.S().Errorw("failed to remove collection schedule", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove collection schedule"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	v1 "github.com/kubev2v/assisted-migration-agent/api/v1"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+)
+
+// ListVCenters returns the vCenters
+// (GET /vcenters)
+func (h *Handler) ListVCenters(c *gin.Context) {
+	vcenters, err := h.vcenters.List(c.Request.Context())
+	if err != nil {
+		zap.S().Errorw("failed to list vcenters", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list vcenters"})
+		return
+	}
+
+	resp := v1.VCenterList{Vcenters: make([]v1.VCenter, 0, len(vcenters))}
+	for _, vcenter := range vcenters {
+		var v v1.VCenter
+		v.FromModel(vcenter)
+		resp.Vcenters = append(resp.Vcenters, v)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateVCenter adds a vCenter
+// (POST /vcenters)
+func (h *Handler) CreateVCenter(c *gin.Context) {
+	var req v1.VCenterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, "invalid request body"))
+		return
+	}
+
+	if err := services.ValidateVCenterName(req.Name); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, err.Error()))
+		return
+	}
+	creds, err := credentialsFromRequest(v1.CollectorStartRequest{
+		Url:        req.Url,
+		Username:   req.Username,
+		Password:   req.Password,
+		CaCert:     req.CaCert,
+		Thumbprint: req.Thumbprint,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	vcenter, err := h.vcenters.Create(c.Request.Context(), req.Name, creds)
+	if err != nil {
+		if errors.Is(err, store.ErrAlreadyExists) {
+			c.JSON(http.StatusConflict, errorResponse(v1.ErrorCodeVcenterExists, "a vcenter with the same name exists"))
+			return
+		}
+		zap.S().Errorw("failed to add vcenter", "error", err, "name", req.Name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add vcenter"})
+		return
+	}
+
+	var resp v1.VCenter
+	resp.FromModel(*vcenter)
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetVCenter returns a vCenter
+// (GET /vcenters/{name})
+func (h *Handler) GetVCenter(c *gin.Context, name string) {
+	vcenter, err := h.vcenters.Get(c.Request.Context(), name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "vcenter not found"})
+			return
+		}
+		zap.S().Errorw("failed to get vcenter", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get vcenter"})
+		return
+	}
+
+	var resp v1.VCenter
+	resp.FromModel(*vcenter)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateVCenter replaces the credentials of a vCenter
+// (PUT /vcenters/{name})
+func (h *Handler) UpdateVCenter(c *gin.Context, name string) {
+	var req v1.CollectorStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, "invalid request body"))
+		return
+	}
+
+	creds, err := credentialsFromRequest(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(v1.ErrorCodeInvalidRequest, err.Error()))
+		return
+	}
+
+	vcenter, err := h.vcenters.Update(c.Request.Context(), name, creds)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "vcenter not found"})
+		case errors.Is(err, services.ErrCollectionInProgress):
+			c.JSON(http.StatusConflict, errorResponse(v1.ErrorCodeCollectionInProgress, "collection in progress"))
+		default:
+			zap.S().Errorw("failed to update vcenter", "error", err, "name", name)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update vcenter"})
+		}
+		return
+	}
+
+	var resp v1.VCenter
+	resp.FromModel(*vcenter)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteVCenter removes a vCenter
+// (DELETE /vcenters/{name})
+func (h *Handler) DeleteVCenter(c *gin.Context, name string) {
+	if err := h.vcenters.Delete(c.Request.Context(), name); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "vcenter not found"})
+			return
+		}
+		zap.S().Errorw("failed to remove vcenter", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove vcenter"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CollectVCenter starts the collection of a vCenter
+// (POST /vcenters/{name}/collect)
+func (h *Handler) CollectVCenter(c *gin.Context, name string, params v1.CollectVCenterParams) {
+	fullResync := params.FullResync != nil && *params.FullResync
+	vcenter, err := h.vcenters.Collect(c.Request.Context(), name, fullResync)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "vcenter not found"})
+			return
+		}
+		zap.S().Errorw("failed to collect vcenter", "error", err, "name", name)
+		c.JSON(collectorError(err))
+		return
+	}
+
+	var resp v1.VCenter
+	resp.FromModel(*vcenter)
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// StopVCenterCollection stops the collection of a vCenter
+// (DELETE /vcenters/{name}/collect)
+func (h *Handler) StopVCenterCollection(c *gin.Context, name string) {
+	vcenter, err := h.vcenters.Stop(c.Request.Context(), name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "vcenter not found"})
+			return
+		}
+		zap.S().Errorw("failed to stop vcenter collection", "error", err, "name", name)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to stop vcenter collection"})
+		return
+	}
+
+	var resp v1.VCenter
+	resp.FromModel(*vcenter)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListVCenterCollectionRuns returns a page of the collection history of a vCenter
+// (GET /vcenters/{name}/runs)
+func (h *Handler) ListVCenterCollectionRuns(c *gin.Context, name string, params v1.ListVCenterCollectionRunsParams) {
+	if collector, ok := h.vcenterCollector(c, name); ok {
+		listCollectionRuns(c, collector, params.Limit, params.Offset)
+	}
+}
+
+// GetVCenterInventory returns the latest inventory snapshot of a vCenter
+// (GET /vcenters/{name}/inventory)
+func (h *Handler) GetVCenterInventory(c *gin.Context, name string) {
+	if collector, ok := h.vcenterCollector(c, name); ok {
+		getInventory(c, collector)
+	}
+}
+
+// GetVCenterInventoryDiff returns the changes between two inventory snapshots of a vCenter
+// (GET /vcenters/{name}/inventory/diff)
+func (h *Handler) GetVCenterInventoryDiff(c *gin.Context, name string, params v1.GetVCenterInventoryDiffParams) {
+	if collector, ok := h.vcenterCollector(c, name); ok {
+		getInventoryDiff(c, collector, params.From, params.To)
+	}
+}
+
+// ListVCenterInventorySnapshots returns the inventory snapshots metadata of a vCenter
+// (GET /vcenters/{name}/inventory/snapshots)
+func (h *Handler) ListVCenterInventorySnapshots(c *gin.Context, name string) {
+	if collector, ok := h.vcenterCollector(c, name); ok {
+		listInventorySnapshots(c, collector)
+	}
+}
+
+// GetVCenterInventorySnapshot returns an inventory snapshot of a vCenter with its inventory
+// (GET /vcenters/{name}/inventory/snapshots/{id})
+func (h *Handler) GetVCenterInventorySnapshot(c *gin.Context, name string, id int64) {
+	if collector, ok := h.vcenterCollector(c, name); ok {
+		getInventorySnapshot(c, collector, id)
+	}
+}
+
+// vcenterCollector returns the collector of the vCenter, or responds with not found.
+func (h *Handler) vcenterCollector(c *gin.Context, name string) (*services.CollectorService, bool) {
+	collector, err := h.vcenters.Collector(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "vcenter not found"})
+		return nil, false
+	}
+	return collector, true
+}
@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type AgentMode string
 
@@ -9,6 +12,21 @@ const (
 	AgentModeDisconnected AgentMode = "disconnected"
 )
 
+// AgentSettings holds the agent mode and the data-sharing consent chosen through the API,
+// along with when and by what they were set.
+type AgentSettings struct {
+	// Mode is empty until a mode is chosen
+	Mode               AgentMode
+	ModeSetAt          time.Time
+	ModeSetBy          string
+	DataSharingAllowed bool
+	// ConsentTermsVersion is the version of the terms the consent was given for
+	ConsentTermsVersion string
+	ConsentSetAt        time.Time
+	ConsentSetBy        string
+	UpdatedAt           time.Time
+}
+
 type ConsoleStatusType string
 
 const (
@@ -29,10 +47,32 @@ func ParseConsoleStatusType(s string) (ConsoleStatusType, error) {
 	}
 }
 
+// ConsoleStopReason is the reason the agent stopped sending updates to console on its own.
+type ConsoleStopReason string
+
+const (
+	ConsoleStopReasonSourceGone   ConsoleStopReason = "source_gone"
+	ConsoleStopReasonUnauthorized ConsoleStopReason = "unauthorized"
+)
+
 type ConsoleStatus struct {
 	Current ConsoleStatusType
 	Target  ConsoleStatusType
-	Error   error
+	// StopReason is set when console rejected the agent, until the agent is switched to connected mode again
+	StopReason          ConsoleStopReason
+	Error               error
+	LastStatusUpdate    time.Time
+	LastInventoryUpdate time.Time
+	StatusBackoff       BackoffStatus
+	InventoryBackoff    BackoffStatus
+}
+
+// BackoffStatus describes the retries of the updates sent to console after consecutive failures.
+type BackoffStatus struct {
+	ConsecutiveFailures int
+	// Delay is the delay before the next attempt, zero when the last attempt succeeded
+	Delay       time.Duration
+	NextAttempt time.Time
 }
 
 type CollectorStatusType string
@@ -44,9 +84,13 @@ const (
 	CollectorStatusCollecting CollectorStatusType = "collecting"
 	CollectorStatusCollected  CollectorStatusType = "collected"
 	CollectorStatusError      CollectorStatusType = "error"
+	CollectorStatusCancelled  CollectorStatusType = "cancelled"
 )
 
 type AgentStatus struct {
+	ID        string
+	SourceID  string
+	Version   string
 	Console   ConsoleStatus
 	Collector CollectorStatusType
 }
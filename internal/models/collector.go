@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // CollectorState represents the current state of the collector.
 type CollectorState string
 
@@ -16,11 +18,66 @@ const (
 	CollectorStateCollected CollectorState = "collected"
 	// CollectorStateError - error during connecting or collecting
 	CollectorStateError CollectorState = "error"
+	// CollectorStateCancelled - collection stopped before completion
+	CollectorStateCancelled CollectorState = "cancelled"
 )
 
+// CollectorPhase is the step a running collection is at.
+type CollectorPhase string
+
+const (
+	// CollectorPhaseConnecting - creating the forklift collector
+	CollectorPhaseConnecting CollectorPhase = "connecting"
+	// CollectorPhaseSyncing - forklift collector syncing vCenter objects into its database
+	CollectorPhaseSyncing CollectorPhase = "syncing"
+	// CollectorPhaseBuildingInventory - building the inventory from the forklift database
+	CollectorPhaseBuildingInventory CollectorPhase = "building_inventory"
+	// CollectorPhasePersisting - saving the inventory snapshot
+	CollectorPhasePersisting CollectorPhase = "persisting"
+)
+
+// CollectorProgress describes a running collection.
+type CollectorProgress struct {
+	Phase     CollectorPhase
+	StartedAt time.Time
+	Elapsed   time.Duration
+	// Objects already synced into the forklift database
+	VMs        int
+	Hosts      int
+	Datastores int
+	// EstimatedCompletion is based on the duration of the previous collection
+	// of the same vCenter. Zero when unknown.
+	EstimatedCompletion time.Time
+}
+
+// Preflight is the result of the vCenter privilege check run before a collection.
+type Preflight struct {
+	CheckedAt time.Time
+	// MissingPrivileges lists the inventory objects on which required privileges are not granted
+	MissingPrivileges []MissingPrivileges
+}
+
+// Passed reports whether all the required privileges are granted.
+func (p Preflight) Passed() bool {
+	return len(p.MissingPrivileges) == 0
+}
+
+// MissingPrivileges lists the required privileges not granted on an inventory object.
+type MissingPrivileges struct {
+	// Entity is the managed object id
+	Entity     string
+	EntityType string
+	EntityName string
+	Privileges []string
+}
+
 // CollectorStatus holds the current collector state and metadata.
 type CollectorStatus struct {
 	State          CollectorState
 	Error          string
 	HasCredentials bool
+	// Progress is set while collecting
+	Progress *CollectorProgress
+	// Preflight is the result of the last privilege check, nil if none was run
+	Preflight *Preflight
 }
@@ -4,9 +4,14 @@ import "time"
 
 // Credentials represents stored vCenter credentials.
 type Credentials struct {
-	URL                  string
-	Username             string
-	Password             string
+	URL      string
+	Username string
+	Password string
+	// CACert is a PEM bundle used to verify the vCenter certificate instead of the system roots
+	CACert string
+	// Thumbprint pins the vCenter certificate when it cannot be verified with the CAs
+	Thumbprint string
+	// Deprecated: the data-sharing consent is stored in the agent settings.
 	IsDataSharingAllowed bool
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
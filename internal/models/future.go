@@ -11,12 +11,14 @@ type Future[T any] struct {
 	value       T
 	cancel      context.CancelFunc
 	lock        sync.Mutex
+	done        chan struct{}
 }
 
 func NewFuture[T any](input chan T, cancel context.CancelFunc) *Future[T] {
 	f := &Future[T]{
 		input:  input,
 		cancel: cancel,
+		done:   make(chan struct{}),
 	}
 
 	go func() {
@@ -27,6 +29,7 @@ func NewFuture[T any](input chan T, cancel context.CancelFunc) *Future[T] {
 		f.value = v
 		f.inputClosed = true
 		f.cancel()
+		close(f.done)
 	}()
 
 	return f
@@ -38,6 +41,11 @@ func (f *Future[T]) IsResolved() bool {
 	return f.inputClosed
 }
 
+// Done returns a channel closed once the future is resolved.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
 func (f *Future[T]) Result() (value T) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
@@ -19,9 +19,78 @@ type InfrastructureData struct {
 	VmsPerCluster         []int
 }
 
-// Inventory represents inventory data stored in the database.
+// Inventory represents an inventory snapshot stored in the database.
 type Inventory struct {
-	Data      []byte
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          int64
+	StartedAt   time.Time
+	CompletedAt time.Time
+	VCenterURL  string
+	Hash        string
+	Data        []byte
+	VMs         []byte // JSON encoded []VMSummary, nil for snapshots taken without it
+	CreatedAt   time.Time
+}
+
+// VMSummary holds the VM attributes compared between inventory snapshots.
+type VMSummary struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	CpuCount   int      `json:"cpuCount"`
+	MemoryMB   int      `json:"memoryMB"`
+	DisksGB    []int    `json:"disksGB"`
+	Networks   []string `json:"networks"`
+	PowerState string   `json:"powerState"`
+	Host       string   `json:"host"`
+	Datastores []string `json:"datastores"`
+}
+
+// InventoryDiff describes the changes between two inventory snapshots.
+type InventoryDiff struct {
+	From int64
+	To   int64
+	// VMsCompared is false when one of the snapshots has no VM summaries.
+	VMsCompared bool
+	AddedVMs    []VMSummary
+	RemovedVMs  []VMSummary
+	ChangedVMs  []VMChange
+	VMs         CountChange
+	Hosts       CountChange
+	Clusters    CountChange
+	Datastores  CountChange
+}
+
+// VMChange lists the attributes of a VM that changed between two snapshots.
+type VMChange struct {
+	ID      string
+	Name    string
+	Changes []FieldChange
+}
+
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+type CountChange struct {
+	From int
+	To   int
+}
+
+// InventoryUpload tracks the delivery of the inventory to console.
+// The pending inventory is the last inventory sent without success.
+type InventoryUpload struct {
+	SourceID      string
+	DeliveredHash string
+	DeliveredAt   time.Time
+	PendingHash   string
+	PendingSince  time.Time
+	// Attempts is the number of failed attempts to send the pending inventory
+	Attempts int
+	Error    string
+}
+
+// Pending reports whether an inventory is waiting to be delivered.
+func (u InventoryUpload) Pending() bool {
+	return u.PendingHash != ""
 }
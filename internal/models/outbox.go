@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// InventoryWithheldReason is the reason the agent would not send the inventory to console next.
+type InventoryWithheldReason string
+
+const (
+	InventoryWithheldNoConsent    InventoryWithheldReason = "no_consent"
+	InventoryWithheldNotCollected InventoryWithheldReason = "not_collected"
+	InventoryWithheldUnchanged    InventoryWithheldReason = "unchanged"
+)
+
+// OutboxRequest is a request to console built without being sent.
+type OutboxRequest struct {
+	Method string
+	URL    string
+	// Body is the uncompressed body of the request
+	Body       []byte
+	Compressed bool
+}
+
+// Outbox holds the requests the agent would send next to console.
+type Outbox struct {
+	Status OutboxRequest
+	// Inventory is nil when the inventory would not be sent, for the withheld reason
+	Inventory         *OutboxRequest
+	InventoryHash     string
+	InventoryWithheld InventoryWithheldReason
+	// LastUploadHash and LastUploadAt are the hash and time of the last inventory delivered to console
+	LastUploadHash string
+	LastUploadAt   time.Time
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// CollectionTrigger is what started a collection.
+type CollectionTrigger string
+
+const (
+	CollectionTriggerManual    CollectionTrigger = "manual"
+	CollectionTriggerScheduled CollectionTrigger = "scheduled"
+)
+
+// CollectionRunState is the state of a collection run.
+type CollectionRunState string
+
+const (
+	CollectionRunStateRunning   CollectionRunState = "running"
+	CollectionRunStateSucceeded CollectionRunState = "succeeded"
+	CollectionRunStateFailed    CollectionRunState = "failed"
+	CollectionRunStateCancelled CollectionRunState = "cancelled"
+)
+
+// CollectionRun records a collection, from the credentials verification to the inventory snapshot.
+type CollectionRun struct {
+	ID         int64
+	Trigger    CollectionTrigger
+	VCenterURL string
+	State      CollectionRunState
+	Error      string
+	StartedAt  time.Time
+	// EndedAt is zero while running
+	EndedAt time.Time
+	// Objects synced from vCenter
+	VMs        int
+	Hosts      int
+	Datastores int
+	// SnapshotID is the inventory snapshot saved by the run, zero if none
+	SnapshotID int64
+	// Incremental is set when the run only applied the vCenter changes since the previous run
+	Incremental bool
+}
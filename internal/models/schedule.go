@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ScheduledRunStatus is the outcome of a scheduled collection.
+type ScheduledRunStatus string
+
+const (
+	// ScheduledRunStatusRunning - the collection was started and is not finished
+	ScheduledRunStatusRunning ScheduledRunStatus = "running"
+	// ScheduledRunStatusSucceeded - the inventory was collected
+	ScheduledRunStatusSucceeded ScheduledRunStatus = "succeeded"
+	// ScheduledRunStatusFailed - the collection could not be started or failed
+	ScheduledRunStatusFailed ScheduledRunStatus = "failed"
+	// ScheduledRunStatusCancelled - the collection was stopped before completion
+	ScheduledRunStatusCancelled ScheduledRunStatus = "cancelled"
+	// ScheduledRunStatusSkipped - a collection was already in progress
+	ScheduledRunStatusSkipped ScheduledRunStatus = "skipped"
+)
+
+// CollectionSchedule triggers periodic re-collections with the stored credentials.
+// Exactly one of Interval and Cron is set.
+type CollectionSchedule struct {
+	Interval time.Duration
+	// Cron is a standard 5 fields cron expression
+	Cron string
+	// LastRun is the outcome of the last scheduled collection, nil if none ran
+	LastRun *ScheduledRun
+	// NextRun is computed by the scheduler, it is not persisted
+	NextRun   time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ScheduledRun is a collection triggered by the schedule.
+type ScheduledRun struct {
+	StartedAt time.Time
+	Status    ScheduledRunStatus
+	Error     string
+}
@@ -1,7 +1,26 @@
 package models
 
+import "time"
+
 type VCenterCredentials struct {
 	URL      string
 	Username string
 	Password string
 }
+
+// VCenter is a named vCenter endpoint collected in addition to the vCenter of the collector.
+type VCenter struct {
+	Name        string
+	Credentials Credentials
+	State       CollectorState
+	// Error is the message of the last error when State is error
+	Error string
+	// Progress is set while collecting
+	Progress *CollectorProgress
+	// Preflight is the result of the last privilege check, nil if none was run
+	Preflight *Preflight
+	// CollectedAt is zero until the first successful collection
+	CollectedAt time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
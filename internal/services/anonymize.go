@@ -0,0 +1,155 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+)
+
+// AnonymizedField is a group of identifying fields of the inventory sent to console.
+type AnonymizedField string
+
+const (
+	// AnonymizedFieldVCenter is the id of the vCenter
+	AnonymizedFieldVCenter AnonymizedField = "vcenter"
+	// AnonymizedFieldCluster is the key of the clusters, made of the cluster id and the vCenter name
+	AnonymizedFieldCluster AnonymizedField = "cluster"
+	// AnonymizedFieldHost is the id of the hosts, including the host a datastore is attached to
+	AnonymizedFieldHost AnonymizedField = "host"
+	// AnonymizedFieldDatastore is the disk id of the datastores
+	AnonymizedFieldDatastore AnonymizedField = "datastore"
+	// AnonymizedFieldNetwork is the name and the distributed switch of the networks. The VLAN id is removed,
+	// since the few possible VLAN ids would make its hash easy to reverse.
+	AnonymizedFieldNetwork AnonymizedField = "network"
+)
+
+// AnonymizedFields lists the fields anonymized when no field list is configured.
+var AnonymizedFields = []AnonymizedField{
+	AnonymizedFieldVCenter,
+	AnonymizedFieldCluster,
+	AnonymizedFieldHost,
+	AnonymizedFieldDatastore,
+	AnonymizedFieldNetwork,
+}
+
+// anonymizedHashLength is the number of hex characters kept from the hash of an identifying value.
+const anonymizedHashLength = 16
+
+// ParseAnonymizedFields parses the configured field list, all the fields when empty.
+func ParseAnonymizedFields(names []string) ([]AnonymizedField, error) {
+	if len(names) == 0 {
+		return AnonymizedFields, nil
+	}
+
+	fields := make([]AnonymizedField, 0, len(names))
+	for _, name := range names {
+		field := AnonymizedField(strings.TrimSpace(name))
+		if !slices.Contains(AnonymizedFields, field) {
+			return nil, fmt.Errorf("unknown field %q: must be one of %v", name, AnonymizedFields)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// InventoryAnonymizer replaces the identifying fields of the inventory with salted hashes.
+// The same value always gets the same hash for a given salt, so the anonymized inventories can still be compared.
+type InventoryAnonymizer struct {
+	salt   []byte
+	fields []AnonymizedField
+}
+
+func NewInventoryAnonymizer(salt []byte, fields []AnonymizedField) *InventoryAnonymizer {
+	return &InventoryAnonymizer{salt: salt, fields: fields}
+}
+
+// Anonymize returns the inventory with the identifying fields replaced.
+func (a *InventoryAnonymizer) Anonymize(data []byte) ([]byte, error) {
+	var inventory apiplanner.Inventory
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory: %w", err)
+	}
+
+	if a.anonymizes(AnonymizedFieldVCenter) {
+		inventory.VcenterId = a.hash(inventory.VcenterId)
+	}
+	if inventory.Vcenter != nil {
+		a.anonymizeData(inventory.Vcenter)
+	}
+
+	if inventory.Clusters != nil {
+		clusters := make(map[string]apiplanner.InventoryData, len(inventory.Clusters))
+		for key, cluster := range inventory.Clusters {
+			if a.anonymizes(AnonymizedFieldCluster) {
+				key = a.hash(key)
+			}
+			a.anonymizeData(&cluster)
+			clusters[key] = cluster
+		}
+		inventory.Clusters = clusters
+	}
+
+	anonymized, err := json.Marshal(inventory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anonymized inventory: %w", err)
+	}
+	return anonymized, nil
+}
+
+func (a *InventoryAnonymizer) anonymizeData(data *apiplanner.InventoryData) {
+	if data.Vcenter != nil && a.anonymizes(AnonymizedFieldVCenter) {
+		data.Vcenter = &apiplanner.VCenter{Id: a.hash(data.Vcenter.Id)}
+	}
+
+	infra := &data.Infra
+	if infra.Hosts != nil && a.anonymizes(AnonymizedFieldHost) {
+		for i := range *infra.Hosts {
+			(*infra.Hosts)[i].Id = a.hashPtr((*infra.Hosts)[i].Id)
+		}
+	}
+
+	for i := range infra.Datastores {
+		if a.anonymizes(AnonymizedFieldDatastore) {
+			infra.Datastores[i].DiskId = a.hash(infra.Datastores[i].DiskId)
+		}
+		if a.anonymizes(AnonymizedFieldHost) {
+			infra.Datastores[i].HostId = a.hashPtr(infra.Datastores[i].HostId)
+		}
+	}
+
+	if a.anonymizes(AnonymizedFieldNetwork) {
+		for i := range infra.Networks {
+			infra.Networks[i].Name = a.hash(infra.Networks[i].Name)
+			infra.Networks[i].Dvswitch = a.hashPtr(infra.Networks[i].Dvswitch)
+			infra.Networks[i].VlanId = nil
+		}
+	}
+}
+
+func (a *InventoryAnonymizer) anonymizes(field AnonymizedField) bool {
+	return slices.Contains(a.fields, field)
+}
+
+// hash returns the salted hash of the value. Empty values are kept empty.
+func (a *InventoryAnonymizer) hash(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:anonymizedHashLength]
+}
+
+func (a *InventoryAnonymizer) hashPtr(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	hashed := a.hash(*value)
+	return &hashed
+}
@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"encoding/json"
+
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+)
+
+var _ = Describe("InventoryAnonymizer", func() {
+	var data []byte
+
+	BeforeEach(func() {
+		hostID := "host-12"
+		dvswitch := "dvs-prod"
+		vlanID := "42"
+		infra := apiplanner.InventoryData{
+			Vcenter: &apiplanner.VCenter{Id: "vcenter-1"},
+			Infra: apiplanner.Infra{
+				Datastores: []apiplanner.Datastore{{DiskId: "naa.6000c29", HostId: &hostID, TotalCapacityGB: 100}},
+				Networks:   []apiplanner.Network{{Name: "prod-net", Dvswitch: &dvswitch, VlanId: &vlanID}},
+				Hosts:      &[]apiplanner.Host{{Id: &hostID, Model: "PowerEdge"}},
+				TotalHosts: 1,
+			},
+			Vms: apiplanner.VMs{Total: 3},
+		}
+
+		var err error
+		data, err = json.Marshal(apiplanner.Inventory{
+			VcenterId: "vcenter-1",
+			Vcenter:   &infra,
+			Clusters:  map[string]apiplanner.InventoryData{"east/domain-c1": infra},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	anonymize := func(salt string, fields ...services.AnonymizedField) apiplanner.Inventory {
+		anonymized, err := services.NewInventoryAnonymizer([]byte(salt), fields).Anonymize(data)
+		Expect(err).NotTo(HaveOccurred())
+
+		var inventory apiplanner.Inventory
+		Expect(json.Unmarshal(anonymized, &inventory)).To(Succeed())
+		return inventory
+	}
+
+	It("should replace the identifying fields and keep the sizing data", func() {
+		inventory := anonymize("salt", services.AnonymizedFields...)
+
+		Expect(inventory.VcenterId).NotTo(Equal("vcenter-1"))
+		Expect(inventory.VcenterId).To(HaveLen(16))
+		Expect(inventory.Clusters).NotTo(HaveKey("east/domain-c1"))
+		Expect(inventory.Clusters).To(HaveLen(1))
+
+		vcenter := inventory.Vcenter
+		Expect(vcenter.Vcenter.Id).To(Equal(inventory.VcenterId))
+		Expect(vcenter.Infra.Datastores[0].DiskId).NotTo(Equal("naa.6000c29"))
+		Expect(*vcenter.Infra.Datastores[0].HostId).To(Equal(*(*vcenter.Infra.Hosts)[0].Id))
+		Expect(*(*vcenter.Infra.Hosts)[0].Id).NotTo(Equal("host-12"))
+		Expect(vcenter.Infra.Networks[0].Name).NotTo(Equal("prod-net"))
+		Expect(*vcenter.Infra.Networks[0].Dvswitch).NotTo(Equal("dvs-prod"))
+		Expect(vcenter.Infra.Networks[0].VlanId).To(BeNil())
+
+		Expect(vcenter.Infra.Datastores[0].TotalCapacityGB).To(Equal(100))
+		Expect((*vcenter.Infra.Hosts)[0].Model).To(Equal("PowerEdge"))
+		Expect(vcenter.Vms.Total).To(Equal(3))
+	})
+
+	It("should hash the same values the same way for a given salt", func() {
+		Expect(anonymize("salt", services.AnonymizedFields...)).To(Equal(anonymize("salt", services.AnonymizedFields...)))
+		Expect(anonymize("salt", services.AnonymizedFields...).VcenterId).NotTo(Equal(anonymize("other", services.AnonymizedFields...).VcenterId))
+	})
+
+	It("should only anonymize the given fields", func() {
+		inventory := anonymize("salt", services.AnonymizedFieldNetwork)
+
+		Expect(inventory.VcenterId).To(Equal("vcenter-1"))
+		Expect(inventory.Clusters).To(HaveKey("east/domain-c1"))
+		Expect(inventory.Vcenter.Infra.Datastores[0].DiskId).To(Equal("naa.6000c29"))
+		Expect(inventory.Vcenter.Infra.Networks[0].Name).NotTo(Equal("prod-net"))
+	})
+
+	It("should parse the configured fields", func() {
+		fields, err := services.ParseAnonymizedFields(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields).To(Equal(services.AnonymizedFields))
+
+		fields, err = services.ParseAnonymizedFields([]string{"network", " host"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields).To(Equal([]services.AnonymizedField{services.AnonymizedFieldNetwork, services.AnonymizedFieldHost}))
+
+		_, err = services.ParseAnonymizedFields([]string{"vm"})
+		Expect(err).To(MatchError(ContainSubstring(`unknown field "vm"`)))
+	})
+})
@@ -3,60 +3,172 @@ package services
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/url"
-	"strings"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/vim25"
-	"github.com/vmware/govmomi/vim25/soap"
 	"go.uber.org/zap"
 
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	agentErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 )
 
 var (
-	ErrCollectionInProgress = errors.New("collection already in progress")
-	ErrInvalidState         = errors.New("invalid state for this operation")
-	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrCollectionInProgress  = errors.New("collection already in progress")
+	ErrInvalidState          = errors.New("invalid state for this operation")
+	ErrInvalidCredentials    = errors.New("invalid credentials")
+	ErrCollectionInterrupted = errors.New("collection interrupted by agent restart")
 )
 
+// collectorEndpoint persists the credentials and the state of the vCenter collected by a CollectorService.
+type collectorEndpoint interface {
+	Credentials(ctx context.Context) (*models.Credentials, error)
+	SaveCredentials(ctx context.Context, creds *models.Credentials) error
+	State(ctx context.Context) (models.CollectorState, string, error)
+	SaveState(ctx context.Context, state models.CollectorState, errorMsg string) error
+}
+
+// CollectorService collects the vCenter of the collector, or a named vCenter. Each collection is
+// recorded as a run and saves an inventory snapshot.
 type CollectorService struct {
-	scheduler  *scheduler.Scheduler
-	store      *store.Store
-	dataFolder string
+	scheduler *scheduler.Scheduler
+	endpoint  collectorEndpoint
+	inventory *store.InventoryStore
+	runs      *store.CollectionRunStore
+	// log adds the name of the vCenter to the logs of a named vCenter
+	log               *zap.SugaredLogger
+	dataFolder        string
+	snapshotRetention int
+	timeout           time.Duration
+	incremental       bool
 
 	mu            sync.RWMutex
 	state         models.CollectorState
 	lastError     error
 	collectFuture *models.Future[models.Result[any]]
+	progress      *models.CollectorProgress
+	preflight     *models.Preflight
+	// running is the collector of the job in progress, used to report the synced objects
+	running *VSphereCollector
+	// estimatedDuration is the duration of the previous collection of the same vCenter
+	estimatedDuration time.Duration
+	// retained is the collector of the last successful collection, kept in sync with vCenter
+	// for the next incremental collection. It is not persisted: forklift cannot resume the
+	// updates of a previous session to vCenter, so the first collection after a restart is a full one.
+	retained *VSphereCollector
+}
+
+func NewCollectorService(s *scheduler.Scheduler, st *store.Store, cfg config.Agent) *CollectorService {
+	return newCollectorService(s, st, cfg, "")
 }
 
-func NewCollectorService(s *scheduler.Scheduler, st *store.Store, dataFolder string) *CollectorService {
+// newCollectorService creates the collector of the named vCenter, or of the vCenter of the collector
+// when the name is empty. The forklift database of a named vCenter is kept in its own folder.
+func newCollectorService(s *scheduler.Scheduler, st *store.Store, cfg config.Agent, name string) *CollectorService {
 	c := &CollectorService{
-		scheduler:  s,
-		store:      st,
-		dataFolder: dataFolder,
-		state:      models.CollectorStateReady,
+		scheduler:         s,
+		endpoint:          &collectorCredentials{store: st},
+		inventory:         st.Inventory().VCenter(name),
+		runs:              st.CollectionRuns().VCenter(name),
+		log:               zap.S(),
+		dataFolder:        cfg.DataFolder,
+		snapshotRetention: cfg.SnapshotRetention,
+		timeout:           cfg.CollectorTimeout,
+		incremental:       cfg.IncrementalCollection,
+		state:             models.CollectorStateReady,
+	}
+	if name != "" {
+		c.endpoint = &vcenterEndpoint{store: st, name: name}
+		c.log = zap.S().With("vcenter", name)
+		c.dataFolder = filepath.Join(cfg.DataFolder, vcentersFolder, name)
 	}
 
 	// Log whether credentials exist from a previous run
-	_, err := st.Credentials().Get(context.Background())
+	_, err := c.endpoint.Credentials(context.Background())
 	if err == nil {
-		zap.S().Info("collector initialized with existing credentials")
+		c.log.Info("collector initialized with existing credentials")
 	} else {
-		zap.S().Info("collector initialized, awaiting credentials")
+		c.log.Info("collector initialized, awaiting credentials")
 	}
 
+	c.restore(context.Background(), cfg.ResumeCollection)
+
 	return c
 }
 
+// restore restores the state persisted before the agent restarted. The runs interrupted by the restart
+// are recorded as failed and, when resume is set, the collection is started again with the stored credentials.
+func (c *CollectorService) restore(ctx context.Context, resume bool) {
+	state, errorMsg, err := c.endpoint.State(ctx)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		c.log.Warnw("failed to get collector state", "error", err)
+	}
+
+	interrupted, err := c.runs.Running(ctx)
+	if err != nil {
+		c.log.Warnw("failed to get interrupted collection runs", "error", err)
+	}
+	for i := range interrupted {
+		c.finishRun(&interrupted[i], ErrCollectionInterrupted)
+	}
+
+	switch state {
+	case models.CollectorStateError:
+		c.state = models.CollectorStateError
+		c.lastError = errors.New(errorMsg)
+		c.log.Infow("collector state restored", "state", c.state, "error", errorMsg)
+		return
+	case models.CollectorStateCancelled:
+		c.state = models.CollectorStateCancelled
+		c.log.Infow("collector state restored", "state", c.state)
+		return
+	case models.CollectorStateConnecting, models.CollectorStateConnected, models.CollectorStateCollecting:
+	default:
+		if len(interrupted) == 0 {
+			return
+		}
+	}
+
+	if !resume {
+		c.log.Warn("collection was interrupted by the agent restart")
+		c.setError(ErrCollectionInterrupted)
+		return
+	}
+
+	trigger := models.CollectionTriggerManual
+	if len(interrupted) > 0 {
+		trigger = interrupted[len(interrupted)-1].Trigger
+	}
+
+	c.log.Info("resuming the collection interrupted by the agent restart")
+	go func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		creds, err := c.endpoint.Credentials(ctx)
+		if err != nil {
+			c.log.Warnw("failed to get credentials to resume the collection", "error", err)
+			c.setError(ErrCollectionInterrupted)
+			return
+		}
+		if err := c.start(ctx, creds, trigger, false); err != nil {
+			c.log.Warnw("failed to resume the collection", "error", err)
+		}
+	}()
+}
+
 // GetStatus returns the current collector status.
 func (c *CollectorService) GetStatus(ctx context.Context) models.CollectorStatus {
 	c.mu.RLock()
@@ -70,89 +182,280 @@ func (c *CollectorService) GetStatus(ctx context.Context) models.CollectorStatus
 		status.Error = c.lastError.Error()
 	}
 
+	if c.progress != nil {
+		status.Progress = c.currentProgress()
+	}
+
+	if c.preflight != nil {
+		preflight := *c.preflight
+		status.Preflight = &preflight
+	}
+
 	// Check if credentials exist
-	_, err := c.store.Credentials().Get(ctx)
+	_, err := c.endpoint.Credentials(ctx)
 	status.HasCredentials = err == nil
 
 	return status
 }
 
+// currentProgress returns a copy of the progress completed with the elapsed time,
+// the synced objects and the estimated completion.
+func (c *CollectorService) currentProgress() *models.CollectorProgress {
+	progress := *c.progress
+	progress.Elapsed = time.Since(progress.StartedAt).Round(time.Second)
+
+	if c.running != nil {
+		vms, hosts, datastores, err := c.running.SyncedCounts()
+		if err != nil {
+			c.log.Debugw("failed to count synced objects", "error", err)
+		} else {
+			progress.VMs, progress.Hosts, progress.Datastores = vms, hosts, datastores
+		}
+	}
+
+	if c.estimatedDuration > 0 {
+		// past the estimate, the completion is unknown
+		if estimate := progress.StartedAt.Add(c.estimatedDuration); estimate.After(time.Now()) {
+			progress.EstimatedCompletion = estimate
+		}
+	}
+
+	return &progress
+}
+
 func (c *CollectorService) setState(state models.CollectorState) {
-	zap.S().Debugw("collector state transition", "from", c.state, "to", state)
+	c.log.Debugw("collector state transition", "from", c.state, "to", state)
 	c.state = state
 	if state != models.CollectorStateError {
 		c.lastError = nil
 	}
+	if state != models.CollectorStateCollecting {
+		c.progress = nil
+	}
+	c.saveState()
 }
 
 func (c *CollectorService) setError(err error) {
 	c.state = models.CollectorStateError
 	c.lastError = err
+	c.progress = nil
+	c.saveState()
+}
+
+// saveState persists the state so it is restored when the agent restarts.
+func (c *CollectorService) saveState() {
+	var errorMsg string
+	if c.lastError != nil {
+		errorMsg = c.lastError.Error()
+	}
+	if err := c.endpoint.SaveState(context.Background(), c.state, errorMsg); err != nil {
+		c.log.Warnw("failed to save collector state", "state", c.state, "error", err)
+	}
+}
+
+func (c *CollectorService) setPhase(phase models.CollectorPhase) {
+	c.log.Debugw("collection phase", "phase", phase)
+	if c.progress != nil {
+		c.progress.Phase = phase
+	}
 }
 
 // Start saves credentials, verifies them with vCenter, and starts async collection.
-func (c *CollectorService) Start(ctx context.Context, creds *models.Credentials) error {
+// With fullResync, the inventory is collected from scratch even in incremental mode.
+func (c *CollectorService) Start(ctx context.Context, creds *models.Credentials, fullResync bool) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Check if collection is already in progress using the future
-	if c.collectFuture != nil && !c.collectFuture.IsResolved() {
+	if c.collecting() {
 		return ErrCollectionInProgress
 	}
+	if err := c.endpoint.SaveCredentials(ctx, creds); err != nil {
+		return err
+	}
+
+	return c.start(ctx, creds, models.CollectionTriggerManual, fullResync)
+}
+
+// Collect verifies the stored credentials with vCenter and starts async collection.
+// With fullResync, the inventory is collected from scratch even in incremental mode.
+func (c *CollectorService) Collect(ctx context.Context, fullResync bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	creds, err := c.endpoint.Credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	return c.start(ctx, creds, models.CollectionTriggerManual, fullResync)
+}
+
+// UpdateCredentials replaces the stored credentials and resets the state to ready.
+// The collector kept for incremental collections is stopped, so the next collection is a full one.
+func (c *CollectorService) UpdateCredentials(ctx context.Context, creds *models.Credentials) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// Save credentials
-	if err := c.store.Credentials().Save(ctx, creds); err != nil {
+	if c.collecting() {
+		return ErrCollectionInProgress
+	}
+	if err := c.endpoint.SaveCredentials(ctx, creds); err != nil {
 		return err
 	}
 
+	if c.retained != nil {
+		c.retained.Close()
+		c.retained = nil
+	}
+	c.preflight = nil
+	c.setState(models.CollectorStateReady)
+	return nil
+}
+
+// Recollect starts a collection with the stored credentials and returns the collection job.
+func (c *CollectorService) Recollect(ctx context.Context) (*models.Future[models.Result[any]], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	creds, err := c.endpoint.Credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	if err := c.start(ctx, creds, models.CollectionTriggerScheduled, false); err != nil {
+		return nil, err
+	}
+	if c.collectFuture == nil {
+		return nil, c.lastError
+	}
+	return c.collectFuture, nil
+}
+
+// start verifies the credentials and starts the collection job. It must be called with the lock held.
+func (c *CollectorService) start(ctx context.Context, creds *models.Credentials, trigger models.CollectionTrigger, fullResync bool) error {
+	if c.collecting() {
+		return ErrCollectionInProgress
+	}
+
 	// Set connecting state
 	c.setState(models.CollectorStateConnecting)
+	run := c.startRun(ctx, trigger, creds.URL)
 
-	// Verify credentials synchronously
-	if err := c.verifyCredentials(ctx, creds); err != nil {
+	// Verify credentials and privileges synchronously
+	preflight, err := verifyCredentials(ctx, creds)
+	if preflight != nil {
+		c.preflight = preflight
+	}
+	if err != nil {
 		c.setError(err)
+		c.finishRun(run, err)
 		return err
 	}
 
-	// Credentials verified, set connected
+	// Credentials verified, set connected. Missing privileges are reported in the preflight but
+	// do not prevent the collection of the objects the user can read.
 	c.setState(models.CollectorStateConnected)
 
 	// Start async collection
-	c.startCollectionJob()
+	c.startCollectionJob(run, fullResync)
 
 	return nil
 }
 
+// collecting reports whether a collection job is in progress. It must be called with the lock held.
+func (c *CollectorService) collecting() bool {
+	return c.collectFuture != nil && !c.collectFuture.IsResolved()
+}
+
+// Verify checks the credentials and the privileges of the user with vCenter, without saving them
+// nor starting a collection. The result is reported as the preflight of the collector status.
+func (c *CollectorService) Verify(ctx context.Context, creds *models.Credentials) (*models.Preflight, error) {
+	preflight, err := verifyCredentials(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.preflight = preflight
+	c.mu.Unlock()
+
+	return preflight, nil
+}
+
 // Stop cancels any running collection but keeps credentials for retry.
+// The state is cancelled when a collection was running, ready otherwise.
+// The collector kept for incremental collections is stopped, so the next collection is a full one.
 func (c *CollectorService) Stop(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.retained != nil {
+		c.retained.Close()
+		c.retained = nil
+	}
+
+	// Keep credentials - user can retry with same credentials
 	// Cancel running job if any (this triggers context cancellation in the job)
-	if c.collectFuture != nil && !c.collectFuture.IsResolved() {
+	if c.collecting() {
 		c.collectFuture.Stop()
+		c.collectFuture = nil
+		c.setState(models.CollectorStateCancelled)
+		return nil
 	}
 	c.collectFuture = nil
 
-	// Keep credentials - user can retry with same credentials
-	// Reset state to ready
 	c.setState(models.CollectorStateReady)
 	return nil
 }
 
-// verifyCredentials tests the vCenter connection.
-func (c *CollectorService) verifyCredentials(ctx context.Context, creds *models.Credentials) error {
+// close stops the collection and waits for its job to return, so nothing is saved afterwards.
+func (c *CollectorService) close(ctx context.Context) error {
+	c.mu.Lock()
+	job := c.collectFuture
+	c.mu.Unlock()
+
+	if err := c.Stop(ctx); err != nil {
+		return err
+	}
+	if job != nil {
+		select {
+		case <-job.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// the job may have retained its collector before being stopped
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.retained != nil {
+		c.retained.Close()
+		c.retained = nil
+	}
+	return nil
+}
+
+// verifyCredentials tests the vCenter connection and checks the privileges of the user.
+func verifyCredentials(ctx context.Context, creds *models.Credentials) (*models.Preflight, error) {
 	u, err := parseVCenterURL(creds)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	verifyCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	vimClient, err := vim25.NewClient(verifyCtx, soap.NewClient(u, true))
+	soapClient, err := newSoapClient(u, creds)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	vimClient, err := vim25.NewClient(verifyCtx, soapClient)
+	if err != nil {
+		return nil, connectionError(verifyCtx, u, err)
+	}
+	if apiType := vimClient.ServiceContent.About.ApiType; apiType != vCenterAPIType {
+		return nil, agentErrors.NewNotVCenterError(u.Host, apiType, nil)
 	}
 
 	client := &govmomi.Client{
@@ -162,18 +465,27 @@ func (c *CollectorService) verifyCredentials(ctx context.Context, creds *models.
 
 	zap.S().Info("verifying vCenter credentials")
 	if err := client.Login(verifyCtx, u.User); err != nil {
-		if strings.Contains(err.Error(), "Login failure") ||
-			(strings.Contains(err.Error(), "incorrect") && strings.Contains(err.Error(), "password")) {
-			return ErrInvalidCredentials
-		}
-		return err
+		return nil, sessionError(u, err)
 	}
+	defer func() {
+		_ = client.Logout(context.Background())
+		client.CloseIdleConnections()
+	}()
+	zap.S().Info("vCenter credentials verified successfully")
 
-	_ = client.Logout(verifyCtx)
-	client.CloseIdleConnections()
+	preflightCtx, cancelPreflight := context.WithTimeout(ctx, preflightTimeout)
+	defer cancelPreflight()
 
-	zap.S().Info("vCenter credentials verified successfully")
-	return nil
+	preflight, err := runPreflight(preflightCtx, client)
+	if err != nil {
+		return nil, sessionError(u, err)
+	}
+	if !preflight.Passed() {
+		zap.S().Warnw("vCenter user is missing privileges, the inventory may be partial",
+			"privileges", preflightMissingPrivileges(preflight), "objects", len(preflight.MissingPrivileges))
+	}
+
+	return preflight, nil
 }
 
 func parseVCenterURL(creds *models.Credentials) (*url.URL, error) {
@@ -188,67 +500,260 @@ func parseVCenterURL(creds *models.Credentials) (*url.URL, error) {
 	return u, nil
 }
 
+// startRun records a new collection run. A failure to record it does not prevent the collection.
+func (c *CollectorService) startRun(ctx context.Context, trigger models.CollectionTrigger, vcenterURL string) *models.CollectionRun {
+	run := &models.CollectionRun{
+		Trigger:    trigger,
+		VCenterURL: vcenterURL,
+		State:      models.CollectionRunStateRunning,
+		StartedAt:  time.Now(),
+	}
+
+	id, err := c.runs.Create(ctx, run)
+	if err != nil {
+		c.log.Warnw("failed to record collection run", "error", err)
+		return run
+	}
+	run.ID = id
+
+	return run
+}
+
+// finishRun records the outcome of the run from the error which ended it.
+func (c *CollectorService) finishRun(run *models.CollectionRun, err error) {
+	if run.ID == 0 {
+		return
+	}
+
+	run.EndedAt = time.Now()
+	switch {
+	case errors.Is(err, context.Canceled):
+		run.State = models.CollectionRunStateCancelled
+	case err != nil:
+		run.State = models.CollectionRunStateFailed
+		run.Error = err.Error()
+	default:
+		run.State = models.CollectionRunStateSucceeded
+	}
+
+	// the job context may be cancelled already
+	if err := c.runs.Finish(context.Background(), run); err != nil {
+		c.log.Warnw("failed to record collection run outcome", "id", run.ID, "error", err)
+	}
+}
+
 // startCollectionJob starts the async inventory collection using the forklift collector.
-func (c *CollectorService) startCollectionJob() {
+func (c *CollectorService) startCollectionJob(run *models.CollectionRun, fullResync bool) {
 	// Get credentials for the collector
-	creds, err := c.store.Credentials().Get(context.Background())
+	creds, err := c.endpoint.Credentials(context.Background())
 	if err != nil {
-		zap.S().Errorw("failed to get credentials for collection", "error", err)
+		c.log.Errorw("failed to get credentials for collection", "error", err)
 		c.setError(err)
+		c.finishRun(run, err)
 		return
 	}
 
-	c.collectFuture = c.scheduler.AddWork(func(ctx context.Context) (any, error) {
-		c.mu.Lock()
-		c.setState(models.CollectorStateCollecting)
-		c.mu.Unlock()
+	c.collectFuture = c.scheduler.AddWork(func(ctx context.Context) (_ any, err error) {
+		defer func() { c.finishRun(run, err) }()
 
-		zap.S().Info("starting vSphere inventory collection")
+		startedAt := time.Now()
+		estimatedDuration := c.previousCollectionDuration(ctx, creds.URL)
+		c.updateJobState(ctx, func() {
+			c.setState(models.CollectorStateCollecting)
+			c.progress = &models.CollectorProgress{Phase: models.CollectorPhaseConnecting, StartedAt: startedAt}
+			c.estimatedDuration = estimatedDuration
+		})
 
-		// Create the vSphere collector (local to this job)
-		vsphereCollector, err := NewVSphereCollector(creds, c.dataFolder)
+		c.log.Info("starting vSphere inventory collection")
+
+		// Reuse the collector kept in sync since the previous collection, or create a new one
+		vsphereCollector, incremental, err := c.acquireCollector(creds, fullResync)
 		if err != nil {
-			zap.S().Errorw("failed to create vSphere collector", "error", err)
-			c.mu.Lock()
-			c.setError(err)
-			c.mu.Unlock()
+			c.log.Errorw("failed to create vSphere collector", "error", err)
+			c.updateJobState(ctx, func() { c.setError(err) })
 			return nil, err
 		}
-		defer vsphereCollector.Close() // Ensure cleanup when job completes
+		run.Incremental = incremental
+		if incremental {
+			c.log.Info("collecting the vCenter changes since the previous collection")
+		}
 
-		// Run the collection (use ctx from scheduler for cancellation)
-		if err := vsphereCollector.Collect(ctx); err != nil {
-			zap.S().Errorw("vSphere collection failed", "error", err)
+		c.updateJobState(ctx, func() {
+			c.running = vsphereCollector
+			c.setPhase(models.CollectorPhaseSyncing)
+		})
+		defer func() {
+			// the status must never count on a closed database
 			c.mu.Lock()
-			c.setError(err)
+			c.running = nil
 			c.mu.Unlock()
+			c.releaseCollector(vsphereCollector, err == nil)
+		}()
+
+		// Run the collection (use ctx from scheduler for cancellation)
+		collectCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+		if err := vsphereCollector.Collect(collectCtx); err != nil {
+			if errors.Is(err, context.Canceled) {
+				c.log.Info("vSphere collection cancelled")
+				return nil, err
+			}
+			c.log.Errorw("vSphere collection failed", "error", err)
+			c.updateJobState(ctx, func() { c.setError(err) })
 			return nil, err
 		}
 
-		zap.S().Infow("vSphere inventory collection completed", "db_path", vsphereCollector.DBPath())
+		c.log.Infow("vSphere inventory collection completed", "db_path", vsphereCollector.DBPath())
 
-		c.mu.Lock()
-		c.setState(models.CollectorStateCollected)
-		c.mu.Unlock()
+		if vms, hosts, datastores, err := vsphereCollector.SyncedCounts(); err != nil {
+			c.log.Debugw("failed to count synced objects", "error", err)
+		} else {
+			run.VMs, run.Hosts, run.Datastores = vms, hosts, datastores
+		}
+
+		snapshotID, err := c.saveInventory(ctx, vsphereCollector, creds.URL, startedAt, func(phase models.CollectorPhase) {
+			c.updateJobState(ctx, func() { c.setPhase(phase) })
+		})
+		if err != nil {
+			c.log.Errorw("failed to save inventory", "error", err)
+			c.updateJobState(ctx, func() { c.setError(err) })
+			return nil, err
+		}
+		run.SnapshotID = snapshotID
+
+		c.updateJobState(ctx, func() { c.setState(models.CollectorStateCollected) })
 
 		// Transition back to ready after a brief moment
 		time.Sleep(100 * time.Millisecond)
-		c.mu.Lock()
-		c.setState(models.CollectorStateReady)
-		c.mu.Unlock()
+		c.updateJobState(ctx, func() { c.setState(models.CollectorStateReady) })
 
 		return nil, nil
 	})
 }
 
+// acquireCollector returns the collector kept by the previous collection when it collects the same vCenter,
+// or a new collector which collects the inventory from scratch. The boolean reports whether the collection is incremental.
+func (c *CollectorService) acquireCollector(creds *models.Credentials, fullResync bool) (*VSphereCollector, bool, error) {
+	c.mu.Lock()
+	retained := c.retained
+	c.retained = nil
+	c.mu.Unlock()
+
+	if retained != nil {
+		if !fullResync && retained.Matches(creds) {
+			return retained, true, nil
+		}
+		retained.Close()
+	}
+
+	if c.dataFolder != "" {
+		if err := os.MkdirAll(c.dataFolder, 0o750); err != nil {
+			return nil, false, fmt.Errorf("failed to create the collector data folder: %w", err)
+		}
+	}
+	vsphereCollector, err := NewVSphereCollector(creds, c.dataFolder)
+	return vsphereCollector, false, err
+}
+
+// releaseCollector keeps the collector in sync with vCenter for the next collection in incremental mode.
+// It is closed otherwise, or when the collection did not succeed.
+func (c *CollectorService) releaseCollector(vsphereCollector *VSphereCollector, succeeded bool) {
+	if !c.incremental || !succeeded {
+		vsphereCollector.Close()
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retained = vsphereCollector
+}
+
+// updateJobState applies fn under the lock unless the job was stopped,
+// so a cancelled job does not overwrite the state set by Stop or by a newer job.
+func (c *CollectorService) updateJobState(ctx context.Context, fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+	fn()
+}
+
+// previousCollectionDuration returns the duration of the latest collection of the vCenter, or zero if there is none.
+func (c *CollectorService) previousCollectionDuration(ctx context.Context, vcenterURL string) time.Duration {
+	snapshots, err := c.inventory.List(ctx)
+	if err != nil {
+		c.log.Debugw("failed to list inventory snapshots", "error", err)
+		return 0
+	}
+	for _, s := range snapshots {
+		if s.VCenterURL == vcenterURL {
+			return s.CompletedAt.Sub(s.StartedAt)
+		}
+	}
+	return 0
+}
+
+// saveInventory builds the inventory from the forklift database, persists it as a new snapshot
+// and prunes the snapshots beyond the retention. It returns the id of the snapshot.
+func (c *CollectorService) saveInventory(ctx context.Context, vsphereCollector *VSphereCollector, vcenterURL string, startedAt time.Time, setPhase func(models.CollectorPhase)) (int64, error) {
+	setPhase(models.CollectorPhaseBuildingInventory)
+
+	builder := NewInventoryBuilder(vsphereCollector.DB())
+	inventory, err := builder.Build()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build inventory: %w", err)
+	}
+	vms, err := builder.VMs()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build vms summary: %w", err)
+	}
+
+	data, err := json.Marshal(inventory)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal inventory: %w", err)
+	}
+	vmsData, err := json.Marshal(vms)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal vms summary: %w", err)
+	}
+
+	setPhase(models.CollectorPhasePersisting)
+
+	id, err := c.inventory.Save(ctx, &models.Inventory{
+		StartedAt:   startedAt,
+		CompletedAt: time.Now(),
+		VCenterURL:  vcenterURL,
+		Data:        data,
+		VMs:         vmsData,
+	})
+	if err != nil {
+		return 0, err
+	}
+	c.log.Infow("inventory snapshot saved", "id", id)
+
+	if c.snapshotRetention > 0 {
+		pruned, err := c.inventory.Prune(ctx, c.snapshotRetention)
+		if err != nil {
+			// the snapshot is saved, old ones will be pruned after the next collection
+			c.log.Warnw("failed to prune inventory snapshots", "error", err)
+		} else if pruned > 0 {
+			c.log.Debugw("pruned inventory snapshots", "count", pruned)
+		}
+	}
+
+	return id, nil
+}
+
 // GetCredentials retrieves stored credentials.
 func (c *CollectorService) GetCredentials(ctx context.Context) (*models.Credentials, error) {
-	return c.store.Credentials().Get(ctx)
+	return c.endpoint.Credentials(ctx)
 }
 
 // HasCredentials checks if credentials exist.
 func (c *CollectorService) HasCredentials(ctx context.Context) (bool, error) {
-	_, err := c.store.Credentials().Get(ctx)
+	_, err := c.endpoint.Credentials(ctx)
 	if errors.Is(err, store.ErrNotFound) {
 		return false, nil
 	}
@@ -258,9 +763,37 @@ func (c *CollectorService) HasCredentials(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
-// GetInventory retrieves the stored inventory.
+// GetInventory retrieves the latest inventory snapshot.
 func (c *CollectorService) GetInventory(ctx context.Context) (*models.Inventory, error) {
-	return c.store.Inventory().Get(ctx)
+	return c.inventory.Latest(ctx)
+}
+
+// ListInventorySnapshots returns the stored inventory snapshots without their data, newest first.
+func (c *CollectorService) ListInventorySnapshots(ctx context.Context) ([]models.Inventory, error) {
+	return c.inventory.List(ctx)
+}
+
+// DiffInventory compares the inventory snapshots with the given ids.
+func (c *CollectorService) DiffInventory(ctx context.Context, from, to int64) (*models.InventoryDiff, error) {
+	fromSnapshot, err := c.inventory.Get(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	toSnapshot, err := c.inventory.Get(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	return DiffInventories(fromSnapshot, toSnapshot)
+}
+
+// ListRuns returns a page of the collection runs, newest first, along with the total number of runs.
+func (c *CollectorService) ListRuns(ctx context.Context, limit, offset int) ([]models.CollectionRun, int, error) {
+	return c.runs.List(ctx, limit, offset)
+}
+
+// GetInventorySnapshot retrieves the inventory snapshot with the given id.
+func (c *CollectorService) GetInventorySnapshot(ctx context.Context, id int64) (*models.Inventory, error) {
+	return c.inventory.Get(ctx, id)
 }
 
 // Status implements the Collector interface for console service.
@@ -282,20 +815,40 @@ func (c *CollectorService) Status() models.CollectorStatusType {
 		return models.CollectorStatusCollected
 	case models.CollectorStateError:
 		return models.CollectorStatusError
+	case models.CollectorStateCancelled:
+		return models.CollectorStatusCancelled
 	default:
 		return models.CollectorStatusReady
 	}
 }
 
 // Inventory implements the Collector interface for console service.
-// It returns the inventory from the database, or empty JSON if not collected yet.
+// It returns the latest inventory snapshot, store.ErrNotFound until one is collected.
 func (c *CollectorService) Inventory() (io.Reader, error) {
-	inv, err := c.store.Inventory().Get(context.Background())
+	inv, err := c.inventory.Latest(context.Background())
 	if err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			return strings.NewReader("{}"), nil
-		}
 		return nil, err
 	}
 	return bytes.NewReader(inv.Data), nil
 }
+
+// collectorCredentials persists the credentials and the state of the vCenter of the collector.
+type collectorCredentials struct {
+	store *store.Store
+}
+
+func (e *collectorCredentials) Credentials(ctx context.Context) (*models.Credentials, error) {
+	return e.store.Credentials().Get(ctx)
+}
+
+func (e *collectorCredentials) SaveCredentials(ctx context.Context, creds *models.Credentials) error {
+	return e.store.Credentials().Save(ctx, creds)
+}
+
+func (e *collectorCredentials) State(ctx context.Context) (models.CollectorState, string, error) {
+	return e.store.CollectorState().Get(ctx)
+}
+
+func (e *collectorCredentials) SaveState(ctx context.Context, state models.CollectorState, errorMsg string) error {
+	return e.store.CollectorState().Save(ctx, state, errorMsg)
+}
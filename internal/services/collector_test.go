@@ -0,0 +1,411 @@
+package services_test
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/simulator/vpx"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	agentErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+)
+
+var _ = Describe("CollectorService", func() {
+	var (
+		ctx       context.Context
+		sched     *scheduler.Scheduler
+		db        *sql.DB
+		st        *store.Store
+		collector *services.CollectorService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sched = scheduler.NewScheduler(1)
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrations.Run(ctx, db)).To(Succeed())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		st = store.NewStore(db, encryption.NewKeyring(key))
+		collector = services.NewCollectorService(sched, st, config.Agent{
+			DataFolder: GinkgoT().TempDir(),
+		})
+	})
+
+	AfterEach(func() {
+		sched.Close()
+		_ = db.Close()
+	})
+
+	// newSimulator starts a vSphere simulator accepting the user/pass login.
+	newSimulator := func(model *simulator.Model, withTLS bool) *simulator.Server {
+		Expect(model.Create()).To(Succeed())
+		DeferCleanup(model.Remove)
+		model.Service.Listen = &url.URL{User: url.UserPassword("user", "pass")}
+		if withTLS {
+			model.Service.TLS = new(tls.Config)
+		}
+		server := model.Service.NewServer()
+		DeferCleanup(server.Close)
+		return server
+	}
+
+	credentials := func(server *simulator.Server, password string) *models.Credentials {
+		u := *server.URL
+		u.User = nil
+		return &models.Credentials{URL: u.String(), Username: "user", Password: password}
+	}
+
+	Describe("Start", func() {
+		It("should return ErrInvalidCredentials for a wrong password", func() {
+			server := newSimulator(simulator.VPX(), false)
+
+			err := collector.Start(ctx, credentials(server, "wrong"), false)
+			Expect(err).To(MatchError(services.ErrInvalidCredentials))
+			Expect(collector.GetStatus(ctx).State).To(Equal(models.CollectorStateError))
+		})
+
+		It("should record a failed manual run", func() {
+			server := newSimulator(simulator.VPX(), false)
+			creds := credentials(server, "wrong")
+
+			Expect(collector.Start(ctx, creds, false)).NotTo(Succeed())
+
+			runs, total, err := collector.ListRuns(ctx, 20, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(1))
+			Expect(runs[0].Trigger).To(Equal(models.CollectionTriggerManual))
+			Expect(runs[0].VCenterURL).To(Equal(creds.URL))
+			Expect(runs[0].State).To(Equal(models.CollectionRunStateFailed))
+			Expect(runs[0].Error).To(Equal(services.ErrInvalidCredentials.Error()))
+			Expect(runs[0].EndedAt.IsZero()).To(BeFalse())
+		})
+
+		It("should return a NotVCenterError for a standalone ESXi host", func() {
+			server := newSimulator(simulator.ESX(), false)
+
+			err := collector.Start(ctx, credentials(server, "pass"), false)
+			var notVCenter *agentErrors.NotVCenterError
+			Expect(errors.As(err, &notVCenter)).To(BeTrue())
+			Expect(notVCenter.APIType).To(Equal("HostAgent"))
+		})
+
+		It("should return a NotVCenterError for an endpoint which is not vSphere", func() {
+			server := httptest.NewServer(http.NotFoundHandler())
+			defer server.Close()
+
+			err := collector.Start(ctx, &models.Credentials{URL: server.URL, Username: "user", Password: "pass"}, false)
+			Expect(agentErrors.IsNotVCenterError(err)).To(BeTrue())
+		})
+
+		It("should return a VCenterUnreachableError when the connection is refused", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			addr := listener.Addr().String()
+			Expect(listener.Close()).To(Succeed())
+
+			err = collector.Start(ctx, &models.Credentials{URL: "https://" + addr + "/sdk", Username: "user", Password: "pass"}, false)
+			Expect(agentErrors.IsVCenterUnreachableError(err)).To(BeTrue())
+		})
+
+		It("should return a CertificateError with the presented certificate when it is not trusted", func() {
+			server := newSimulator(simulator.VPX(), true)
+
+			err := collector.Start(ctx, credentials(server, "pass"), false)
+			var certErr *agentErrors.CertificateError
+			Expect(errors.As(err, &certErr)).To(BeTrue())
+			Expect(certErr.Thumbprint).To(Equal(soap.ThumbprintSHA1(server.Certificate())))
+		})
+
+		It("should reject a certificate which does not match the thumbprint", func() {
+			server := newSimulator(simulator.VPX(), true)
+
+			creds := credentials(server, "wrong")
+			creds.Thumbprint = "01:23:45:67:89:AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67"
+			err := collector.Start(ctx, creds, false)
+			Expect(agentErrors.IsCertificateError(err)).To(BeTrue())
+		})
+
+		It("should trust a certificate matching the thumbprint", func() {
+			server := newSimulator(simulator.VPX(), true)
+
+			creds := credentials(server, "wrong")
+			creds.Thumbprint = soap.ThumbprintSHA1(server.Certificate())
+			err := collector.Start(ctx, creds, false)
+			Expect(err).To(MatchError(services.ErrInvalidCredentials))
+		})
+
+		It("should collect and report the privileges which are missing", func() {
+			model := simulator.VPX()
+			server := newSimulator(model, true)
+			authz := model.Map().Get(*vpx.ServiceContent.AuthorizationManager).(*simulator.AuthorizationManager)
+			model.Map().Put(&denyingAuthorizationManager{AuthorizationManager: authz, denied: "System.Read"})
+			creds := credentials(server, "pass")
+			creds.Thumbprint = soap.ThumbprintSHA1(server.Certificate())
+
+			collector = services.NewCollectorService(sched, st, config.Agent{
+				DataFolder:       GinkgoT().TempDir(),
+				CollectorTimeout: time.Minute,
+			})
+			Expect(collector.Start(ctx, creds, false)).To(Succeed())
+
+			status := collector.GetStatus(ctx)
+			Expect(status.Preflight).NotTo(BeNil())
+			Expect(status.Preflight.Passed()).To(BeFalse())
+
+			var run models.CollectionRun
+			Eventually(func() models.CollectionRunState {
+				runs, _, err := st.CollectionRuns().List(ctx, 1, 0)
+				Expect(err).NotTo(HaveOccurred())
+				run = runs[0]
+				return run.State
+			}, "60s").ShouldNot(Equal(models.CollectionRunStateRunning))
+			Expect(run.Error).To(BeEmpty())
+			Expect(run.State).To(Equal(models.CollectionRunStateSucceeded))
+		})
+	})
+
+	Describe("incremental collection", func() {
+		var (
+			server      *simulator.Server
+			creds       *models.Credentials
+			cfg         config.Agent
+			incremental *services.CollectorService
+		)
+
+		BeforeEach(func() {
+			server = newSimulator(simulator.VPX(), true)
+			creds = credentials(server, "pass")
+			creds.Thumbprint = soap.ThumbprintSHA1(server.Certificate())
+
+			cfg = config.Agent{
+				DataFolder:            GinkgoT().TempDir(),
+				CollectorTimeout:      time.Minute,
+				IncrementalCollection: true,
+			}
+			incremental = services.NewCollectorService(sched, st, cfg)
+			// stops the collector kept in sync before the simulator is closed
+			DeferCleanup(func() { _ = incremental.Stop(ctx) })
+		})
+
+		// collect runs a collection to completion and returns its run.
+		collect := func(fullResync bool) models.CollectionRun {
+			Eventually(func() error {
+				return incremental.Start(ctx, creds, fullResync)
+			}).Should(Succeed())
+
+			var run models.CollectionRun
+			Eventually(func() models.CollectionRunState {
+				runs, _, err := st.CollectionRuns().List(ctx, 1, 0)
+				Expect(err).NotTo(HaveOccurred())
+				run = runs[0]
+				return run.State
+			}, "60s").ShouldNot(Equal(models.CollectionRunStateRunning))
+			Expect(run.Error).To(BeEmpty())
+			Expect(run.State).To(Equal(models.CollectionRunStateSucceeded))
+			return run
+		}
+
+		renameVM := func(name string) {
+			client, err := govmomi.NewClient(ctx, server.URL, true)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = client.Logout(ctx) }()
+
+			vm, err := find.NewFinder(client.Client).VirtualMachine(ctx, "DC0_H0_VM0")
+			Expect(err).NotTo(HaveOccurred())
+			task, err := vm.Rename(ctx, name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Wait(ctx)).To(Succeed())
+		}
+
+		snapshotVMs := func(run models.CollectionRun) string {
+			snapshot, err := incremental.GetInventorySnapshot(ctx, run.SnapshotID)
+			Expect(err).NotTo(HaveOccurred())
+			return string(snapshot.VMs)
+		}
+
+		It("should apply the vCenter changes to the next collection", func() {
+			first := collect(false)
+			Expect(first.Incremental).To(BeFalse())
+			Expect(first.VMs).To(BeNumerically(">", 0))
+			Expect(first.SnapshotID).NotTo(BeZero())
+
+			renameVM("renamed-vm")
+
+			Eventually(func() string {
+				second := collect(false)
+				Expect(second.Incremental).To(BeTrue())
+				Expect(second.VMs).To(Equal(first.VMs))
+				return snapshotVMs(second)
+			}, "30s").Should(ContainSubstring("renamed-vm"))
+		})
+
+		It("should collect from scratch with a full resync", func() {
+			collect(false)
+
+			Expect(collect(true).Incremental).To(BeFalse())
+			Expect(collect(false).Incremental).To(BeTrue())
+		})
+
+		It("should collect from scratch after a stop", func() {
+			collect(false)
+			Expect(incremental.Stop(ctx)).To(Succeed())
+
+			Expect(collect(false).Incremental).To(BeFalse())
+		})
+
+		// The collector kept in sync lives in the agent process: forklift starts from scratch with a new
+		// session to vCenter, so the first collection after a restart cannot be incremental.
+		It("should collect from scratch after an agent restart", func() {
+			collect(false)
+			Expect(incremental.Stop(ctx)).To(Succeed())
+
+			incremental = services.NewCollectorService(sched, st, cfg)
+
+			Expect(collect(false).Incremental).To(BeFalse())
+			Expect(collect(false).Incremental).To(BeTrue())
+		})
+	})
+
+	Describe("Verify", func() {
+		It("should report the privilege check without saving the credentials", func() {
+			server := newSimulator(simulator.VPX(), false)
+
+			preflight, err := collector.Verify(ctx, credentials(server, "pass"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preflight.Passed()).To(BeTrue())
+			Expect(preflight.CheckedAt).NotTo(BeZero())
+
+			status := collector.GetStatus(ctx)
+			Expect(status.HasCredentials).To(BeFalse())
+			Expect(status.State).To(Equal(models.CollectorStateReady))
+			Expect(status.Preflight).NotTo(BeNil())
+			Expect(status.Preflight.Passed()).To(BeTrue())
+		})
+
+		It("should return ErrInvalidCredentials for a wrong password", func() {
+			server := newSimulator(simulator.VPX(), false)
+
+			_, err := collector.Verify(ctx, credentials(server, "wrong"))
+			Expect(err).To(MatchError(services.ErrInvalidCredentials))
+			Expect(collector.GetStatus(ctx).Preflight).To(BeNil())
+		})
+	})
+	Describe("restore", func() {
+		// interruptRun records a run left running by a previous agent, as if it was killed while collecting.
+		interruptRun := func(trigger models.CollectionTrigger, url string) int64 {
+			id, err := st.CollectionRuns().Create(ctx, &models.CollectionRun{
+				Trigger:    trigger,
+				VCenterURL: url,
+				State:      models.CollectionRunStateRunning,
+				StartedAt:  time.Now(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.CollectorState().Save(ctx, models.CollectorStateCollecting, "")).To(Succeed())
+			return id
+		}
+
+		restart := func(resume bool) *services.CollectorService {
+			return services.NewCollectorService(sched, st, config.Agent{
+				DataFolder:       GinkgoT().TempDir(),
+				ResumeCollection: resume,
+			})
+		}
+
+		It("should persist the state and restore it", func() {
+			server := newSimulator(simulator.VPX(), false)
+			Expect(collector.Start(ctx, credentials(server, "wrong"), false)).NotTo(Succeed())
+
+			status := restart(false).GetStatus(ctx)
+			Expect(status.State).To(Equal(models.CollectorStateError))
+			Expect(status.Error).To(Equal(services.ErrInvalidCredentials.Error()))
+		})
+
+		It("should restore the cancelled state", func() {
+			Expect(st.CollectorState().Save(ctx, models.CollectorStateCancelled, "")).To(Succeed())
+
+			Expect(restart(false).GetStatus(ctx).State).To(Equal(models.CollectorStateCancelled))
+		})
+
+		It("should report an interrupted collection as failed", func() {
+			id := interruptRun(models.CollectionTriggerScheduled, "https://vcenter.example.com")
+
+			status := restart(false).GetStatus(ctx)
+			Expect(status.State).To(Equal(models.CollectorStateError))
+			Expect(status.Error).To(Equal(services.ErrCollectionInterrupted.Error()))
+
+			runs, _, err := st.CollectionRuns().List(ctx, 20, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runs).To(HaveLen(1))
+			Expect(runs[0].ID).To(Equal(id))
+			Expect(runs[0].State).To(Equal(models.CollectionRunStateFailed))
+			Expect(runs[0].Error).To(Equal(services.ErrCollectionInterrupted.Error()))
+		})
+
+		It("should resume an interrupted collection with the stored credentials", func() {
+			server := newSimulator(simulator.VPX(), false)
+			creds := credentials(server, "wrong")
+			Expect(st.Credentials().Save(ctx, creds)).To(Succeed())
+			interruptRun(models.CollectionTriggerScheduled, creds.URL)
+
+			restarted := restart(true)
+
+			// the resumed collection fails on the wrong password
+			Eventually(func() models.CollectorStatus {
+				return restarted.GetStatus(ctx)
+			}).Should(HaveField("Error", services.ErrInvalidCredentials.Error()))
+
+			runs, total, err := st.CollectionRuns().List(ctx, 20, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(2))
+			Expect(runs[0].Trigger).To(Equal(models.CollectionTriggerScheduled))
+			Expect(runs[0].State).To(Equal(models.CollectionRunStateFailed))
+			Expect(runs[1].Error).To(Equal(services.ErrCollectionInterrupted.Error()))
+		})
+	})
+})
+
+// denyingAuthorizationManager reports a privilege as not granted on every object, the simulator
+// granting all of them.
+type denyingAuthorizationManager struct {
+	*simulator.AuthorizationManager
+	denied string
+}
+
+func (m *denyingAuthorizationManager) HasPrivilegeOnEntities(req *types.HasPrivilegeOnEntities) soap.HasFault {
+	body := m.AuthorizationManager.HasPrivilegeOnEntities(req).(*methods.HasPrivilegeOnEntitiesBody)
+	for _, e := range body.Res.Returnval {
+		for i := range e.PrivAvailability {
+			if e.PrivAvailability[i].PrivId == m.denied {
+				e.PrivAvailability[i].IsGranted = false
+			}
+		}
+	}
+	return body
+}
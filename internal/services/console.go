@@ -2,10 +2,16 @@ package services
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"runtime/debug"
+	"slices"
 	"sync"
 	"time"
 
@@ -16,18 +22,21 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/config"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/backoff"
 	"github.com/kubev2v/assisted-migration-agent/pkg/console"
-	"github.com/kubev2v/assisted-migration-agent/pkg/errors"
+	agentErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 )
 
 type Collector interface {
 	Status() models.CollectorStatusType
+	// Inventory returns the latest inventory collected, store.ErrNotFound until one is collected
 	Inventory() (io.Reader, error)
 }
 
 type Console struct {
 	updateInterval    time.Duration
+	backoff           backoff.Policy
 	agentID           uuid.UUID
 	sourceID          uuid.UUID
 	version           string
@@ -35,10 +44,23 @@ type Console struct {
 	scheduler         *scheduler.Scheduler
 	mu                sync.Mutex
 	client            *console.Client
-	close             chan any
+	modeMu            sync.Mutex // serializes the mode changes, so a single run loop runs at a time
+	close             chan any   // closed to stop the run loop
+	done              chan any   // closed when the run loop returns, nil until a loop is started
 	collector         Collector
-	inventoryLastHash string // holds the hash of the last sent inventory
-	store             *store.Store
+	inventoryLastHash string // holds the hash of the last delivered inventory, guarded by mu
+	// inventoryPendingHash holds the hash of the inventory sent without success yet
+	inventoryPendingHash string
+	maxInventoryRetries  int
+	// dataSharingAllowed mirrors the consent saved in the agent settings, the inventory is uploaded only with it
+	dataSharingAllowed bool
+	// inventoryUpload is the inventory upload in flight, stopped when the consent is revoked
+	inventoryUpload *models.Future[models.Result[any]]
+	// anonymizedFields is nil unless the inventory is anonymized before being sent
+	anonymizedFields []AnonymizedField
+	// anonymizer is created with the stored salt on first use
+	anonymizer *InventoryAnonymizer
+	store      *store.Store
 }
 
 func NewConsoleService(cfg config.Agent, s *scheduler.Scheduler, client *console.Client, collector Collector, st *store.Store) *Console {
@@ -52,62 +74,235 @@ func NewConsoleService(cfg config.Agent, s *scheduler.Scheduler, client *console
 		Target:  targetStatus,
 	}
 
-	creds, err := st.Credentials().Get(context.Background())
-	if err == nil && creds.IsDataSharingAllowed {
-		defaultStatus.Target = models.ConsoleStatusConnected
+	settings, err := st.AgentSettings().Get(context.Background())
+	switch {
+	case err == nil && settings.Mode != "":
+		// the mode chosen through the API prevails over the configured one
+		if target, err := models.ParseConsoleStatusType(string(settings.Mode)); err == nil {
+			defaultStatus.Target = target
+		}
+	case err != nil && !errors.Is(err, store.ErrNotFound):
+		zap.S().Warnw("failed to get agent settings", "error", err)
 	}
 	c := newConsoleService(cfg, s, client, collector, st, defaultStatus)
+	c.dataSharingAllowed = err == nil && settings.DataSharingAllowed
 
 	if defaultStatus.Target == models.ConsoleStatusConnected {
-		go c.run()
+		c.mu.Lock()
+		c.start()
+		c.mu.Unlock()
 	}
 
 	return c
 }
 
-func newConsoleService(cfg config.Agent, s *scheduler.Scheduler, client *console.Client, collector Collector, store *store.Store, defaultStatus models.ConsoleStatus) *Console {
+func newConsoleService(cfg config.Agent, s *scheduler.Scheduler, client *console.Client, collector Collector, st *store.Store, defaultStatus models.ConsoleStatus) *Console {
 	c := &Console{
-		updateInterval: cfg.UpdateInterval,
-		agentID:        uuid.MustParse(cfg.ID),
-		sourceID:       uuid.MustParse(cfg.SourceID),
-		version:        cfg.Version,
-		scheduler:      s,
-		status:         defaultStatus,
-		client:         client,
-		close:          make(chan any),
-		store:          store,
-		collector:      collector,
+		updateInterval:      cfg.UpdateInterval,
+		agentID:             uuid.MustParse(cfg.ID),
+		sourceID:            uuid.MustParse(cfg.SourceID),
+		version:             cfg.Version,
+		scheduler:           s,
+		status:              defaultStatus,
+		client:              client,
+		close:               make(chan any),
+		store:               st,
+		collector:           collector,
+		maxInventoryRetries: cfg.InventoryMaxRetries,
+		backoff: backoff.Policy{
+			Initial:    cfg.BackoffInitial,
+			Max:        cfg.BackoffMax,
+			Multiplier: cfg.BackoffMultiplier,
+			Jitter:     cfg.BackoffJitter,
+		},
+	}
+
+	if cfg.AnonymizeInventory {
+		fields, err := ParseAnonymizedFields(cfg.AnonymizeFields)
+		if err != nil {
+			// the agent rejects the invalid fields with its flags, the identifying fields are never sent anyway
+			fields = AnonymizedFields
+		}
+		c.anonymizedFields = fields
+	}
+
+	upload, err := st.InventoryUploads().Get(context.Background(), c.sourceID.String())
+	switch {
+	case err == nil:
+		c.inventoryLastHash = upload.DeliveredHash
+		c.inventoryPendingHash = upload.PendingHash
+		if upload.Pending() {
+			zap.S().Infow("inventory upload pending since the previous run", "hash", upload.PendingHash, "since", upload.PendingSince, "attempts", upload.Attempts)
+		}
+	case !errors.Is(err, store.ErrNotFound):
+		zap.S().Warnw("failed to get inventory upload", "error", err)
 	}
+
 	return c
 }
 
 // IsDataSharingAllowed checks if the user has allowed data sharing.
 func (c *Console) IsDataSharingAllowed(ctx context.Context) (bool, error) {
-	creds, err := c.store.Credentials().Get(ctx)
+	settings, err := c.store.AgentSettings().Get(ctx)
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
 	if err != nil {
 		return false, err
 	}
-	return creds.IsDataSharingAllowed, nil
+	return settings.DataSharingAllowed, nil
+}
+
+// Consent returns the agent settings holding the data-sharing consent, with no consent when nothing was set.
+func (c *Console) Consent(ctx context.Context) (*models.AgentSettings, error) {
+	settings, err := c.store.AgentSettings().Get(ctx)
+	if errors.Is(err, store.ErrNotFound) {
+		return &models.AgentSettings{}, nil
+	}
+	return settings, err
+}
+
+// SetConsent persists the data-sharing consent given by setBy for the given version of the terms.
+// Without consent the inventory is not uploaded, even in connected mode, and revoking the consent
+// stops the inventory upload in flight. The status updates are sent regardless of the consent.
+func (c *Console) SetConsent(ctx context.Context, allowed bool, termsVersion, setBy string) (*models.AgentSettings, error) {
+	if err := c.store.AgentSettings().SaveConsent(ctx, allowed, termsVersion, setBy); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.dataSharingAllowed != allowed {
+		zap.S().Infow("data-sharing consent changed", "allowed", allowed, "terms_version", termsVersion, "set_by", setBy)
+	}
+	c.dataSharingAllowed = allowed
+	if !allowed && c.inventoryUpload != nil {
+		c.inventoryUpload.Stop()
+		c.inventoryUpload = nil
+	}
+	c.mu.Unlock()
+
+	return c.Consent(ctx)
+}
+
+// SaveMode persists the mode chosen by setBy, so it is restored when the agent starts, and switches to it.
+// The inventory is uploaded in connected mode only once the data-sharing consent is given with SetConsent.
+func (c *Console) SaveMode(ctx context.Context, mode models.AgentMode, setBy string) error {
+	if err := c.store.AgentSettings().SaveMode(ctx, mode, setBy); err != nil {
+		return err
+	}
+
+	c.SetMode(mode)
+	return nil
+}
+
+// consoleTransitions lists the allowed transitions of the console connection status:
+//
+//	disconnected → connecting: the run loop starts
+//	connecting → connected | error: outcome of the first console call
+//	connected ⇄ error: outcome of the most recent console call
+//	error → connecting: the run loop restarts after console rejected the agent
+//	any → disconnected: the run loop stops
+var consoleTransitions = map[models.ConsoleStatusType][]models.ConsoleStatusType{
+	models.ConsoleStatusDisconnected: {models.ConsoleStatusConnecting},
+	models.ConsoleStatusConnecting:   {models.ConsoleStatusConnected, models.ConsoleStatusError, models.ConsoleStatusDisconnected},
+	models.ConsoleStatusConnected:    {models.ConsoleStatusError, models.ConsoleStatusDisconnected},
+	models.ConsoleStatusError:        {models.ConsoleStatusConnected, models.ConsoleStatusConnecting, models.ConsoleStatusDisconnected},
 }
 
+// SetMode starts the run loop in connected mode and stops it in disconnected mode.
+// It does nothing when the run loop is already in the requested mode, except switching to connected mode
+// restarts the loop stopped after console rejected the agent, and switching to disconnected mode clears the
+// reason it was stopped for.
 func (c *Console) SetMode(mode models.AgentMode) {
+	c.modeMu.Lock()
+	defer c.modeMu.Unlock()
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
 
 	zap.S().Debugw("setting agent mode", "targetMode", mode, "currentTarget", c.status.Target)
 
+	var done chan any
 	switch mode {
 	case models.AgentModeConnected:
 		c.status.Target = models.ConsoleStatusConnected
-		zap.S().Debugw("starting run loop for connected mode")
-		go c.run()
+		c.start()
 	case models.AgentModeDisconnected:
-		if c.status.Target == models.ConsoleStatusConnected {
+		c.status.Target = models.ConsoleStatusDisconnected
+		if c.running() {
 			zap.S().Debugw("stopping run loop for disconnected mode")
-			c.close <- struct{}{}
+			close(c.close)
+			done = c.done
+		} else {
+			c.disconnect()
 		}
-		c.status.Target = models.ConsoleStatusDisconnected
 	}
+	c.mu.Unlock()
+
+	// the run loop may need the lock to record errors, so wait for it without holding it
+	if done != nil {
+		<-done
+	}
+}
+
+// start starts the run loop unless it is running. It must be called with the lock held.
+func (c *Console) start() {
+	if c.running() {
+		return
+	}
+
+	if c.status.StopReason != "" {
+		zap.S().Infow("restarting run loop stopped by console", "reason", c.status.StopReason)
+		c.status.StopReason = ""
+		c.status.Error = nil
+	}
+
+	zap.S().Debugw("starting run loop for connected mode")
+	c.transition(models.ConsoleStatusConnecting)
+	c.close = make(chan any)
+	c.done = make(chan any)
+	go c.supervise(c.close, c.done)
+}
+
+// disconnect records the disconnected mode while the run loop is not running, which happens after console
+// rejected the agent. The stop reason is cleared as the agent no longer tries to reach console.
+// It must be called with the lock held.
+func (c *Console) disconnect() {
+	if c.status.StopReason != "" {
+		zap.S().Infow("clearing the stop reason of the run loop stopped by console", "reason", c.status.StopReason)
+		c.status.StopReason = ""
+		c.status.Error = nil
+	}
+	c.transition(models.ConsoleStatusDisconnected)
+}
+
+// running reports whether the run loop is running and not being stopped. It must be called with the lock held.
+func (c *Console) running() bool {
+	if c.done == nil {
+		return false
+	}
+	select {
+	case <-c.close:
+		return false
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// transition sets the console connection status. It must be called with the lock held.
+func (c *Console) transition(to models.ConsoleStatusType) {
+	from := c.status.Current
+	if from == to {
+		return
+	}
+	if !slices.Contains(consoleTransitions[from], to) {
+		zap.S().Warnw("invalid console status transition", "from", from, "to", to)
+		return
+	}
+
+	zap.S().Infow("console status changed", "from", from, "to", to)
+	c.status.Current = to
 }
 
 func (c *Console) Status() models.ConsoleStatus {
@@ -116,35 +311,105 @@ func (c *Console) Status() models.ConsoleStatus {
 	return c.status
 }
 
+// AgentStatus returns the console status along with the agent identity and the collector status.
+func (c *Console) AgentStatus() models.AgentStatus {
+	return models.AgentStatus{
+		ID:        c.agentID.String(),
+		SourceID:  c.sourceID.String(),
+		Version:   c.version,
+		Console:   c.Status(),
+		Collector: c.collector.Status(),
+	}
+}
+
+// supervise runs the run loop until it is stopped or console rejects the agent.
+// The run loop is restarted if it panics.
+func (c *Console) supervise(stop <-chan any, done chan<- any) {
+	defer func() {
+		select {
+		case <-stop:
+			c.mu.Lock()
+			c.transition(models.ConsoleStatusDisconnected)
+			c.mu.Unlock()
+		default:
+		}
+		close(done)
+	}()
+
+	for c.runRecovered(stop) {
+		select {
+		case <-stop:
+			return
+		case <-time.After(c.updateInterval):
+		}
+	}
+}
+
+// runRecovered runs the run loop and reports whether it panicked.
+func (c *Console) runRecovered(stop <-chan any) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.S().Errorw("run loop panicked, restarting it", "panic", r, "stack", string(debug.Stack()))
+			c.setError(fmt.Errorf("run loop panicked: %v", r))
+			panicked = true
+		}
+	}()
+
+	c.run(stop)
+	return false
+}
+
 // run is the main loop that sends status and inventory updates to the console.
 //
 // On each tick (heartbeat):
-//  1. Check if statusFuture is resolved. If yes, handle errors (fatal errors stop the loop),
-//     then dispatch a new status update.
-//  2. If collector status is not "collected", skip inventory processing.
-//  3. If inventoryFuture is still pending, skip (don't send new inventory until previous completes).
-//  4. If inventoryFuture resolved, handle any errors.
-//  5. If inventory changed since last send (hash comparison), dispatch new inventory update.
+//  1. Check if statusFuture is resolved. If yes, handle errors (fatal errors stop the loop).
+//  2. Dispatch a new status update unless the status stream is backing off.
+//  3. If the data-sharing consent is not given, drop the pending inventory and skip inventory processing.
+//  4. If collector status is not "collected", skip inventory processing.
+//  5. If inventoryFuture is still pending, skip (don't send new inventory until previous completes).
+//  6. If inventoryFuture resolved, handle any errors. The hash of the inventory is committed only on success.
+//  7. If the inventory stream is not backing off and the inventory changed since last delivery
+//     (hash comparison), dispatch new inventory update.
 //
 // Fatal errors (stop the loop, no retry):
 //   - SourceGoneError (410): The source was deleted from the console. No point in sending updates.
 //   - AgentUnauthorizedError (401): Invalid or expired JWT. Agent cannot authenticate.
 //
+// After a fatal error, the status is error with the stop reason until SetMode restarts the loop.
+//
+// The status is connected or error depending on the outcome of the most recent status or inventory update.
 // Transient errors are logged and stored in status.Error, but the loop continues.
-func (c *Console) run() {
+// The status and inventory streams back off independently after consecutive transient errors,
+// and the backoff of a stream is reset by its next success.
+//
+// The inventory waiting to be delivered is persisted, so it is sent again after a restart.
+// After InventoryMaxRetries failed retries, the inventory is sent again only once it changes
+// or once the status updates succeed again after failing.
+//
+// The inventory is never uploaded without the data-sharing consent. Revoking the consent stops the upload
+// in flight and drops the pending inventory, which is sent again only once the consent is given again.
+func (c *Console) run(stop <-chan any) {
 	tick := time.NewTicker(c.updateInterval)
 	defer func() {
 		tick.Stop()
 		zap.S().Debugw("run loop stopped")
 	}()
 
-	var inventoryFuture *models.Future[models.Result[any]]
+	statusBackoff := backoff.New(c.backoff)
+	inventoryBackoff := backoff.New(c.backoff)
+	c.setBackoff(statusBackoff, inventoryBackoff)
+
+	var (
+		inventoryFuture *models.Future[models.Result[any]]
+		// retriesExhausted is set when the pending inventory failed more than the max retries
+		retriesExhausted bool
+	)
 	statusFuture := c.dispatchStatus()
 
 	for {
 		select {
 		case <-tick.C:
-		case <-c.close:
+		case <-stop:
 			zap.S().Debugw("close signal received, exiting run loop")
 			return
 		}
@@ -153,22 +418,38 @@ func (c *Console) run() {
 			result := statusFuture.Result()
 			zap.S().Debugw("status update completed", "error", result.Err)
 			if result.Err != nil {
-				switch result.Err.(type) {
-				case *errors.SourceGoneError:
-					zap.S().Info("source is gone..stop sending requests")
+				if c.stopOnFatal(result.Err) {
 					return
-				case *errors.AgentUnauthorizedError:
-					zap.S().Info("agent not authenticated..stop sending requests")
-					return
-				default:
-					zap.S().Errorw("failed to send status to console", "error", result.Err)
 				}
-				c.status.Error = result.Err
+				delay := statusBackoff.Failure()
+				zap.S().Errorw("failed to send status to console", "error", result.Err, "failures", statusBackoff.Failures(), "retry_in", delay)
+				c.setError(result.Err)
+			} else {
+				if statusBackoff.Failures() > 0 && retriesExhausted {
+					zap.S().Infow("console recovered, retrying pending inventory", "hash", c.inventoryPendingHash)
+					retriesExhausted = false
+					inventoryBackoff.Success()
+				}
+				statusBackoff.Success()
+				c.setConnected()
 			}
+			c.setBackoff(statusBackoff, inventoryBackoff)
+			statusFuture = nil
+		}
+
+		if statusFuture == nil && statusBackoff.Ready() {
 			statusFuture = c.dispatchStatus()
 		}
 
-		if c.collector.Status() != models.CollectorStatusCollected {
+		if !c.isDataSharingAllowed() {
+			if inventoryFuture != nil || c.inventoryPendingHash != "" {
+				zap.S().Infow("data-sharing consent revoked, dropping pending inventory", "hash", c.inventoryPendingHash)
+				inventoryFuture = nil
+				retriesExhausted = false
+				inventoryBackoff.Success()
+				c.setBackoff(statusBackoff, inventoryBackoff)
+				c.clearInventoryPending()
+			}
 			continue
 		}
 
@@ -178,47 +459,348 @@ func (c *Console) run() {
 			}
 			result := inventoryFuture.Result()
 			if result.Err != nil {
-				zap.S().Errorw("failed to send inventory to console", "error", result.Err)
-				c.status.Error = result.Err
+				if c.stopOnFatal(result.Err) {
+					return
+				}
+				delay := inventoryBackoff.Failure()
+				c.setError(result.Err)
+				c.recordInventoryFailure(result.Err)
+				if c.maxInventoryRetries > 0 && inventoryBackoff.Failures() > c.maxInventoryRetries {
+					zap.S().Errorw("failed to send inventory to console, giving up until it changes or console recovers", "error", result.Err, "failures", inventoryBackoff.Failures())
+					retriesExhausted = true
+				} else {
+					zap.S().Errorw("failed to send inventory to console", "error", result.Err, "failures", inventoryBackoff.Failures(), "retry_in", delay)
+				}
+			} else {
+				inventoryBackoff.Success()
+				c.setConnected()
+				c.markInventoryDelivered()
 			}
+			c.setBackoff(statusBackoff, inventoryBackoff)
+			inventoryFuture = nil
+		}
+
+		if !inventoryBackoff.Ready() {
+			continue
 		}
 
-		if inventory, changed := c.getInventoryIfChanged(); changed {
-			inventoryFuture = c.dispatchInventory(inventory)
+		inventory, hash, withheld, err := c.nextInventory(context.Background())
+		if err != nil {
+			zap.S().Errorw("failed to get inventory", "error", err)
+			continue
 		}
+		if withheld != "" {
+			continue
+		}
+
+		if retriesExhausted {
+			if hash == c.inventoryPendingHash {
+				continue
+			}
+			retriesExhausted = false
+			inventoryBackoff.Success()
+			c.setBackoff(statusBackoff, inventoryBackoff)
+		}
+
+		if hash != c.inventoryPendingHash {
+			c.markInventoryPending(hash)
+		}
+		inventoryFuture = c.dispatchInventory(inventory)
 	}
 }
 
+// stopOnFatal records the stop reason if the error returned by console stops the run loop.
+func (c *Console) stopOnFatal(err error) bool {
+	var reason models.ConsoleStopReason
+	switch err.(type) {
+	case *agentErrors.SourceGoneError:
+		zap.S().Info("source is gone..stop sending requests")
+		reason = models.ConsoleStopReasonSourceGone
+	case *agentErrors.AgentUnauthorizedError:
+		zap.S().Info("agent not authenticated..stop sending requests")
+		reason = models.ConsoleStopReasonUnauthorized
+	default:
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transition(models.ConsoleStatusError)
+	c.status.StopReason = reason
+	c.status.Error = err
+	return true
+}
+
 func (c *Console) dispatchStatus() *models.Future[models.Result[any]] {
 	return c.scheduler.AddWork(func(ctx context.Context) (any, error) {
-		return struct{}{}, c.client.UpdateAgentStatus(ctx, c.agentID, c.sourceID, c.version, c.collector.Status())
+		if err := c.client.UpdateAgentStatus(ctx, c.agentID, c.sourceID, c.version, c.collector.Status()); err != nil {
+			return struct{}{}, err
+		}
+
+		c.mu.Lock()
+		c.status.LastStatusUpdate = time.Now()
+		c.mu.Unlock()
+
+		return struct{}{}, nil
 	})
 }
 
+// dispatchInventory starts the upload of the inventory unless the consent was revoked meanwhile.
+// The upload is recorded under the lock, so revoking the consent stops it.
 func (c *Console) dispatchInventory(inventory []byte) *models.Future[models.Result[any]] {
-	return c.scheduler.AddWork(func(ctx context.Context) (any, error) {
-		return struct{}{}, c.client.UpdateSourceStatus(ctx, c.sourceID, bytes.NewReader(inventory))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dataSharingAllowed {
+		return nil
+	}
+
+	c.inventoryUpload = c.scheduler.AddWork(func(ctx context.Context) (any, error) {
+		if err := c.client.UpdateSourceStatus(ctx, c.sourceID, bytes.NewReader(inventory)); err != nil {
+			return struct{}{}, err
+		}
+
+		c.mu.Lock()
+		c.status.LastInventoryUpdate = time.Now()
+		c.mu.Unlock()
+
+		return struct{}{}, nil
 	})
+	return c.inventoryUpload
+}
+
+// isDataSharingAllowed reports whether the inventory may be uploaded.
+func (c *Console) isDataSharingAllowed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dataSharingAllowed
+}
+
+// setConnected records the success of a console call.
+func (c *Console) setConnected() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transition(models.ConsoleStatusConnected)
+}
+
+// setError records the failure of a console call.
+func (c *Console) setError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.Error = err
+	c.transition(models.ConsoleStatusError)
+}
+
+// setBackoff reports the backoff of the status and inventory streams in the status.
+func (c *Console) setBackoff(statusBackoff, inventoryBackoff *backoff.Backoff) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.StatusBackoff = backoffStatus(statusBackoff)
+	c.status.InventoryBackoff = backoffStatus(inventoryBackoff)
 }
 
-func (c *Console) getInventoryIfChanged() ([]byte, bool) {
+func backoffStatus(b *backoff.Backoff) models.BackoffStatus {
+	return models.BackoffStatus{
+		ConsecutiveFailures: b.Failures(),
+		Delay:               b.Delay(),
+		NextAttempt:         b.NextAttempt(),
+	}
+}
+
+// InventoryPreview returns the inventory exactly as it would be sent to console,
+// store.ErrNotFound until an inventory is collected.
+func (c *Console) InventoryPreview(ctx context.Context) ([]byte, error) {
+	return c.outgoingInventory(ctx)
+}
+
+// nextInventory returns the inventory sent next to console with its hash, or the reason it is withheld:
+// without consent, until an inventory is collected, and while the latest inventory is the delivered one.
+// The hash is the one of the inventory sent, so changing the anonymized fields sends the inventory again.
+// The run loop and the outbox both rely on it, so the outbox shows what the run loop sends.
+func (c *Console) nextInventory(ctx context.Context) ([]byte, string, models.InventoryWithheldReason, error) {
+	if !c.isDataSharingAllowed() {
+		return nil, "", models.InventoryWithheldNoConsent, nil
+	}
+
+	inventory, err := c.outgoingInventory(ctx)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return nil, "", models.InventoryWithheldNotCollected, nil
+	case err != nil:
+		return nil, "", "", err
+	}
+
+	hash := inventoryHash(inventory)
+	c.mu.Lock()
+	delivered := c.inventoryLastHash
+	c.mu.Unlock()
+	if hash == delivered {
+		return nil, "", models.InventoryWithheldUnchanged, nil
+	}
+
+	return inventory, hash, "", nil
+}
+
+// Outbox returns the requests the run loop would send next to console, built by the client without being sent.
+func (c *Console) Outbox(ctx context.Context) (*models.Outbox, error) {
+	statusReq, err := c.client.NewAgentStatusRequest(ctx, c.agentID, c.sourceID, c.version, c.collector.Status())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build status request: %w", err)
+	}
+	status, err := outboxRequest(statusReq)
+	if err != nil {
+		return nil, err
+	}
+	outbox := &models.Outbox{Status: *status}
+
+	upload, err := c.store.InventoryUploads().Get(ctx, c.sourceID.String())
+	switch {
+	case err == nil:
+		outbox.LastUploadHash = upload.DeliveredHash
+		outbox.LastUploadAt = upload.DeliveredAt
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, err
+	}
+
+	inventory, hash, withheld, err := c.nextInventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if withheld != "" {
+		outbox.InventoryWithheld = withheld
+		return outbox, nil
+	}
+
+	inventoryReq, err := c.client.NewSourceStatusRequest(ctx, c.sourceID, inventory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build inventory request: %w", err)
+	}
+	outbox.Inventory, err = outboxRequest(inventoryReq)
+	if err != nil {
+		return nil, err
+	}
+	outbox.InventoryHash = hash
+
+	return outbox, nil
+}
+
+// outboxRequest reads the request built by the client, with its body uncompressed.
+func outboxRequest(req *http.Request) (*models.OutboxRequest, error) {
+	defer req.Body.Close()
+
+	var body io.Reader = req.Body
+	compressed := req.Header.Get("Content-Encoding") == "gzip"
+	if compressed {
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress request body: %w", err)
+		}
+		body = gz
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	return &models.OutboxRequest{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Body:       data,
+		Compressed: compressed,
+	}, nil
+}
+
+func inventoryHash(inventory []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(inventory))
+}
+
+// outgoingInventory returns the inventory of the collector, anonymized when configured.
+// The inventory is never returned unanonymized when the anonymization fails.
+func (c *Console) outgoingInventory(ctx context.Context) ([]byte, error) {
 	reader, err := c.collector.Inventory()
 	if err != nil {
-		zap.S().Errorw("failed to get inventory", "error", err)
-		return nil, false
+		return nil, err
 	}
 
 	inventory, err := io.ReadAll(reader)
 	if err != nil {
-		zap.S().Errorw("failed to read inventory", "error", err)
-		return nil, false
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	if c.anonymizedFields == nil {
+		return inventory, nil
 	}
 
-	hash := fmt.Sprintf("%x", sha256.Sum256(inventory))
-	if hash == c.inventoryLastHash {
-		return nil, false
+	anonymizer, err := c.inventoryAnonymizer(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return anonymizer.Anonymize(inventory)
+}
 
-	c.inventoryLastHash = hash
-	return inventory, true
+// inventoryAnonymizer returns the anonymizer, with the salt stored on the first anonymization
+// so the hashes are the same across restarts.
+func (c *Console) inventoryAnonymizer(ctx context.Context) (*InventoryAnonymizer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.anonymizer != nil {
+		return c.anonymizer, nil
+	}
+
+	salt, err := c.store.Anonymization().GetSalt(ctx)
+	if errors.Is(err, store.ErrNotFound) {
+		salt = make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate anonymization salt: %w", err)
+		}
+		if err := c.store.Anonymization().CreateSalt(ctx, salt); err != nil {
+			return nil, fmt.Errorf("failed to save anonymization salt: %w", err)
+		}
+		zap.S().Info("anonymization salt created")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get anonymization salt: %w", err)
+	}
+
+	c.anonymizer = NewInventoryAnonymizer(salt, c.anonymizedFields)
+	return c.anonymizer, nil
+}
+
+// markInventoryPending records the inventory about to be sent as waiting to be delivered.
+func (c *Console) markInventoryPending(hash string) {
+	c.inventoryPendingHash = hash
+	if err := c.store.InventoryUploads().MarkPending(context.Background(), c.sourceID.String(), hash); err != nil {
+		zap.S().Warnw("failed to save pending inventory upload", "error", err)
+	}
+}
+
+// recordInventoryFailure records a failed attempt to deliver the pending inventory.
+func (c *Console) recordInventoryFailure(uploadErr error) {
+	if err := c.store.InventoryUploads().RecordFailure(context.Background(), c.sourceID.String(), uploadErr.Error()); err != nil {
+		zap.S().Warnw("failed to save inventory upload failure", "error", err)
+	}
+}
+
+// clearInventoryPending forgets the inventory waiting to be delivered.
+func (c *Console) clearInventoryPending() {
+	c.inventoryPendingHash = ""
+	if err := c.store.InventoryUploads().ClearPending(context.Background(), c.sourceID.String()); err != nil {
+		zap.S().Warnw("failed to clear pending inventory upload", "error", err)
+	}
+}
+
+// markInventoryDelivered commits the hash of the pending inventory once console accepted it.
+func (c *Console) markInventoryDelivered() {
+	delivered := c.inventoryPendingHash
+	c.inventoryPendingHash = ""
+
+	c.mu.Lock()
+	c.inventoryLastHash = delivered
+	deliveredAt := c.status.LastInventoryUpdate
+	c.mu.Unlock()
+
+	if err := c.store.InventoryUploads().MarkDelivered(context.Background(), c.sourceID.String(), delivered, deliveredAt); err != nil {
+		zap.S().Warnw("failed to save inventory upload", "error", err)
+	}
 }
@@ -2,16 +2,24 @@ package services_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
 	"database/sql"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/soap"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/config"
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
@@ -19,38 +27,61 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
 	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
 	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
 )
 
 // MockCollector implements Collector interface for testing
 type MockCollector struct {
-	status    models.CollectorStatusType
+	mu     sync.Mutex
+	status models.CollectorStatusType
+	// inventory is nil until an inventory is collected
 	inventory []byte
 	err       error
+	// panics is the number of calls to Inventory panicking
+	panics atomic.Int32
 }
 
 func NewMockCollector(status models.CollectorStatusType) *MockCollector {
 	return &MockCollector{
-		status:    status,
-		inventory: []byte("{}"),
+		status: status,
 	}
 }
 
 func (m *MockCollector) Status() models.CollectorStatusType {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return m.status
 }
 
 func (m *MockCollector) Inventory() (io.Reader, error) {
+	if m.panics.Add(-1) >= 0 {
+		panic("inventory failed")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.err != nil {
 		return nil, m.err
 	}
+	if m.inventory == nil {
+		return nil, store.ErrNotFound
+	}
 	return strings.NewReader(string(m.inventory)), nil
 }
 
 func (m *MockCollector) SetStatus(status models.CollectorStatusType) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.status = status
 }
 
+// SetInventory replaces the collected inventory while the console service runs.
+func (m *MockCollector) SetInventory(inventory []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inventory = inventory
+}
+
 var _ = Describe("Console Service", func() {
 	var (
 		sched     *scheduler.Scheduler
@@ -76,7 +107,10 @@ var _ = Describe("Console Service", func() {
 		err = migrations.Run(context.Background(), db)
 		Expect(err).NotTo(HaveOccurred())
 
-		st = store.NewStore(db)
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		st = store.NewStore(db, encryption.NewKeyring(key))
 
 		cfg = config.Agent{
 			ID:             agentID,
@@ -132,19 +166,15 @@ var _ = Describe("Console Service", func() {
 		})
 	})
 
-	Describe("NewConsoleService with data sharing allowed in DB", func() {
-		It("should create a console service with connected target status when data sharing is allowed", func() {
-			// Save credentials with data sharing allowed before creating service
-			creds := &models.Credentials{
-				URL:                  "https://vcenter.example.com",
-				Username:             "admin",
-				Password:             "secret",
-				IsDataSharingAllowed: true,
-			}
-			err := st.Credentials().Save(context.Background(), creds)
+	Describe("NewConsoleService with agent settings in DB", func() {
+		It("should keep the configured mode when data sharing is allowed", func() {
+			// Save data sharing consent before creating service
+			err := st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")
 			Expect(err).NotTo(HaveOccurred())
 
+			requestReceived := make(chan bool, 10)
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestReceived <- true
 				w.WriteHeader(http.StatusOK)
 			}))
 			defer server.Close()
@@ -152,23 +182,18 @@ var _ = Describe("Console Service", func() {
 			client, err := console.NewConsoleClient(server.URL, "")
 			Expect(err).NotTo(HaveOccurred())
 
+			cfg.Mode = "disconnected"
 			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
 			Expect(consoleSrv).NotTo(BeNil())
 
 			status := consoleSrv.Status()
 			Expect(status.Current).To(Equal(models.ConsoleStatusDisconnected))
-			Expect(status.Target).To(Equal(models.ConsoleStatusConnected))
+			Expect(status.Target).To(Equal(models.ConsoleStatusDisconnected))
+			Consistently(requestReceived, 150*time.Millisecond).ShouldNot(Receive())
 		})
 
-		It("should start sending status updates when data sharing is allowed", func() {
-			// Save credentials with data sharing allowed before creating service
-			creds := &models.Credentials{
-				URL:                  "https://vcenter.example.com",
-				Username:             "admin",
-				Password:             "secret",
-				IsDataSharingAllowed: true,
-			}
-			err := st.Credentials().Save(context.Background(), creds)
+		It("should start sending status updates in the saved connected mode", func() {
+			err := st.AgentSettings().SaveMode(context.Background(), models.AgentModeConnected, "test")
 			Expect(err).NotTo(HaveOccurred())
 
 			requestReceived := make(chan bool, 10)
@@ -181,20 +206,15 @@ var _ = Describe("Console Service", func() {
 			client, err := console.NewConsoleClient(server.URL, "")
 			Expect(err).NotTo(HaveOccurred())
 
-			_ = services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(consoleSrv.Status().Target).To(Equal(models.ConsoleStatusConnected))
 
 			Eventually(requestReceived, 500*time.Millisecond).Should(Receive())
 		})
 
 		It("should remain disconnected when data sharing is not allowed", func() {
-			// Save credentials with data sharing NOT allowed
-			creds := &models.Credentials{
-				URL:                  "https://vcenter.example.com",
-				Username:             "admin",
-				Password:             "secret",
-				IsDataSharingAllowed: false,
-			}
-			err := st.Credentials().Save(context.Background(), creds)
+			// Save data sharing NOT allowed
+			err := st.AgentSettings().SaveConsent(context.Background(), false, "", "test")
 			Expect(err).NotTo(HaveOccurred())
 
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -214,6 +234,57 @@ var _ = Describe("Console Service", func() {
 		})
 	})
 
+	Describe("SaveMode", func() {
+		var client *console.Client
+
+		BeforeEach(func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			DeferCleanup(server.Close)
+
+			var err error
+			client, err = console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should restore the saved mode instead of the configured one", func() {
+			cfg.Mode = "connected"
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(consoleSrv.SaveMode(context.Background(), models.AgentModeDisconnected, "test")).To(Succeed())
+			Expect(consoleSrv.Status().Target).To(Equal(models.ConsoleStatusDisconnected))
+
+			restarted := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(restarted.Status().Target).To(Equal(models.ConsoleStatusDisconnected))
+		})
+
+		It("should not record the consent when switching to connected mode", func() {
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			Expect(consoleSrv.SaveMode(context.Background(), models.AgentModeConnected, "test")).To(Succeed())
+			Expect(consoleSrv.Status().Target).To(Equal(models.ConsoleStatusConnected))
+
+			allowed, err := consoleSrv.IsDataSharingAllowed(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeFalse())
+
+			settings, err := st.AgentSettings().Get(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(settings.Mode).To(Equal(models.AgentModeConnected))
+			Expect(settings.ModeSetBy).To(Equal("test"))
+		})
+
+		It("should keep the consent when switching to disconnected mode", func() {
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			_, err := consoleSrv.SetConsent(context.Background(), true, "v1", "test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(consoleSrv.SaveMode(context.Background(), models.AgentModeDisconnected, "test")).To(Succeed())
+
+			allowed, err := consoleSrv.IsDataSharingAllowed(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeTrue())
+		})
+	})
+
 	Describe("Connected mode via SetMode", func() {
 		It("should switch to connected target status via SetMode", func() {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -230,8 +301,12 @@ var _ = Describe("Console Service", func() {
 			consoleSrv.SetMode(models.AgentModeConnected)
 
 			status := consoleSrv.Status()
-			Expect(status.Current).To(Equal(models.ConsoleStatusDisconnected))
+			Expect(status.Current).To(Equal(models.ConsoleStatusConnecting))
 			Expect(status.Target).To(Equal(models.ConsoleStatusConnected))
+
+			Eventually(func() models.ConsoleStatusType {
+				return consoleSrv.Status().Current
+			}, 500*time.Millisecond).Should(Equal(models.ConsoleStatusConnected))
 		})
 
 		It("should start sending status updates when switched to connected mode", func() {
@@ -304,6 +379,63 @@ var _ = Describe("Console Service", func() {
 			Consistently(requestReceived, 150*time.Millisecond).ShouldNot(Receive())
 		})
 
+		It("should report the outcome of the most recent console call", func() {
+			var failing atomic.Bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if failing.Load() {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			current := func() models.ConsoleStatusType {
+				return consoleSrv.Status().Current
+			}
+			Eventually(current, 500*time.Millisecond).Should(Equal(models.ConsoleStatusConnected))
+
+			failing.Store(true)
+			Eventually(current, 500*time.Millisecond).Should(Equal(models.ConsoleStatusError))
+
+			failing.Store(false)
+			Eventually(current, 500*time.Millisecond).Should(Equal(models.ConsoleStatusConnected))
+
+			consoleSrv.SetMode(models.AgentModeDisconnected)
+			Expect(current()).To(Equal(models.ConsoleStatusDisconnected))
+		})
+
+		It("should restart the run loop when it panics", func() {
+			inventoryReceived := make(chan bool, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") {
+					inventoryReceived <- true
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
+			collector.panics.Store(1)
+			Expect(st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")).To(Succeed())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(inventoryReceived, time.Second).Should(Receive())
+			Expect(consoleSrv.Status().Error).To(MatchError(ContainSubstring("run loop panicked")))
+		})
+
 		It("should return current console status", func() {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(http.StatusOK)
@@ -342,6 +474,7 @@ var _ = Describe("Console Service", func() {
 			// Set collector to collected status so inventory would be sent if not blocked
 			collector.SetStatus(models.CollectorStatusCollected)
 			collector.inventory = []byte(`{"test": "data"}`)
+			Expect(st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")).To(Succeed())
 
 			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
 			consoleSrv.SetMode(models.AgentModeConnected)
@@ -358,6 +491,128 @@ var _ = Describe("Console Service", func() {
 			Consistently(statusReceived, 300*time.Millisecond).ShouldNot(Receive())
 		})
 
+		It("should report the stop reason and restart when switched to connected mode", func() {
+			var gone atomic.Bool
+			gone.Store(true)
+			statusReceived := make(chan bool, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				statusReceived <- true
+				if gone.Load() {
+					w.WriteHeader(http.StatusGone)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() models.ConsoleStopReason {
+				return consoleSrv.Status().StopReason
+			}, 500*time.Millisecond).Should(Equal(models.ConsoleStopReasonSourceGone))
+
+			status := consoleSrv.Status()
+			Expect(status.Current).To(Equal(models.ConsoleStatusError))
+			Expect(status.Target).To(Equal(models.ConsoleStatusConnected))
+			Expect(status.Error).To(HaveOccurred())
+
+			for len(statusReceived) > 0 {
+				<-statusReceived
+			}
+
+			By("restarting the loop")
+			gone.Store(false)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			status = consoleSrv.Status()
+			Expect(status.StopReason).To(BeEmpty())
+			Expect(status.Current).NotTo(Equal(models.ConsoleStatusError))
+			Eventually(statusReceived, 500*time.Millisecond).Should(Receive())
+		})
+
+		It("should not start another loop when already connected", func() {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg.UpdateInterval = 100 * time.Millisecond
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			for range 5 {
+				consoleSrv.SetMode(models.AgentModeConnected)
+			}
+
+			time.Sleep(450 * time.Millisecond)
+
+			// a single loop sends the first status and one status per tick
+			Expect(requests.Load()).To(BeNumerically("<=", 6))
+		})
+
+		It("should switch to disconnected mode after the loop stopped", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusGone)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() models.ConsoleStopReason {
+				return consoleSrv.Status().StopReason
+			}, 500*time.Millisecond).Should(Equal(models.ConsoleStopReasonSourceGone))
+
+			done := make(chan any)
+			go func() {
+				defer close(done)
+				consoleSrv.SetMode(models.AgentModeDisconnected)
+			}()
+			Eventually(done, time.Second).Should(BeClosed())
+			Expect(consoleSrv.Status().Target).To(Equal(models.ConsoleStatusDisconnected))
+		})
+
+		It("should clear the stop reason when switched to disconnected mode after the loop stopped", func() {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() models.ConsoleStopReason {
+				return consoleSrv.Status().StopReason
+			}, 500*time.Millisecond).Should(Equal(models.ConsoleStopReasonUnauthorized))
+			Expect(consoleSrv.Status().Current).To(Equal(models.ConsoleStatusError))
+			// the loop stopped, nothing stops it in disconnected mode
+			Eventually(requests.Load, 500*time.Millisecond).Should(Equal(int32(1)))
+
+			consoleSrv.SetMode(models.AgentModeDisconnected)
+
+			status := consoleSrv.Status()
+			Expect(status.Current).To(Equal(models.ConsoleStatusDisconnected))
+			Expect(status.Target).To(Equal(models.ConsoleStatusDisconnected))
+			Expect(status.StopReason).To(BeEmpty())
+			Expect(status.Error).To(BeNil())
+			Consistently(requests.Load, 200*time.Millisecond).Should(Equal(int32(1)))
+		})
+
 		It("should stop sending requests when agent is unauthorized (401)", func() {
 			statusReceived := make(chan bool, 10)
 			inventoryReceived := make(chan bool, 10)
@@ -377,6 +632,7 @@ var _ = Describe("Console Service", func() {
 			// Set collector to collected status so inventory would be sent if not blocked
 			collector.SetStatus(models.CollectorStatusCollected)
 			collector.inventory = []byte(`{"test": "data"}`)
+			Expect(st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")).To(Succeed())
 
 			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
 			consoleSrv.SetMode(models.AgentModeConnected)
@@ -412,7 +668,74 @@ var _ = Describe("Console Service", func() {
 		})
 	})
 
+	Describe("Backoff", func() {
+		It("should back off status updates after consecutive failures", func() {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg.BackoffInitial = 200 * time.Millisecond
+			cfg.BackoffMax = time.Minute
+			cfg.BackoffMultiplier = 2
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() int {
+				return consoleSrv.Status().StatusBackoff.ConsecutiveFailures
+			}, time.Second).Should(BeNumerically(">=", 2))
+
+			status := consoleSrv.Status()
+			Expect(status.StatusBackoff.Delay).To(BeNumerically(">=", 400*time.Millisecond))
+			Expect(status.StatusBackoff.NextAttempt).To(BeTemporally(">", time.Now()))
+			Expect(status.InventoryBackoff.ConsecutiveFailures).To(BeZero())
+
+			// 200ms then 400ms between attempts instead of one attempt per 50ms tick
+			Expect(requests.Load()).To(BeNumerically("<=", 4))
+		})
+
+		It("should reset the backoff after a successful update", func() {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) <= 2 {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg.BackoffInitial = 50 * time.Millisecond
+			cfg.BackoffMax = time.Second
+			cfg.BackoffMultiplier = 2
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() bool {
+				return !consoleSrv.Status().LastStatusUpdate.IsZero()
+			}, 2*time.Second).Should(BeTrue())
+
+			Eventually(func() models.BackoffStatus {
+				return consoleSrv.Status().StatusBackoff
+			}, time.Second).Should(Equal(models.BackoffStatus{}))
+		})
+	})
+
 	Describe("Inventory", func() {
+		BeforeEach(func() {
+			Expect(st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")).To(Succeed())
+		})
+
 		It("should send inventory when collector status is collected", func() {
 			statusReceived := make(chan bool, 10)
 			inventoryReceived := make(chan bool, 10)
@@ -443,7 +766,7 @@ var _ = Describe("Console Service", func() {
 			Eventually(inventoryReceived, 500*time.Millisecond).Should(Receive())
 		})
 
-		It("should not send inventory when collector status is not collected", func() {
+		It("should not send inventory until one is collected", func() {
 			statusReceived := make(chan bool, 10)
 			inventoryReceived := make(chan bool, 10)
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -459,9 +782,7 @@ var _ = Describe("Console Service", func() {
 			client, err := console.NewConsoleClient(server.URL, "")
 			Expect(err).NotTo(HaveOccurred())
 
-			// Collector status is Ready (set in BeforeEach)
-			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
-
+			// Collector status is Ready and nothing was collected (set in BeforeEach)
 			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
 			consoleSrv.SetMode(models.AgentModeConnected)
 
@@ -473,10 +794,10 @@ var _ = Describe("Console Service", func() {
 		})
 
 		It("should not resend inventory if unchanged", func() {
-			inventoryCount := 0
+			var inventoryCount atomic.Int32
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				if strings.Contains(r.URL.Path, "sources") {
-					inventoryCount++
+					inventoryCount.Add(1)
 				}
 				w.WriteHeader(http.StatusOK)
 			}))
@@ -495,18 +816,18 @@ var _ = Describe("Console Service", func() {
 			time.Sleep(300 * time.Millisecond)
 
 			// Inventory should only be sent once since it hasn't changed
-			Expect(inventoryCount).To(Equal(1))
+			Expect(inventoryCount.Load()).To(Equal(int32(1)))
 		})
 
 		It("should not send more inventory after unauthorized error (401)", func() {
-			inventoryCount := 0
+			var inventoryCount atomic.Int32
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				if strings.Contains(r.URL.Path, "agents") {
 					w.WriteHeader(http.StatusOK)
 					return
 				}
 				if strings.Contains(r.URL.Path, "sources") {
-					inventoryCount++
+					inventoryCount.Add(1)
 					w.WriteHeader(http.StatusUnauthorized)
 					return
 				}
@@ -527,10 +848,10 @@ var _ = Describe("Console Service", func() {
 			time.Sleep(300 * time.Millisecond)
 
 			// Inventory should have been attempted once
-			Expect(inventoryCount).To(Equal(1))
+			Expect(inventoryCount.Load()).To(Equal(int32(1)))
 
 			// Change inventory to trigger a new send attempt
-			collector.inventory = []byte(`{"vms": [{"name": "vm2"}]}`)
+			collector.SetInventory([]byte(`{"vms": [{"name": "vm2"}]}`))
 
 			// Wait for more ticks
 			time.Sleep(300 * time.Millisecond)
@@ -571,5 +892,564 @@ var _ = Describe("Console Service", func() {
 			Expect(status.Error).NotTo(BeNil())
 			Expect(status.Error.Error()).To(ContainSubstring("failed to update source inventory"))
 		})
+
+		It("should retry a failed inventory upload until it is delivered", func() {
+			var inventoryCount atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") && inventoryCount.Add(1) <= 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() string {
+				upload, err := st.InventoryUploads().Get(context.Background(), sourceID)
+				if err != nil || upload.Pending() {
+					return ""
+				}
+				return upload.DeliveredHash
+			}, time.Second).Should(Equal(fmt.Sprintf("%x", sha256.Sum256(collector.inventory))))
+			Expect(inventoryCount.Load()).To(Equal(int32(3)))
+			Expect(consoleSrv.Status().LastInventoryUpdate.IsZero()).To(BeFalse())
+
+			// the delivered inventory is not sent again
+			Consistently(inventoryCount.Load, 200*time.Millisecond).Should(Equal(int32(3)))
+		})
+
+		It("should stop retrying the inventory upload after the max retries", func() {
+			var inventoryCount atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") {
+					inventoryCount.Add(1)
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
+
+			cfg.InventoryMaxRetries = 2
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(inventoryCount.Load, time.Second).Should(Equal(int32(3)))
+			Consistently(inventoryCount.Load, 300*time.Millisecond).Should(Equal(int32(3)))
+
+			upload, err := st.InventoryUploads().Get(context.Background(), sourceID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upload.Pending()).To(BeTrue())
+			Expect(upload.Attempts).To(Equal(3))
+
+			By("sending the inventory again once it changes")
+			collector.SetInventory([]byte(`{"vms": [{"name": "vm2"}]}`))
+			Eventually(inventoryCount.Load, time.Second).Should(BeNumerically(">", 3))
+		})
+
+		It("should deliver the pending inventory after a restart", func() {
+			unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer unavailable.Close()
+
+			client, err := console.NewConsoleClient(unavailable.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() bool {
+				upload, err := st.InventoryUploads().Get(context.Background(), sourceID)
+				return err == nil && upload.Attempts > 0
+			}, time.Second).Should(BeTrue())
+			consoleSrv.SetMode(models.AgentModeDisconnected)
+
+			By("restarting with console available")
+			inventoryReceived := make(chan []byte, 10)
+			available := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") {
+					body, _ := io.ReadAll(r.Body)
+					inventoryReceived <- body
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer available.Close()
+
+			client, err = console.NewConsoleClient(available.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			restarted := services.NewConsoleService(cfg, sched, client, collector, st)
+			restarted.SetMode(models.AgentModeConnected)
+
+			Eventually(inventoryReceived, time.Second).Should(Receive(ContainSubstring("vm1")))
+			Eventually(func() bool {
+				upload, err := st.InventoryUploads().Get(context.Background(), sourceID)
+				return err == nil && !upload.Pending()
+			}, time.Second).Should(BeTrue())
+		})
+	})
+
+	Describe("Anonymization", func() {
+		BeforeEach(func() {
+			Expect(st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")).To(Succeed())
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vcenter_id": "vcenter-1", "clusters": {"domain-c1": {"infra": {"networks": [{"name": "prod-net"}]}}}}`)
+			cfg.AnonymizeInventory = true
+		})
+
+		It("should send the anonymized inventory shown by the preview", func() {
+			inventoryReceived := make(chan []byte, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") {
+					body, _ := io.ReadAll(r.Body)
+					inventoryReceived <- body
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			preview, err := consoleSrv.InventoryPreview(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(preview)).NotTo(ContainSubstring("vcenter-1"))
+			Expect(string(preview)).NotTo(ContainSubstring("domain-c1"))
+			Expect(string(preview)).NotTo(ContainSubstring("prod-net"))
+
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			var body []byte
+			Eventually(inventoryReceived, 500*time.Millisecond).Should(Receive(&body))
+			Expect(body).NotTo(ContainSubstring("vcenter-1"))
+			Expect(body).To(ContainSubstring(string(preview)))
+		})
+
+		It("should keep the hashes across restarts", func() {
+			client, err := console.NewConsoleClient("http://localhost", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			preview, err := services.NewConsoleService(cfg, sched, client, collector, st).InventoryPreview(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			restarted, err := services.NewConsoleService(cfg, sched, client, collector, st).InventoryPreview(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restarted).To(Equal(preview))
+		})
+
+		It("should send the inventory unchanged when the anonymization is disabled", func() {
+			client, err := console.NewConsoleClient("http://localhost", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg.AnonymizeInventory = false
+			preview, err := services.NewConsoleService(cfg, sched, client, collector, st).InventoryPreview(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(preview).To(Equal(collector.inventory))
+		})
+	})
+
+	Describe("Consent", func() {
+		It("should return no consent when nothing was set", func() {
+			client, err := console.NewConsoleClient("http://localhost", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+
+			settings, err := consoleSrv.Consent(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(settings.DataSharingAllowed).To(BeFalse())
+			Expect(settings.ConsentSetAt.IsZero()).To(BeTrue())
+		})
+
+		It("should not send inventory without consent in connected mode", func() {
+			statusReceived := make(chan bool, 10)
+			inventoryReceived := make(chan bool, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "agents") {
+					statusReceived <- true
+				} else if strings.Contains(r.URL.Path, "sources") {
+					inventoryReceived <- true
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(statusReceived, 500*time.Millisecond).Should(Receive())
+			Consistently(inventoryReceived, 300*time.Millisecond).ShouldNot(Receive())
+
+			By("granting the consent")
+			settings, err := consoleSrv.SetConsent(context.Background(), true, "v1", "test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(settings.DataSharingAllowed).To(BeTrue())
+			Expect(settings.ConsentTermsVersion).To(Equal("v1"))
+			Expect(settings.ConsentSetBy).To(Equal("test"))
+
+			Eventually(inventoryReceived, 500*time.Millisecond).Should(Receive())
+		})
+
+		It("should stop the pending inventory upload when the consent is revoked", func() {
+			var inventoryCount atomic.Int32
+			uploadCancelled := make(chan bool, 10)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") {
+					inventoryCount.Add(1)
+					// the closed connection is noticed only once the body is read
+					_, _ = io.ReadAll(r.Body)
+					// hold the upload until the agent gives up on it
+					select {
+					case <-r.Context().Done():
+						uploadCancelled <- true
+					case <-time.After(5 * time.Second):
+					}
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
+			Expect(st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")).To(Succeed())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(inventoryCount.Load, 500*time.Millisecond).Should(Equal(int32(1)))
+
+			_, err = consoleSrv.SetConsent(context.Background(), false, "", "test")
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(uploadCancelled, time.Second).Should(Receive())
+			Eventually(func() bool {
+				upload, err := st.InventoryUploads().Get(context.Background(), sourceID)
+				return err == nil && !upload.Pending()
+			}, time.Second).Should(BeTrue())
+			Consistently(inventoryCount.Load, 300*time.Millisecond).Should(Equal(int32(1)))
+
+			upload, err := st.InventoryUploads().Get(context.Background(), sourceID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upload.DeliveredHash).To(BeEmpty())
+		})
+	})
+
+	Describe("Outbox", func() {
+		var (
+			server   *httptest.Server
+			requests atomic.Int32
+		)
+
+		BeforeEach(func() {
+			requests.Store(0)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusOK)
+			}))
+			DeferCleanup(server.Close)
+
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
+		})
+
+		It("should withhold the inventory without consent", func() {
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg.Version = "v1.0.0"
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+
+			outbox, err := consoleSrv.Outbox(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outbox.Status.Method).To(Equal(http.MethodPut))
+			Expect(outbox.Status.URL).To(Equal(server.URL + "/api/v1/agents/" + agentID + "/status"))
+			Expect(string(outbox.Status.Body)).To(ContainSubstring(`"version":"v1.0.0"`))
+			Expect(string(outbox.Status.Body)).To(ContainSubstring(`"status":"collected"`))
+			Expect(outbox.Inventory).To(BeNil())
+			Expect(outbox.InventoryWithheld).To(Equal(models.InventoryWithheldNoConsent))
+
+			Expect(requests.Load()).To(BeZero())
+		})
+
+		It("should show the inventory sent next until it is delivered", func() {
+			Expect(st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")).To(Succeed())
+			Expect(st.AgentSettings().SaveMode(context.Background(), models.AgentModeDisconnected, "test")).To(Succeed())
+			client, err := console.NewConsoleClient(server.URL, "", console.WithCompression(true))
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+
+			outbox, err := consoleSrv.Outbox(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outbox.Inventory).NotTo(BeNil())
+			Expect(outbox.Inventory.URL).To(Equal(server.URL + "/api/v1/sources/" + sourceID + "/status"))
+			Expect(outbox.Inventory.Body).To(Equal(collector.inventory))
+			Expect(outbox.Inventory.Compressed).To(BeTrue())
+			hash := fmt.Sprintf("%x", sha256.Sum256(collector.inventory))
+			Expect(outbox.InventoryHash).To(Equal(hash))
+			Expect(outbox.LastUploadHash).To(BeEmpty())
+			Expect(requests.Load()).To(BeZero())
+
+			By("delivering the inventory")
+			consoleSrv.SetMode(models.AgentModeConnected)
+			Eventually(func() string {
+				outbox, err := consoleSrv.Outbox(context.Background())
+				Expect(err).NotTo(HaveOccurred())
+				return outbox.LastUploadHash
+			}, time.Second).Should(Equal(hash))
+
+			outbox, err = consoleSrv.Outbox(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outbox.Inventory).To(BeNil())
+			Expect(outbox.InventoryWithheld).To(Equal(models.InventoryWithheldUnchanged))
+			Expect(outbox.LastUploadAt.IsZero()).To(BeFalse())
+		})
+	})
+
+	Describe("Inventory collected by the collector", func() {
+		var (
+			ctx   context.Context
+			creds *models.Credentials
+			real  *services.CollectorService
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			Expect(st.AgentSettings().SaveConsent(ctx, true, "v1", "test")).To(Succeed())
+
+			model := simulator.VPX()
+			Expect(model.Create()).To(Succeed())
+			DeferCleanup(model.Remove)
+			model.Service.Listen = &url.URL{User: url.UserPassword("user", "pass")}
+			model.Service.TLS = new(tls.Config)
+			server := model.Service.NewServer()
+			DeferCleanup(server.Close)
+
+			u := *server.URL
+			u.User = nil
+			creds = &models.Credentials{
+				URL:        u.String(),
+				Username:   "user",
+				Password:   "pass",
+				Thumbprint: soap.ThumbprintSHA1(server.Certificate()),
+			}
+
+			cfg.DataFolder = GinkgoT().TempDir()
+			cfg.CollectorTimeout = time.Minute
+			real = services.NewCollectorService(sched, st, cfg)
+		})
+
+		// collect runs a collection through ready, connecting, connected, collecting, collected and back
+		// to ready, and returns the hash of the inventory snapshot it saved.
+		collect := func() string {
+			Expect(real.Start(ctx, creds, false)).To(Succeed())
+			Eventually(func() models.CollectionRunState {
+				runs, _, err := real.ListRuns(ctx, 1, 0)
+				Expect(err).NotTo(HaveOccurred())
+				return runs[0].State
+			}, "60s").Should(Equal(models.CollectionRunStateSucceeded))
+			Expect(real.Status()).To(Equal(models.CollectorStatusReady))
+
+			snapshot, err := real.GetInventory(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			return fmt.Sprintf("%x", sha256.Sum256(snapshot.Data))
+		}
+
+		deliveredHash := func() string {
+			upload, err := st.InventoryUploads().Get(ctx, sourceID)
+			if err != nil || upload.Pending() {
+				return ""
+			}
+			return upload.DeliveredHash
+		}
+
+		It("should retry the upload of the latest snapshot after the collector is back to ready", func() {
+			var inventoryCount atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") && inventoryCount.Add(1) <= 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, real, st)
+
+			outbox, err := consoleSrv.Outbox(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outbox.InventoryWithheld).To(Equal(models.InventoryWithheldNotCollected))
+
+			consoleSrv.SetMode(models.AgentModeConnected)
+			hash := collect()
+
+			Eventually(deliveredHash, 5*time.Second).Should(Equal(hash))
+			Expect(inventoryCount.Load()).To(Equal(int32(3)))
+
+			// the delivered inventory is not sent again
+			Consistently(inventoryCount.Load, 200*time.Millisecond).Should(Equal(int32(3)))
+
+			outbox, err = consoleSrv.Outbox(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outbox.Inventory).To(BeNil())
+			Expect(outbox.InventoryWithheld).To(Equal(models.InventoryWithheldUnchanged))
+			Expect(outbox.LastUploadHash).To(Equal(hash))
+		})
+
+		It("should deliver the snapshot collected before a restart", func() {
+			unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer unavailable.Close()
+
+			client, err := console.NewConsoleClient(unavailable.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, real, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+			hash := collect()
+
+			Eventually(func() bool {
+				upload, err := st.InventoryUploads().Get(ctx, sourceID)
+				return err == nil && upload.Pending() && upload.Attempts > 0
+			}, 5*time.Second).Should(BeTrue())
+			consoleSrv.SetMode(models.AgentModeDisconnected)
+			Expect(real.Stop(ctx)).To(Succeed())
+
+			By("restarting the agent with console available")
+			inventoryReceived := make(chan []byte, 10)
+			available := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.URL.Path, "sources") {
+					body, _ := io.ReadAll(r.Body)
+					inventoryReceived <- body
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer available.Close()
+
+			client, err = console.NewConsoleClient(available.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			restartedCollector := services.NewCollectorService(sched, st, cfg)
+			Expect(restartedCollector.Status()).To(Equal(models.CollectorStatusReady))
+			restarted := services.NewConsoleService(cfg, sched, client, restartedCollector, st)
+
+			outbox, err := restarted.Outbox(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outbox.Inventory).NotTo(BeNil())
+			Expect(outbox.InventoryHash).To(Equal(hash))
+
+			restarted.SetMode(models.AgentModeConnected)
+			Eventually(inventoryReceived, 5*time.Second).Should(Receive())
+			Eventually(deliveredHash, 5*time.Second).Should(Equal(hash))
+		})
+	})
+
+	Describe("AgentStatus", func() {
+		It("should return agent identity and collector status", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg.Version = "v1.0.0"
+			collector.SetStatus(models.CollectorStatusCollecting)
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+
+			status := consoleSrv.AgentStatus()
+			Expect(status.ID).To(Equal(agentID))
+			Expect(status.SourceID).To(Equal(sourceID))
+			Expect(status.Version).To(Equal("v1.0.0"))
+			Expect(status.Collector).To(Equal(models.CollectorStatusCollecting))
+			Expect(status.Console.Target).To(Equal(models.ConsoleStatusDisconnected))
+			Expect(status.Console.LastStatusUpdate).To(BeZero())
+		})
+
+		It("should record the time of the last successful status and inventory update", func() {
+			Expect(st.AgentSettings().SaveConsent(context.Background(), true, "v1", "test")).To(Succeed())
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			collector.SetStatus(models.CollectorStatusCollected)
+			collector.inventory = []byte(`{"vms": [{"name": "vm1"}]}`)
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() bool {
+				return consoleSrv.AgentStatus().Console.LastStatusUpdate.IsZero()
+			}, 500*time.Millisecond).Should(BeFalse())
+			Eventually(func() bool {
+				return consoleSrv.AgentStatus().Console.LastInventoryUpdate.IsZero()
+			}, 500*time.Millisecond).Should(BeFalse())
+		})
+
+		It("should not record update time when console rejects the status", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			client, err := console.NewConsoleClient(server.URL, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			consoleSrv := services.NewConsoleService(cfg, sched, client, collector, st)
+			consoleSrv.SetMode(models.AgentModeConnected)
+
+			Eventually(func() error {
+				return consoleSrv.AgentStatus().Console.Error
+			}, 500*time.Millisecond).ShouldNot(BeNil())
+			Expect(consoleSrv.AgentStatus().Console.LastStatusUpdate).To(BeZero())
+		})
 	})
 })
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// DiffInventories compares two inventory snapshots.
+// Counts are read from the inventories; VMs are compared using the VM summaries
+// recorded with each snapshot, when both snapshots have them.
+func DiffInventories(from, to *models.Inventory) (*models.InventoryDiff, error) {
+	var fromInv, toInv apiplanner.Inventory
+	if err := json.Unmarshal(from.Data, &fromInv); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory snapshot %d: %w", from.ID, err)
+	}
+	if err := json.Unmarshal(to.Data, &toInv); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory snapshot %d: %w", to.ID, err)
+	}
+
+	diff := &models.InventoryDiff{
+		From:       from.ID,
+		To:         to.ID,
+		AddedVMs:   []models.VMSummary{},
+		RemovedVMs: []models.VMSummary{},
+		ChangedVMs: []models.VMChange{},
+		Clusters:   models.CountChange{From: len(fromInv.Clusters), To: len(toInv.Clusters)},
+	}
+	if fromInv.Vcenter != nil {
+		diff.VMs.From = fromInv.Vcenter.Vms.Total
+		diff.Hosts.From = fromInv.Vcenter.Infra.TotalHosts
+		diff.Datastores.From = len(fromInv.Vcenter.Infra.Datastores)
+	}
+	if toInv.Vcenter != nil {
+		diff.VMs.To = toInv.Vcenter.Vms.Total
+		diff.Hosts.To = toInv.Vcenter.Infra.TotalHosts
+		diff.Datastores.To = len(toInv.Vcenter.Infra.Datastores)
+	}
+
+	if from.VMs == nil || to.VMs == nil {
+		return diff, nil
+	}
+
+	var fromVMs, toVMs []models.VMSummary
+	if err := json.Unmarshal(from.VMs, &fromVMs); err != nil {
+		return nil, fmt.Errorf("failed to decode vms of inventory snapshot %d: %w", from.ID, err)
+	}
+	if err := json.Unmarshal(to.VMs, &toVMs); err != nil {
+		return nil, fmt.Errorf("failed to decode vms of inventory snapshot %d: %w", to.ID, err)
+	}
+	diff.VMsCompared = true
+
+	previous := make(map[string]models.VMSummary, len(fromVMs))
+	for _, vm := range fromVMs {
+		previous[vm.ID] = vm
+	}
+
+	for _, vm := range toVMs {
+		old, found := previous[vm.ID]
+		if !found {
+			diff.AddedVMs = append(diff.AddedVMs, vm)
+			continue
+		}
+		delete(previous, vm.ID)
+
+		if changes := diffVM(old, vm); len(changes) > 0 {
+			diff.ChangedVMs = append(diff.ChangedVMs, models.VMChange{ID: vm.ID, Name: vm.Name, Changes: changes})
+		}
+	}
+
+	for _, vm := range fromVMs {
+		if _, removed := previous[vm.ID]; removed {
+			diff.RemovedVMs = append(diff.RemovedVMs, vm)
+		}
+	}
+
+	return diff, nil
+}
+
+func diffVM(from, to models.VMSummary) []models.FieldChange {
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", from.Name, to.Name},
+		{"cpu", strconv.Itoa(from.CpuCount), strconv.Itoa(to.CpuCount)},
+		{"memoryMB", strconv.Itoa(from.MemoryMB), strconv.Itoa(to.MemoryMB)},
+		{"disksGB", joinInts(from.DisksGB), joinInts(to.DisksGB)},
+		{"nics", strings.Join(from.Networks, ","), strings.Join(to.Networks, ",")},
+		{"powerState", from.PowerState, to.PowerState},
+		{"host", from.Host, to.Host},
+		{"datastores", strings.Join(from.Datastores, ","), strings.Join(to.Datastores, ",")},
+	}
+
+	var changes []models.FieldChange
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, models.FieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
+
+func joinInts(values []int) string {
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		s = append(s, strconv.Itoa(v))
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"encoding/json"
+
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+)
+
+var _ = Describe("DiffInventories", func() {
+	snapshot := func(id int64, hosts int, vms []models.VMSummary) *models.Inventory {
+		inv := apiplanner.Inventory{
+			Vcenter: &apiplanner.InventoryData{
+				Vms:   apiplanner.VMs{Total: len(vms)},
+				Infra: apiplanner.Infra{TotalHosts: hosts, Datastores: []apiplanner.Datastore{{}}},
+			},
+			Clusters: map[string]apiplanner.InventoryData{"domain-c1": {}},
+		}
+		data, err := json.Marshal(inv)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := &models.Inventory{ID: id, Data: data}
+		if vms != nil {
+			s.VMs, err = json.Marshal(vms)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return s
+	}
+
+	vm := func(id string) models.VMSummary {
+		return models.VMSummary{
+			ID:         id,
+			Name:       id,
+			CpuCount:   2,
+			MemoryMB:   4096,
+			DisksGB:    []int{20},
+			Networks:   []string{"network-1"},
+			PowerState: "poweredOn",
+			Host:       "host-1",
+			Datastores: []string{"datastore-1"},
+		}
+	}
+
+	It("should report added, removed and changed vms", func() {
+		changed := vm("vm-2")
+		changed.CpuCount = 4
+		changed.Host = "host-2"
+
+		diff, err := services.DiffInventories(
+			snapshot(1, 2, []models.VMSummary{vm("vm-1"), vm("vm-2"), vm("vm-3")}),
+			snapshot(2, 3, []models.VMSummary{changed, vm("vm-3"), vm("vm-4")}),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(diff.From).To(Equal(int64(1)))
+		Expect(diff.To).To(Equal(int64(2)))
+		Expect(diff.VMsCompared).To(BeTrue())
+		Expect(diff.AddedVMs).To(ConsistOf(HaveField("ID", "vm-4")))
+		Expect(diff.RemovedVMs).To(ConsistOf(HaveField("ID", "vm-1")))
+		Expect(diff.ChangedVMs).To(HaveLen(1))
+		Expect(diff.ChangedVMs[0].ID).To(Equal("vm-2"))
+		Expect(diff.ChangedVMs[0].Changes).To(ConsistOf(
+			models.FieldChange{Field: "cpu", From: "2", To: "4"},
+			models.FieldChange{Field: "host", From: "host-1", To: "host-2"},
+		))
+
+		Expect(diff.VMs).To(Equal(models.CountChange{From: 3, To: 3}))
+		Expect(diff.Hosts).To(Equal(models.CountChange{From: 2, To: 3}))
+		Expect(diff.Clusters).To(Equal(models.CountChange{From: 1, To: 1}))
+		Expect(diff.Datastores).To(Equal(models.CountChange{From: 1, To: 1}))
+	})
+
+	It("should only compare counts when a snapshot has no vm summaries", func() {
+		diff, err := services.DiffInventories(
+			snapshot(1, 2, nil),
+			snapshot(2, 2, []models.VMSummary{vm("vm-1")}),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(diff.VMsCompared).To(BeFalse())
+		Expect(diff.AddedVMs).To(BeEmpty())
+		Expect(diff.VMs).To(Equal(models.CountChange{From: 0, To: 1}))
+	})
+})
@@ -0,0 +1,736 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	vspheremodel "github.com/kubev2v/forklift/pkg/controller/provider/model/vsphere"
+	libmodel "github.com/kubev2v/forklift/pkg/lib/inventory/model"
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+var vendorMap = map[string]string{
+	"NETAPP":   "NetApp",
+	"EMC":      "Dell EMC",
+	"PURE":     "Pure Storage",
+	"3PARDATA": "HPE",
+	"ATA":      "ATA",
+	"DELL EMC": "Dell EMC",
+	"DELL":     "Dell",
+	"HPE":      "HPE",
+	"IBM":      "IBM",
+	"HITACHI":  "Vantara",
+	"CISCO":    "Cisco",
+	"FUJITSU":  "Fujitsu",
+	"LENOVO":   "Lenovo",
+}
+
+// InventoryBuilder builds the migration-planner inventory from the forklift vSphere database.
+type InventoryBuilder struct {
+	db libmodel.DB
+}
+
+func NewInventoryBuilder(db libmodel.DB) *InventoryBuilder {
+	return &InventoryBuilder{db: db}
+}
+
+// vsphereData holds the raw objects read from the forklift database.
+type vsphereData struct {
+	about       vspheremodel.About
+	vms         []vspheremodel.VM
+	hosts       []vspheremodel.Host
+	clusters    []vspheremodel.Cluster
+	datacenters []vspheremodel.Datacenter
+	folders     []vspheremodel.Folder
+	datastores  []vspheremodel.Datastore
+	networks    []vspheremodel.Network
+}
+
+// Build reads the collected vSphere objects and returns the inventory
+// with a vCenter-level view and one entry per cluster.
+func (b *InventoryBuilder) Build() (*apiplanner.Inventory, error) {
+	data, err := b.load()
+	if err != nil {
+		return nil, err
+	}
+
+	hostToCluster := make(map[string]string, len(data.hosts))
+	hostPowerState := make(map[string]string, len(data.hosts))
+	for _, h := range data.hosts {
+		if h.Cluster != "" {
+			hostToCluster[h.ID] = h.Cluster
+		}
+		hostPowerState[h.ID] = h.Status
+	}
+
+	vmsByCluster := make(map[string][]vspheremodel.VM)
+	for _, vm := range data.vms {
+		if clusterID, ok := hostToCluster[vm.Host]; ok {
+			vmsByCluster[clusterID] = append(vmsByCluster[clusterID], vm)
+		}
+	}
+
+	clusterIDs := make([]string, 0, len(data.clusters))
+	for _, cl := range data.clusters {
+		if cl.ID != "" {
+			clusterIDs = append(clusterIDs, cl.ID)
+		}
+	}
+	sort.Strings(clusterIDs)
+
+	datastoreTypes := make(map[string]string, len(data.datastores))
+	for _, ds := range data.datastores {
+		datastoreTypes[ds.ID] = ds.Type
+	}
+
+	infra := models.InfrastructureData{
+		Datastores:            b.datastores(data.hosts, data.datastores),
+		Networks:              b.networks(data.networks, data.vms),
+		HostPowerStates:       countHostPowerStates(data.hosts),
+		Hosts:                 apiHosts(data.hosts),
+		HostsPerCluster:       hostsPerCluster(clusterIDs, hostToCluster),
+		ClustersPerDatacenter: clustersPerDatacenter(data.datacenters, data.folders),
+		TotalHosts:            len(data.hosts),
+		TotalClusters:         len(clusterIDs),
+		TotalDatacenters:      len(data.datacenters),
+		VmsPerCluster:         vmsPerCluster(clusterIDs, vmsByCluster),
+	}
+
+	vcenter := newInventoryData(data.vms, infra)
+	fillVMsData(data.vms, vcenter, datastoreTypes)
+
+	inventory := &apiplanner.Inventory{
+		VcenterId: data.about.InstanceUuid,
+		Vcenter:   vcenter,
+		Clusters:  make(map[string]apiplanner.InventoryData, len(clusterIDs)),
+	}
+
+	for _, clusterID := range clusterIDs {
+		clusterVMs := vmsByCluster[clusterID]
+		clusterInfra := filterInfraByCluster(infra, data, clusterID, clusterVMs, hostToCluster, hostPowerState)
+
+		inv := newInventoryData(clusterVMs, clusterInfra)
+		fillVMsData(clusterVMs, inv, datastoreTypes)
+		inventory.Clusters[clusterID] = *inv
+	}
+
+	zap.S().Infow("inventory built", "vms", len(data.vms), "hosts", len(data.hosts), "clusters", len(clusterIDs))
+
+	return inventory, nil
+}
+
+// VMs returns a summary of each collected VM, sorted by id.
+// The summaries are stored with the inventory snapshot to compare VMs between snapshots.
+func (b *InventoryBuilder) VMs() ([]models.VMSummary, error) {
+	var vms []vspheremodel.VM
+	if err := b.db.List(&vms, libmodel.FilterOptions{Detail: 1, Predicate: libmodel.Eq("IsTemplate", false)}); err != nil {
+		return nil, fmt.Errorf("failed to list vms: %w", err)
+	}
+
+	summaries := make([]models.VMSummary, 0, len(vms))
+	for _, vm := range vms {
+		summary := models.VMSummary{
+			ID:         vm.ID,
+			Name:       vm.Name,
+			CpuCount:   int(vm.CpuCount),
+			MemoryMB:   int(vm.MemoryMB),
+			DisksGB:    make([]int, 0, len(vm.Disks)),
+			Networks:   make([]string, 0, len(vm.NICs)),
+			PowerState: vm.PowerState,
+			Host:       vm.Host,
+			Datastores: []string{},
+		}
+		for _, disk := range vm.Disks {
+			summary.DisksGB = append(summary.DisksGB, bytesToGB(disk.Capacity))
+			if disk.Datastore.ID != "" && !slices.Contains(summary.Datastores, disk.Datastore.ID) {
+				summary.Datastores = append(summary.Datastores, disk.Datastore.ID)
+			}
+		}
+		for _, nic := range vm.NICs {
+			summary.Networks = append(summary.Networks, nic.Network.ID)
+		}
+		sort.Strings(summary.Datastores)
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ID < summaries[j].ID })
+	return summaries, nil
+}
+
+func (b *InventoryBuilder) load() (*vsphereData, error) {
+	data := &vsphereData{}
+
+	if err := b.db.Get(&data.about); err != nil {
+		return nil, fmt.Errorf("failed to get vCenter about: %w", err)
+	}
+	if err := b.db.List(&data.vms, libmodel.FilterOptions{Detail: 1, Predicate: libmodel.Eq("IsTemplate", false)}); err != nil {
+		return nil, fmt.Errorf("failed to list vms: %w", err)
+	}
+	if err := b.db.List(&data.hosts, libmodel.FilterOptions{Detail: 1}); err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %w", err)
+	}
+	if err := b.db.List(&data.clusters, libmodel.FilterOptions{Detail: 1}); err != nil {
+		return nil, fmt.Errorf("failed to list clusters: %w", err)
+	}
+	if err := b.db.List(&data.datacenters, libmodel.FilterOptions{Detail: 1}); err != nil {
+		return nil, fmt.Errorf("failed to list datacenters: %w", err)
+	}
+	if err := b.db.List(&data.folders, libmodel.FilterOptions{Detail: 1}); err != nil {
+		return nil, fmt.Errorf("failed to list folders: %w", err)
+	}
+	if err := b.db.List(&data.datastores, libmodel.FilterOptions{Detail: 1}); err != nil {
+		return nil, fmt.Errorf("failed to list datastores: %w", err)
+	}
+	if err := b.db.List(&data.networks, libmodel.FilterOptions{Detail: 1}); err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	return data, nil
+}
+
+func (b *InventoryBuilder) datastores(hosts []vspheremodel.Host, datastores []vspheremodel.Datastore) []apiplanner.Datastore {
+	datastoreHosts := make(map[string][]string)
+	for _, h := range hosts {
+		for _, ref := range h.Datastores {
+			datastoreHosts[ref.ID] = append(datastoreHosts[ref.ID], h.ID)
+		}
+	}
+
+	result := make([]apiplanner.Datastore, 0, len(datastores))
+	for _, ds := range datastores {
+		vendor, model, protocol := findDatastoreInfo(hosts, ds.BackingDevicesNames)
+
+		var hostID *string
+		if ids := datastoreHosts[ds.ID]; len(ids) > 0 {
+			joined := strings.Join(ids, ", ")
+			hostID = &joined
+		}
+
+		diskID := "N/A"
+		if len(ds.BackingDevicesNames) > 0 {
+			diskID = ds.BackingDevicesNames[0]
+		}
+
+		result = append(result, apiplanner.Datastore{
+			TotalCapacityGB: bytesToGB(ds.Capacity),
+			FreeCapacityGB:  bytesToGB(ds.Free),
+			Type:            ds.Type,
+			Vendor:          vendorName(vendor),
+			Model:           model,
+			ProtocolType:    protocol,
+			DiskId:          diskID,
+			HostId:          hostID,
+		})
+	}
+
+	return result
+}
+
+func (b *InventoryBuilder) networks(networks []vspheremodel.Network, vms []vspheremodel.VM) []apiplanner.Network {
+	vmsPerNetwork := make(map[string]int)
+	for _, vm := range vms {
+		for _, n := range vm.Networks {
+			vmsPerNetwork[n.ID]++
+		}
+	}
+
+	result := make([]apiplanner.Network, 0, len(networks))
+	for _, n := range networks {
+		vlanID := n.VlanId
+		vmsCount := vmsPerNetwork[n.ID]
+
+		dvSwitch := &vspheremodel.Network{}
+		if n.Variant == vspheremodel.NetDvPortGroup {
+			dvSwitch.WithRef(n.DVSwitch)
+			if err := b.db.Get(dvSwitch); err != nil {
+				zap.S().Debugw("failed to get distributed switch", "network", n.Name, "error", err)
+			}
+		}
+
+		result = append(result, apiplanner.Network{
+			Name:     n.Name,
+			Type:     networkType(n),
+			VlanId:   &vlanID,
+			Dvswitch: &dvSwitch.Name,
+			VmsCount: &vmsCount,
+		})
+	}
+
+	return result
+}
+
+func networkType(n vspheremodel.Network) apiplanner.NetworkType {
+	switch n.Variant {
+	case vspheremodel.NetDvPortGroup:
+		return apiplanner.Distributed
+	case vspheremodel.NetStandard:
+		return apiplanner.Standard
+	case vspheremodel.NetDvSwitch:
+		return apiplanner.Dvswitch
+	default:
+		return apiplanner.Unsupported
+	}
+}
+
+func apiHosts(hosts []vspheremodel.Host) *[]apiplanner.Host {
+	result := make([]apiplanner.Host, 0, len(hosts))
+	for _, h := range hosts {
+		id := h.ID
+		cpuCores := int(h.CpuCores)
+		cpuSockets := int(h.CpuSockets)
+
+		var memoryMB *int64
+		if h.MemoryBytes > 0 {
+			mb := h.MemoryBytes / (1024 * 1024)
+			memoryMB = &mb
+		}
+
+		result = append(result, apiplanner.Host{
+			Id:         &id,
+			Model:      h.Model,
+			Vendor:     h.Vendor,
+			CpuCores:   &cpuCores,
+			CpuSockets: &cpuSockets,
+			MemoryMB:   memoryMB,
+		})
+	}
+	return &result
+}
+
+func countHostPowerStates(hosts []vspheremodel.Host) map[string]int {
+	states := map[string]int{}
+	for _, h := range hosts {
+		states[h.Status]++
+	}
+	return states
+}
+
+func hostsPerCluster(clusterIDs []string, hostToCluster map[string]string) []int {
+	counts := make(map[string]int, len(clusterIDs))
+	for _, clusterID := range hostToCluster {
+		counts[clusterID]++
+	}
+
+	result := make([]int, 0, len(clusterIDs))
+	for _, id := range clusterIDs {
+		result = append(result, counts[id])
+	}
+	return result
+}
+
+func vmsPerCluster(clusterIDs []string, vmsByCluster map[string][]vspheremodel.VM) []int {
+	result := make([]int, 0, len(clusterIDs))
+	for _, id := range clusterIDs {
+		result = append(result, len(vmsByCluster[id]))
+	}
+	return result
+}
+
+// clustersPerDatacenter counts the clusters found under the host folder of each datacenter.
+func clustersPerDatacenter(datacenters []vspheremodel.Datacenter, folders []vspheremodel.Folder) []int {
+	folderByID := make(map[string]vspheremodel.Folder, len(folders))
+	for _, f := range folders {
+		folderByID[f.ID] = f
+	}
+
+	result := make([]int, 0, len(datacenters))
+	for _, dc := range datacenters {
+		folder, ok := folderByID[dc.Clusters.ID]
+		if !ok {
+			continue
+		}
+		result = append(result, countClusters(folder, folderByID))
+	}
+	return result
+}
+
+func countClusters(folder vspheremodel.Folder, folderByID map[string]vspheremodel.Folder) int {
+	count := 0
+	for _, child := range folder.Children {
+		switch child.Kind {
+		case vspheremodel.ClusterKind:
+			if strings.HasPrefix(child.ID, "domain-c") {
+				count++
+			}
+		case vspheremodel.FolderKind:
+			count += countClusters(folderByID[child.ID], folderByID)
+		}
+	}
+	return count
+}
+
+// findDatastoreInfo returns the vendor, model and protocol of the disk backing a datastore.
+func findDatastoreInfo(hosts []vspheremodel.Host, names []string) (vendor, model, protocol string) {
+	vendor, model, protocol = "N/A", "N/A", "N/A"
+	if len(names) == 0 {
+		return
+	}
+
+	for _, h := range hosts {
+		for _, disk := range h.HostScsiDisks {
+			if disk.CanonicalName != names[0] {
+				continue
+			}
+			vendor = disk.Vendor
+
+			for _, topology := range h.HostScsiTopology {
+				if !slices.Contains(topology.ScsiDiskKeys, disk.Key) {
+					continue
+				}
+				for _, hba := range h.HbaDiskInfo {
+					if hba.Key == topology.HbaKey {
+						return vendor, hba.Model, hba.Protocol
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+func vendorName(vendor string) string {
+	raw := strings.TrimSpace(vendor)
+	if name, ok := vendorMap[strings.ToUpper(raw)]; ok {
+		return name
+	}
+	return raw
+}
+
+// filterInfraByCluster narrows the vCenter infrastructure down to the hosts of a cluster
+// and the datastores and networks used by its VMs.
+func filterInfraByCluster(
+	infra models.InfrastructureData,
+	data *vsphereData,
+	clusterID string,
+	clusterVMs []vspheremodel.VM,
+	hostToCluster map[string]string,
+	hostPowerState map[string]string,
+) models.InfrastructureData {
+	clusterHosts := []apiplanner.Host{}
+	powerStates := map[string]int{}
+	if infra.Hosts != nil {
+		for _, h := range *infra.Hosts {
+			if h.Id == nil || hostToCluster[*h.Id] != clusterID {
+				continue
+			}
+			clusterHosts = append(clusterHosts, h)
+			powerStates[hostPowerState[*h.Id]]++
+		}
+	}
+
+	usedDatastores := make(map[string]struct{})
+	usedNetworks := make(map[string]struct{})
+	for _, vm := range clusterVMs {
+		for _, d := range vm.Disks {
+			usedDatastores[d.Datastore.ID] = struct{}{}
+		}
+		for _, nic := range vm.NICs {
+			usedNetworks[nic.Network.ID] = struct{}{}
+		}
+		for _, n := range vm.Networks {
+			usedNetworks[n.ID] = struct{}{}
+		}
+	}
+
+	// infra.Datastores and infra.Networks are built in the same order as the forklift objects.
+	datastores := []apiplanner.Datastore{}
+	for i, ds := range data.datastores {
+		if _, ok := usedDatastores[ds.ID]; ok {
+			datastores = append(datastores, infra.Datastores[i])
+		}
+	}
+
+	networks := []apiplanner.Network{}
+	for i, n := range data.networks {
+		if _, ok := usedNetworks[n.ID]; ok {
+			networks = append(networks, infra.Networks[i])
+		}
+	}
+
+	return models.InfrastructureData{
+		Datastores:            datastores,
+		Networks:              networks,
+		HostPowerStates:       powerStates,
+		Hosts:                 &clusterHosts,
+		HostsPerCluster:       []int{len(clusterHosts)},
+		ClustersPerDatacenter: []int{1},
+		TotalHosts:            len(clusterHosts),
+		TotalClusters:         1,
+		TotalDatacenters:      1,
+		VmsPerCluster:         []int{len(clusterVMs)},
+	}
+}
+
+// newInventoryData creates an inventory with empty VM aggregates and the given infrastructure.
+func newInventoryData(vms []vspheremodel.VM, infra models.InfrastructureData) *apiplanner.InventoryData {
+	return &apiplanner.InventoryData{
+		Vms: apiplanner.VMs{
+			Total:                len(vms),
+			PowerStates:          map[string]int{},
+			OsInfo:               &map[string]apiplanner.OsInfo{},
+			DiskSizeTier:         &map[string]apiplanner.DiskSizeTierSummary{},
+			DiskTypes:            &map[string]apiplanner.DiskTypeSummary{},
+			MigrationWarnings:    apiplanner.MigrationIssues{},
+			NotMigratableReasons: apiplanner.MigrationIssues{},
+			CpuCores:             apiplanner.VMResourceBreakdown{Histogram: apiplanner.Histogram{Data: []int{}}},
+			RamGB:                apiplanner.VMResourceBreakdown{Histogram: apiplanner.Histogram{Data: []int{}}},
+			DiskCount:            apiplanner.VMResourceBreakdown{Histogram: apiplanner.Histogram{Data: []int{}}},
+			DiskGB:               apiplanner.VMResourceBreakdown{Histogram: apiplanner.Histogram{Data: []int{}}},
+			NicCount:             &apiplanner.VMResourceBreakdown{Histogram: apiplanner.Histogram{Data: []int{}}},
+		},
+		Infra: apiplanner.Infra{
+			ClustersPerDatacenter: &infra.ClustersPerDatacenter,
+			Datastores:            infra.Datastores,
+			HostPowerStates:       infra.HostPowerStates,
+			Hosts:                 infra.Hosts,
+			HostsPerCluster:       &infra.HostsPerCluster,
+			Networks:              infra.Networks,
+			TotalClusters:         &infra.TotalClusters,
+			TotalDatacenters:      &infra.TotalDatacenters,
+			TotalHosts:            infra.TotalHosts,
+			VmsPerCluster:         &infra.VmsPerCluster,
+		},
+	}
+}
+
+// fillVMsData aggregates VM resources, power states, OS and migration concerns into inv.
+func fillVMsData(vms []vspheremodel.VM, inv *apiplanner.InventoryData, datastoreTypes map[string]string) {
+	var cpuSet, memorySet, diskGBSet, diskCountSet, nicCountSet []int
+	allocatedVCpus := 0
+
+	for _, vm := range vms {
+		cpu := int(vm.CpuCount)
+		ramGB := mbToGB(vm.MemoryMB)
+		diskGB := totalCapacityGB(vm.Disks)
+		diskCount := len(vm.Disks)
+		nicCount := len(vm.NICs)
+
+		cpuSet = append(cpuSet, cpu)
+		memorySet = append(memorySet, ramGB)
+		diskGBSet = append(diskGBSet, diskGB)
+		diskCountSet = append(diskCountSet, diskCount)
+		nicCountSet = append(nicCountSet, nicCount)
+
+		if vm.PowerState == "poweredOn" {
+			allocatedVCpus += cpu
+		}
+
+		inv.Vms.PowerStates[vm.PowerState]++
+		updateOsInfo(vm, *inv.Vms.OsInfo)
+		updateDiskTypes(vm, *inv.Vms.DiskTypes, datastoreTypes)
+
+		breakdowns := []*apiplanner.VMResourceBreakdown{&inv.Vms.CpuCores, &inv.Vms.RamGB, &inv.Vms.DiskCount, &inv.Vms.DiskGB, inv.Vms.NicCount}
+		values := []int{cpu, ramGB, diskCount, diskGB, nicCount}
+
+		migratable, hasWarning := migrationReport(vm.Concerns, inv)
+		for i, b := range breakdowns {
+			b.Total += values[i]
+			switch {
+			case !migratable:
+				b.TotalForNotMigratable += values[i]
+			case hasWarning:
+				b.TotalForMigratableWithWarnings += values[i]
+			default:
+				b.TotalForMigratable += values[i]
+			}
+		}
+	}
+
+	inv.Vms.CpuCores.Histogram = histogram(cpuSet)
+	inv.Vms.RamGB.Histogram = histogram(memorySet)
+	inv.Vms.DiskCount.Histogram = histogram(diskCountSet)
+	inv.Vms.DiskGB.Histogram = histogram(diskGBSet)
+	inv.Vms.NicCount.Histogram = histogram(nicCountSet)
+	inv.Vms.DiskSizeTier = diskSizeTier(diskGBSet)
+
+	totalCpus := 0
+	if inv.Infra.Hosts != nil {
+		for _, h := range *inv.Infra.Hosts {
+			if h.CpuCores != nil {
+				totalCpus += *h.CpuCores
+			}
+		}
+	}
+	if totalCpus > 0 {
+		ratio := round(float64(allocatedVCpus) / float64(totalCpus))
+		inv.Infra.CpuOverCommitment = &ratio
+	}
+}
+
+// migrationReport records the VM concerns in inv and reports whether the VM is migratable
+// and whether it has warnings.
+func migrationReport(concerns []vspheremodel.Concern, inv *apiplanner.InventoryData) (migratable bool, hasWarning bool) {
+	migratable = true
+	for _, concern := range concerns {
+		switch concern.Category {
+		case "Critical":
+			migratable = false
+			inv.Vms.NotMigratableReasons = addMigrationIssue(inv.Vms.NotMigratableReasons, concern)
+		case "Warning":
+			hasWarning = true
+			inv.Vms.MigrationWarnings = addMigrationIssue(inv.Vms.MigrationWarnings, concern)
+		}
+	}
+
+	if hasWarning {
+		if inv.Vms.TotalMigratableWithWarnings == nil {
+			inv.Vms.TotalMigratableWithWarnings = new(int)
+		}
+		*inv.Vms.TotalMigratableWithWarnings++
+	}
+	if migratable {
+		inv.Vms.TotalMigratable++
+	}
+	return migratable, hasWarning
+}
+
+func addMigrationIssue(issues apiplanner.MigrationIssues, concern vspheremodel.Concern) apiplanner.MigrationIssues {
+	for i := range issues {
+		if issues[i].Id != nil && *issues[i].Id == concern.Id {
+			issues[i].Count++
+			return issues
+		}
+	}
+
+	id := concern.Id
+	return append(issues, apiplanner.MigrationIssue{
+		Id:         &id,
+		Label:      concern.Label,
+		Assessment: concern.Assessment,
+		Count:      1,
+	})
+}
+
+func updateOsInfo(vm vspheremodel.VM, osInfo map[string]apiplanner.OsInfo) {
+	name := vm.GuestNameFromVmwareTools
+	if name == "" {
+		name = vm.GuestName
+	}
+
+	info, found := osInfo[name]
+	if !found || info.Supported {
+		info.Supported = true
+		for _, c := range vm.Concerns {
+			if c.Id == "vmware.os.unsupported" {
+				info.Supported = false
+			}
+		}
+	}
+	info.Count++
+
+	if !info.Supported && info.UpgradeRecommendation == nil {
+		for _, c := range vm.Concerns {
+			if c.Id == "vmware.os.upgrade.recommendation" {
+				assessment := c.Assessment
+				info.UpgradeRecommendation = &assessment
+				break
+			}
+		}
+	}
+
+	osInfo[name] = info
+}
+
+func updateDiskTypes(vm vspheremodel.VM, summary map[string]apiplanner.DiskTypeSummary, datastoreTypes map[string]string) {
+	seen := make(map[string]bool)
+	for _, disk := range vm.Disks {
+		diskType := datastoreTypes[disk.Datastore.ID]
+		if diskType == "" {
+			continue
+		}
+
+		s := summary[diskType]
+		if !seen[diskType] {
+			s.VmCount++
+			seen[diskType] = true
+		}
+		s.TotalSizeTB = round(s.TotalSizeTB + float64(disk.Capacity)/math.Pow(1024, 4))
+		summary[diskType] = s
+	}
+}
+
+func diskSizeTier(diskGBSet []int) *map[string]apiplanner.DiskSizeTierSummary {
+	result := make(map[string]apiplanner.DiskSizeTierSummary)
+	for _, diskGB := range diskGBSet {
+		diskTB := float64(diskGB) / 1024
+
+		var tier string
+		switch {
+		case diskTB < 10:
+			tier = "Easy (0-10TB)"
+		case diskTB < 20:
+			tier = "Medium (10-20TB)"
+		case diskTB < 50:
+			tier = "Hard (20-50TB)"
+		default:
+			tier = "White Glove (>50TB)"
+		}
+
+		s := result[tier]
+		s.TotalSizeTB = round(s.TotalSizeTB + diskTB)
+		s.VmCount++
+		result[tier] = s
+	}
+	return &result
+}
+
+// histogram distributes the values into sqrt(n) bins, or one bin per value for small ranges.
+func histogram(values []int) apiplanner.Histogram {
+	if len(values) == 0 {
+		return apiplanner.Histogram{Data: []int{}}
+	}
+
+	minVal := slices.Min(values)
+	maxVal := slices.Max(values)
+	rangeValues := maxVal - minVal
+
+	numberOfBins := max(int(math.Sqrt(float64(len(values)))), 1)
+	binSize := 1.0
+	switch {
+	case maxVal <= 10:
+		numberOfBins = rangeValues + 1
+	case rangeValues > 0:
+		binSize = float64(rangeValues) / float64(numberOfBins)
+	}
+
+	bins := make([]int, numberOfBins)
+	for _, v := range values {
+		idx := 0
+		if rangeValues > 0 {
+			idx = min(int(float64(v-minVal)/binSize), numberOfBins-1)
+		}
+		bins[idx]++
+	}
+
+	return apiplanner.Histogram{
+		Data:     bins,
+		Step:     max(int(math.Round(binSize)), 1),
+		MinValue: minVal,
+	}
+}
+
+func totalCapacityGB(disks []vspheremodel.Disk) int {
+	var total int64
+	for _, d := range disks {
+		total += d.Capacity
+	}
+	return bytesToGB(total)
+}
+
+func bytesToGB(bytes int64) int {
+	return int(math.Round(float64(bytes) / math.Pow(1024, 3)))
+}
+
+func mbToGB(mb int32) int {
+	return int(math.Round(float64(mb) / 1024))
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
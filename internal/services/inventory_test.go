@@ -0,0 +1,146 @@
+package services_test
+
+import (
+	"path/filepath"
+
+	vspheremodel "github.com/kubev2v/forklift/pkg/controller/provider/model/vsphere"
+	libmodel "github.com/kubev2v/forklift/pkg/lib/inventory/model"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+)
+
+var _ = Describe("InventoryBuilder", func() {
+	var db libmodel.DB
+
+	BeforeEach(func() {
+		db = libmodel.New(filepath.Join(GinkgoT().TempDir(), "vsphere.db"), vspheremodel.All()...)
+		Expect(db.Open(true)).To(Succeed())
+
+		objects := []libmodel.Model{
+			&vspheremodel.About{InstanceUuid: "vcenter-uuid"},
+			&vspheremodel.Folder{
+				Base:     vspheremodel.Base{ID: "group-h1"},
+				Children: []vspheremodel.Ref{{Kind: vspheremodel.ClusterKind, ID: "domain-c1"}},
+			},
+			&vspheremodel.Datacenter{
+				Base:     vspheremodel.Base{ID: "datacenter-1"},
+				Clusters: vspheremodel.Ref{Kind: vspheremodel.FolderKind, ID: "group-h1"},
+			},
+			&vspheremodel.Cluster{Base: vspheremodel.Base{ID: "domain-c1", Name: "cluster1"}},
+			&vspheremodel.Host{
+				Base:        vspheremodel.Base{ID: "host-1"},
+				Cluster:     "domain-c1",
+				Status:      "green",
+				CpuCores:    16,
+				CpuSockets:  2,
+				MemoryBytes: 64 * 1024 * 1024 * 1024,
+				Datastores:  []vspheremodel.Ref{{ID: "datastore-1"}},
+			},
+			&vspheremodel.Host{
+				Base:    vspheremodel.Base{ID: "host-2"},
+				Status:  "yellow",
+				Cluster: "",
+			},
+			&vspheremodel.Datastore{
+				Base:     vspheremodel.Base{ID: "datastore-1", Name: "ds1"},
+				Type:     "VMFS",
+				Capacity: 100 * 1024 * 1024 * 1024,
+				Free:     50 * 1024 * 1024 * 1024,
+			},
+			&vspheremodel.Network{
+				Base: vspheremodel.Base{ID: "network-1", Name: "VM Network", Variant: vspheremodel.NetStandard},
+			},
+			&vspheremodel.VM{
+				Base:       vspheremodel.Base{ID: "vm-1", Name: "vm1"},
+				Host:       "host-1",
+				PowerState: "poweredOn",
+				CpuCount:   4,
+				MemoryMB:   8192,
+				GuestName:  "Red Hat Enterprise Linux 9",
+				Disks:      []vspheremodel.Disk{{Datastore: vspheremodel.Ref{ID: "datastore-1"}, Capacity: 20 * 1024 * 1024 * 1024}},
+				NICs:       []vspheremodel.NIC{{Network: vspheremodel.Ref{ID: "network-1"}}},
+				Networks:   []vspheremodel.Ref{{ID: "network-1"}},
+			},
+			&vspheremodel.VM{
+				Base:       vspheremodel.Base{ID: "vm-2", Name: "vm2"},
+				Host:       "host-2",
+				PowerState: "poweredOff",
+				CpuCount:   2,
+				MemoryMB:   2048,
+				Concerns:   []vspheremodel.Concern{{Id: "vmware.disk.rdm", Label: "RDM", Category: "Critical"}},
+			},
+			&vspheremodel.VM{
+				Base:       vspheremodel.Base{ID: "vm-3", Name: "template"},
+				Host:       "host-1",
+				IsTemplate: true,
+			},
+		}
+		for _, o := range objects {
+			Expect(db.Insert(o)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		_ = db.Close(true)
+	})
+
+	It("should build the vCenter inventory", func() {
+		inv, err := services.NewInventoryBuilder(db).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inv.VcenterId).To(Equal("vcenter-uuid"))
+		Expect(inv.Vcenter).NotTo(BeNil())
+
+		vcenter := inv.Vcenter
+		Expect(vcenter.Vms.Total).To(Equal(2))
+		Expect(vcenter.Vms.TotalMigratable).To(Equal(1))
+		Expect(vcenter.Vms.PowerStates).To(HaveKeyWithValue("poweredOn", 1))
+		Expect(vcenter.Vms.PowerStates).To(HaveKeyWithValue("poweredOff", 1))
+		Expect(vcenter.Vms.CpuCores.Total).To(Equal(6))
+		Expect(vcenter.Vms.RamGB.Total).To(Equal(10))
+		Expect(vcenter.Vms.NotMigratableReasons).To(HaveLen(1))
+
+		Expect(vcenter.Infra.TotalHosts).To(Equal(2))
+		Expect(*vcenter.Infra.TotalDatacenters).To(Equal(1))
+		Expect(*vcenter.Infra.TotalClusters).To(Equal(1))
+		Expect(*vcenter.Infra.ClustersPerDatacenter).To(Equal([]int{1}))
+		Expect(vcenter.Infra.HostPowerStates).To(HaveKeyWithValue("green", 1))
+		Expect(vcenter.Infra.Datastores).To(HaveLen(1))
+		Expect(vcenter.Infra.Datastores[0].TotalCapacityGB).To(Equal(100))
+		Expect(vcenter.Infra.Networks).To(HaveLen(1))
+		Expect(*vcenter.Infra.Networks[0].VmsCount).To(Equal(1))
+	})
+
+	It("should build one inventory per cluster", func() {
+		inv, err := services.NewInventoryBuilder(db).Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inv.Clusters).To(HaveLen(1))
+		Expect(inv.Clusters).To(HaveKey("domain-c1"))
+
+		cluster := inv.Clusters["domain-c1"]
+		Expect(cluster.Vms.Total).To(Equal(1))
+		Expect(cluster.Infra.TotalHosts).To(Equal(1))
+		Expect(cluster.Infra.Datastores).To(HaveLen(1))
+		Expect(cluster.Infra.Networks).To(HaveLen(1))
+		Expect(*cluster.Infra.CpuOverCommitment).To(Equal(0.25))
+	})
+
+	It("should summarize each vm", func() {
+		vms, err := services.NewInventoryBuilder(db).VMs()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(vms).To(HaveLen(2))
+		Expect(vms[0].ID).To(Equal("vm-1"))
+		Expect(vms[0].CpuCount).To(Equal(4))
+		Expect(vms[0].MemoryMB).To(Equal(8192))
+		Expect(vms[0].DisksGB).To(Equal([]int{20}))
+		Expect(vms[0].Networks).To(Equal([]string{"network-1"}))
+		Expect(vms[0].Datastores).To(Equal([]string{"datastore-1"}))
+		Expect(vms[0].Host).To(Equal("host-1"))
+		Expect(vms[1].ID).To(Equal("vm-2"))
+		Expect(vms[1].PowerState).To(Equal("poweredOff"))
+	})
+})
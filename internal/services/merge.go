@@ -0,0 +1,261 @@
+package services
+
+import (
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+)
+
+// InventorySource is the inventory of one vCenter to merge.
+// The clusters of a named source are keyed by "<name>/<cluster>" in the merged inventory,
+// so the clusters of different vCenters do not collide.
+type InventorySource struct {
+	Name      string
+	Inventory *apiplanner.Inventory
+}
+
+// MergeInventories aggregates the inventories of several vCenters into one inventory.
+// The vCenter-level view sums up the vCenter views of the sources and the vCenter id is the one of the first source.
+//
+// The histograms are rebuilt from the bins of the sources: each VM is counted at the lower bound of its bin,
+// so the merged histograms are exact only for the sources with one bin per value.
+func MergeInventories(sources []InventorySource) *apiplanner.Inventory {
+	merged := &apiplanner.Inventory{
+		Clusters: make(map[string]apiplanner.InventoryData),
+	}
+
+	var vcenters []apiplanner.InventoryData
+	for _, source := range sources {
+		if merged.VcenterId == "" {
+			merged.VcenterId = source.Inventory.VcenterId
+		}
+		for id, cluster := range source.Inventory.Clusters {
+			if source.Name != "" {
+				id = source.Name + "/" + id
+			}
+			merged.Clusters[id] = cluster
+		}
+		if source.Inventory.Vcenter != nil {
+			vcenters = append(vcenters, *source.Inventory.Vcenter)
+		}
+	}
+
+	if len(vcenters) > 0 {
+		merged.Vcenter = &apiplanner.InventoryData{
+			Infra: mergeInfra(vcenters),
+			Vms:   mergeVMs(vcenters),
+		}
+	}
+
+	return merged
+}
+
+// mergeInfra sums up the infrastructure of the inventories.
+// The CPU overcommitment is weighted by the physical cores of each inventory.
+func mergeInfra(data []apiplanner.InventoryData) apiplanner.Infra {
+	var (
+		clustersPerDatacenter, hostsPerCluster, vmsPerCluster []int
+		hosts                                                 []apiplanner.Host
+		totalClusters, totalDatacenters, totalCores           int
+		allocatedVCpus                                        float64
+		hasOverCommitment                                     bool
+	)
+
+	infra := apiplanner.Infra{
+		Datastores:      []apiplanner.Datastore{},
+		Networks:        []apiplanner.Network{},
+		HostPowerStates: map[string]int{},
+	}
+
+	for _, d := range data {
+		i := d.Infra
+		infra.Datastores = append(infra.Datastores, i.Datastores...)
+		infra.Networks = append(infra.Networks, i.Networks...)
+		infra.TotalHosts += i.TotalHosts
+		for state, count := range i.HostPowerStates {
+			infra.HostPowerStates[state] += count
+		}
+
+		clustersPerDatacenter = appendValues(clustersPerDatacenter, i.ClustersPerDatacenter)
+		hostsPerCluster = appendValues(hostsPerCluster, i.HostsPerCluster)
+		vmsPerCluster = appendValues(vmsPerCluster, i.VmsPerCluster)
+		if i.TotalClusters != nil {
+			totalClusters += *i.TotalClusters
+		}
+		if i.TotalDatacenters != nil {
+			totalDatacenters += *i.TotalDatacenters
+		}
+
+		cores := 0
+		if i.Hosts != nil {
+			hosts = append(hosts, *i.Hosts...)
+			for _, h := range *i.Hosts {
+				if h.CpuCores != nil {
+					cores += *h.CpuCores
+				}
+			}
+		}
+		if i.CpuOverCommitment != nil {
+			hasOverCommitment = true
+			allocatedVCpus += *i.CpuOverCommitment * float64(cores)
+		}
+		totalCores += cores
+	}
+
+	infra.ClustersPerDatacenter = &clustersPerDatacenter
+	infra.HostsPerCluster = &hostsPerCluster
+	infra.VmsPerCluster = &vmsPerCluster
+	infra.Hosts = &hosts
+	infra.TotalClusters = &totalClusters
+	infra.TotalDatacenters = &totalDatacenters
+	if hasOverCommitment && totalCores > 0 {
+		ratio := round(allocatedVCpus / float64(totalCores))
+		infra.CpuOverCommitment = &ratio
+	}
+
+	return infra
+}
+
+// mergeVMs sums up the VM aggregates of the inventories.
+func mergeVMs(data []apiplanner.InventoryData) apiplanner.VMs {
+	vms := apiplanner.VMs{
+		PowerStates:          map[string]int{},
+		OsInfo:               &map[string]apiplanner.OsInfo{},
+		DiskSizeTier:         &map[string]apiplanner.DiskSizeTierSummary{},
+		DiskTypes:            &map[string]apiplanner.DiskTypeSummary{},
+		MigrationWarnings:    apiplanner.MigrationIssues{},
+		NotMigratableReasons: apiplanner.MigrationIssues{},
+	}
+
+	var cpuCores, ramGB, diskCount, diskGB, nicCount []apiplanner.VMResourceBreakdown
+	for _, d := range data {
+		v := d.Vms
+		vms.Total += v.Total
+		vms.TotalMigratable += v.TotalMigratable
+		if v.TotalMigratableWithWarnings != nil {
+			if vms.TotalMigratableWithWarnings == nil {
+				vms.TotalMigratableWithWarnings = new(int)
+			}
+			*vms.TotalMigratableWithWarnings += *v.TotalMigratableWithWarnings
+		}
+		for state, count := range v.PowerStates {
+			vms.PowerStates[state] += count
+		}
+
+		if v.Os != nil {
+			if vms.Os == nil {
+				vms.Os = &map[string]int{}
+			}
+			for name, count := range *v.Os {
+				(*vms.Os)[name] += count
+			}
+		}
+		if v.OsInfo != nil {
+			mergeOsInfo(*vms.OsInfo, *v.OsInfo)
+		}
+		if v.DiskSizeTier != nil {
+			for tier, s := range *v.DiskSizeTier {
+				merged := (*vms.DiskSizeTier)[tier]
+				merged.VmCount += s.VmCount
+				merged.TotalSizeTB = round(merged.TotalSizeTB + s.TotalSizeTB)
+				(*vms.DiskSizeTier)[tier] = merged
+			}
+		}
+		if v.DiskTypes != nil {
+			for diskType, s := range *v.DiskTypes {
+				merged := (*vms.DiskTypes)[diskType]
+				merged.VmCount += s.VmCount
+				merged.TotalSizeTB = round(merged.TotalSizeTB + s.TotalSizeTB)
+				(*vms.DiskTypes)[diskType] = merged
+			}
+		}
+
+		vms.MigrationWarnings = mergeMigrationIssues(vms.MigrationWarnings, v.MigrationWarnings)
+		vms.NotMigratableReasons = mergeMigrationIssues(vms.NotMigratableReasons, v.NotMigratableReasons)
+
+		cpuCores = append(cpuCores, v.CpuCores)
+		ramGB = append(ramGB, v.RamGB)
+		diskCount = append(diskCount, v.DiskCount)
+		diskGB = append(diskGB, v.DiskGB)
+		if v.NicCount != nil {
+			nicCount = append(nicCount, *v.NicCount)
+		}
+	}
+
+	vms.CpuCores = mergeBreakdowns(cpuCores)
+	vms.RamGB = mergeBreakdowns(ramGB)
+	vms.DiskCount = mergeBreakdowns(diskCount)
+	vms.DiskGB = mergeBreakdowns(diskGB)
+	nics := mergeBreakdowns(nicCount)
+	vms.NicCount = &nics
+
+	return vms
+}
+
+// mergeOsInfo adds the OS counts of src to dst. An OS is supported only when it is supported in every source.
+func mergeOsInfo(dst, src map[string]apiplanner.OsInfo) {
+	for name, info := range src {
+		merged, found := dst[name]
+		if !found {
+			dst[name] = info
+			continue
+		}
+		merged.Count += info.Count
+		merged.Supported = merged.Supported && info.Supported
+		if merged.UpgradeRecommendation == nil {
+			merged.UpgradeRecommendation = info.UpgradeRecommendation
+		}
+		dst[name] = merged
+	}
+}
+
+// mergeMigrationIssues adds the issues of src to dst, summing up the counts of the issues with the same id.
+func mergeMigrationIssues(dst, src apiplanner.MigrationIssues) apiplanner.MigrationIssues {
+	for _, issue := range src {
+		found := false
+		for i := range dst {
+			if sameMigrationIssue(dst[i], issue) {
+				dst[i].Count += issue.Count
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, issue)
+		}
+	}
+	return dst
+}
+
+func sameMigrationIssue(a, b apiplanner.MigrationIssue) bool {
+	if a.Id != nil && b.Id != nil {
+		return *a.Id == *b.Id
+	}
+	return a.Id == nil && b.Id == nil && a.Label == b.Label
+}
+
+// mergeBreakdowns sums up the totals of the breakdowns and rebuilds the histogram from their bins.
+func mergeBreakdowns(breakdowns []apiplanner.VMResourceBreakdown) apiplanner.VMResourceBreakdown {
+	var merged apiplanner.VMResourceBreakdown
+	var values []int
+	for _, b := range breakdowns {
+		merged.Total += b.Total
+		merged.TotalForMigratable += b.TotalForMigratable
+		merged.TotalForMigratableWithWarnings += b.TotalForMigratableWithWarnings
+		merged.TotalForNotMigratable += b.TotalForNotMigratable
+
+		for i, count := range b.Histogram.Data {
+			value := b.Histogram.MinValue + i*b.Histogram.Step
+			for range count {
+				values = append(values, value)
+			}
+		}
+	}
+	merged.Histogram = histogram(values)
+	return merged
+}
+
+func appendValues(dst []int, src *[]int) []int {
+	if src == nil {
+		return dst
+	}
+	return append(dst, *src...)
+}
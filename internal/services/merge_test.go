@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+)
+
+var _ = Describe("MergeInventories", func() {
+	// newInventory returns the inventory of a vCenter with one cluster, one host and the given VM cpu counts.
+	newInventory := func(id string, hostCores int, overCommitment float64, cpus ...int) *apiplanner.Inventory {
+		data := apiplanner.InventoryData{
+			Infra: apiplanner.Infra{
+				Datastores:            []apiplanner.Datastore{{DiskId: id + "-disk"}},
+				Networks:              []apiplanner.Network{{Name: id + "-net"}},
+				HostPowerStates:       map[string]int{"green": 1},
+				Hosts:                 &[]apiplanner.Host{{CpuCores: &hostCores}},
+				TotalHosts:            1,
+				TotalClusters:         new(int),
+				TotalDatacenters:      new(int),
+				ClustersPerDatacenter: &[]int{1},
+				CpuOverCommitment:     &overCommitment,
+			},
+			Vms: apiplanner.VMs{
+				Total:           len(cpus),
+				TotalMigratable: len(cpus),
+				PowerStates:     map[string]int{"poweredOn": len(cpus)},
+				OsInfo:          &map[string]apiplanner.OsInfo{"RHEL 9": {Count: len(cpus), Supported: true}},
+				MigrationWarnings: apiplanner.MigrationIssues{
+					{Id: new(string), Label: "Warning", Count: len(cpus)},
+				},
+				NotMigratableReasons: apiplanner.MigrationIssues{},
+				CpuCores:             apiplanner.VMResourceBreakdown{Histogram: apiplanner.Histogram{Data: make([]int, 8), Step: 1, MinValue: 1}},
+			},
+		}
+		*data.Infra.TotalClusters = 1
+		*data.Infra.TotalDatacenters = 1
+		for _, cpu := range cpus {
+			data.Vms.CpuCores.Total += cpu
+			data.Vms.CpuCores.Histogram.Data[cpu-1]++
+		}
+
+		return &apiplanner.Inventory{
+			VcenterId: id,
+			Vcenter:   &data,
+			Clusters:  map[string]apiplanner.InventoryData{"domain-c1": data},
+		}
+	}
+
+	It("should key the clusters of the named vCenters by their name", func() {
+		merged := services.MergeInventories([]services.InventorySource{
+			{Inventory: newInventory("primary", 8, 1, 2)},
+			{Name: "east", Inventory: newInventory("east", 8, 1, 2)},
+		})
+
+		Expect(merged.VcenterId).To(Equal("primary"))
+		Expect(merged.Clusters).To(HaveLen(2))
+		Expect(merged.Clusters).To(HaveKey("domain-c1"))
+		Expect(merged.Clusters).To(HaveKey("east/domain-c1"))
+	})
+
+	It("should sum up the vCenter views", func() {
+		merged := services.MergeInventories([]services.InventorySource{
+			{Inventory: newInventory("primary", 8, 1, 2, 4)},
+			{Name: "east", Inventory: newInventory("east", 24, 0.5, 4)},
+		})
+
+		infra := merged.Vcenter.Infra
+		Expect(infra.TotalHosts).To(Equal(2))
+		Expect(*infra.TotalClusters).To(Equal(2))
+		Expect(*infra.TotalDatacenters).To(Equal(2))
+		Expect(*infra.ClustersPerDatacenter).To(Equal([]int{1, 1}))
+		Expect(infra.HostPowerStates).To(Equal(map[string]int{"green": 2}))
+		Expect(infra.Datastores).To(HaveLen(2))
+		Expect(infra.Networks).To(HaveLen(2))
+		Expect(*infra.Hosts).To(HaveLen(2))
+		// (1 * 8 + 0.5 * 24) / 32
+		Expect(*infra.CpuOverCommitment).To(Equal(0.63))
+
+		vms := merged.Vcenter.Vms
+		Expect(vms.Total).To(Equal(3))
+		Expect(vms.TotalMigratable).To(Equal(3))
+		Expect(vms.PowerStates).To(Equal(map[string]int{"poweredOn": 3}))
+		Expect((*vms.OsInfo)["RHEL 9"].Count).To(Equal(3))
+		Expect(vms.MigrationWarnings).To(HaveLen(1))
+		Expect(vms.MigrationWarnings[0].Count).To(Equal(3))
+		Expect(vms.CpuCores.Total).To(Equal(10))
+		Expect(vms.CpuCores.Histogram).To(Equal(apiplanner.Histogram{Data: []int{1, 0, 2}, Step: 1, MinValue: 2}))
+	})
+
+	It("should not mark an OS supported when it is unsupported in a vCenter", func() {
+		unsupported := newInventory("east", 8, 1, 2)
+		(*unsupported.Vcenter.Vms.OsInfo)["RHEL 9"] = apiplanner.OsInfo{Count: 1, Supported: false}
+
+		merged := services.MergeInventories([]services.InventorySource{
+			{Inventory: newInventory("primary", 8, 1, 2)},
+			{Name: "east", Inventory: unsupported},
+		})
+
+		Expect((*merged.Vcenter.Vms.OsInfo)["RHEL 9"]).To(Equal(apiplanner.OsInfo{Count: 2, Supported: false}))
+	})
+})
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// preflightTimeout bounds the privilege check, which lists the inventory objects.
+const preflightTimeout = 30 * time.Second
+
+// requiredPrivileges are the privileges the forklift collector needs to read the inventory.
+var requiredPrivileges = []string{"System.Anonymous", "System.View", "System.Read"}
+
+// preflightEntityTypes are the inventory objects on which the required privileges are checked, along with the root folder.
+var preflightEntityTypes = []string{"Datacenter", "ClusterComputeResource", "HostSystem", "Datastore"}
+
+// runPreflight checks the logged in user has the required privileges on the inventory objects.
+func runPreflight(ctx context.Context, client *govmomi.Client) (*models.Preflight, error) {
+	session, err := client.SessionManager.UserSession(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user session: %w", err)
+	}
+	if session == nil {
+		return nil, errors.New("not logged in")
+	}
+
+	entities, err := preflightEntities(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	req := types.HasPrivilegeOnEntities{
+		This:      *client.ServiceContent.AuthorizationManager,
+		Entity:    make([]types.ManagedObjectReference, 0, len(entities)),
+		SessionId: session.Key,
+		PrivId:    requiredPrivileges,
+	}
+	for _, e := range entities {
+		req.Entity = append(req.Entity, e.Self)
+	}
+
+	res, err := methods.HasPrivilegeOnEntities(ctx, client.RoundTripper, &req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check privileges: %w", err)
+	}
+
+	names := make(map[types.ManagedObjectReference]string, len(entities))
+	for _, e := range entities {
+		names[e.Self] = e.Name
+	}
+
+	preflight := &models.Preflight{CheckedAt: time.Now()}
+	for _, result := range res.Returnval {
+		var missing []string
+		for _, p := range result.PrivAvailability {
+			if !p.IsGranted {
+				missing = append(missing, p.PrivId)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		preflight.MissingPrivileges = append(preflight.MissingPrivileges, models.MissingPrivileges{
+			Entity:     result.Entity.Value,
+			EntityType: result.Entity.Type,
+			EntityName: names[result.Entity],
+			Privileges: missing,
+		})
+	}
+
+	return preflight, nil
+}
+
+// preflightEntities returns the root folder and the inventory objects visible to the user.
+func preflightEntities(ctx context.Context, client *govmomi.Client) ([]mo.ManagedEntity, error) {
+	root := client.ServiceContent.RootFolder
+	entities := []mo.ManagedEntity{{ExtensibleManagedObject: mo.ExtensibleManagedObject{Self: root}, Name: "root"}}
+
+	v, err := view.NewManager(client.Client).CreateContainerView(ctx, root, preflightEntityTypes, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create inventory view: %w", err)
+	}
+	defer func() { _ = v.Destroy(context.Background()) }()
+
+	var found []mo.ManagedEntity
+	if err := v.Retrieve(ctx, preflightEntityTypes, []string{"name"}, &found); err != nil {
+		return nil, fmt.Errorf("failed to list inventory objects: %w", err)
+	}
+
+	return append(entities, found...), nil
+}
+
+// preflightMissingPrivileges returns the distinct privileges missing on any object.
+func preflightMissingPrivileges(preflight *models.Preflight) []string {
+	var privileges []string
+	for _, m := range preflight.MissingPrivileges {
+		for _, p := range m.Privileges {
+			if !slices.Contains(privileges, p) {
+				privileges = append(privileges, p)
+			}
+		}
+	}
+	return privileges
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// minScheduleInterval keeps re-collections from overloading vCenter.
+const minScheduleInterval = time.Minute
+
+// ParseSchedule parses a schedule given either as a duration, e.g. 6h, or as a standard cron expression.
+func ParseSchedule(spec string) (*models.CollectionSchedule, error) {
+	schedule := &models.CollectionSchedule{Cron: spec}
+	if interval, err := time.ParseDuration(spec); err == nil {
+		schedule = &models.CollectionSchedule{Interval: interval}
+	}
+	if err := ValidateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// ValidateSchedule checks exactly one of the interval and the cron expression is set, and is valid.
+func ValidateSchedule(schedule *models.CollectionSchedule) error {
+	switch {
+	case schedule.Interval != 0 && schedule.Cron != "":
+		return fmt.Errorf("%w: interval and cron are mutually exclusive", ErrInvalidSchedule)
+	case schedule.Interval != 0:
+		if schedule.Interval < minScheduleInterval {
+			return fmt.Errorf("%w: interval %s must be at least %s", ErrInvalidSchedule, schedule.Interval, minScheduleInterval)
+		}
+	case schedule.Cron != "":
+		if _, err := cron.ParseStandard(schedule.Cron); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+	default:
+		return fmt.Errorf("%w: either interval or cron must be set", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// ScheduleService triggers re-collections with the stored credentials according to the collection schedule.
+// Each run re-collects the vCenter of the collector and the named vCenters. The vCenters whose collection
+// is already in progress are left out, and the run is skipped when all of them are.
+type ScheduleService struct {
+	scheduler *scheduler.Scheduler
+	collector *CollectorService
+	// vcenters is nil when the named vCenters are not collected
+	vcenters *VCenterService
+	store    *store.Store
+
+	mu       sync.Mutex
+	schedule *models.CollectionSchedule
+	reload   chan any
+	close    chan any
+}
+
+func NewScheduleService(s *scheduler.Scheduler, collector *CollectorService, vcenters *VCenterService, st *store.Store) *ScheduleService {
+	srv := &ScheduleService{
+		scheduler: s,
+		collector: collector,
+		vcenters:  vcenters,
+		store:     st,
+		reload:    make(chan any, 1),
+		close:     make(chan any),
+	}
+
+	schedule, err := st.Schedule().Get(context.Background())
+	switch {
+	case err == nil:
+		srv.schedule = schedule
+		zap.S().Infow("collection schedule loaded", "interval", schedule.Interval, "cron", schedule.Cron)
+	case !errors.Is(err, store.ErrNotFound):
+		zap.S().Errorw("failed to load collection schedule", "error", err)
+	}
+
+	go srv.run()
+
+	return srv
+}
+
+// Get returns the schedule with its next run, store.ErrNotFound when no schedule is set.
+func (s *ScheduleService) Get() (*models.CollectionSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.schedule == nil {
+		return nil, store.ErrNotFound
+	}
+	schedule := *s.schedule
+	schedule.NextRun = s.nextRun(time.Now())
+	return &schedule, nil
+}
+
+// Set validates and persists the schedule, replacing the current one.
+// Setting the current schedule again keeps it unchanged, so its next run is not pushed back.
+func (s *ScheduleService) Set(ctx context.Context, schedule *models.CollectionSchedule) (*models.CollectionSchedule, error) {
+	if err := ValidateSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	unchanged := s.schedule != nil && s.schedule.Interval == schedule.Interval && s.schedule.Cron == schedule.Cron
+	s.mu.Unlock()
+	if unchanged {
+		return s.Get()
+	}
+
+	if err := s.store.Schedule().Save(ctx, schedule); err != nil {
+		return nil, err
+	}
+	saved, err := s.store.Schedule().Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.schedule = saved
+	s.mu.Unlock()
+	s.notify()
+
+	zap.S().Infow("collection schedule set", "interval", saved.Interval, "cron", saved.Cron)
+	return s.Get()
+}
+
+// Delete removes the schedule. Running collections are not stopped.
+func (s *ScheduleService) Delete(ctx context.Context) error {
+	if err := s.store.Schedule().Delete(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.schedule = nil
+	s.mu.Unlock()
+	s.notify()
+
+	zap.S().Info("collection schedule removed")
+	return nil
+}
+
+// Close stops the schedule loop.
+func (s *ScheduleService) Close() {
+	s.close <- struct{}{}
+}
+
+func (s *ScheduleService) notify() {
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+// run waits for the next run of the schedule, and triggers it.
+// The wait is recomputed whenever the schedule changes.
+func (s *ScheduleService) run() {
+	for {
+		var (
+			timer *time.Timer
+			fire  <-chan time.Time
+		)
+
+		s.mu.Lock()
+		if s.schedule != nil {
+			timer = time.NewTimer(time.Until(s.nextRun(time.Now())))
+			fire = timer.C
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-fire:
+			s.trigger()
+		case <-s.reload:
+		case <-s.close:
+			zap.S().Debug("schedule loop stopped")
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// nextRun returns the next run of the schedule. It must be called with the lock held.
+// An interval run is due an interval after the last run, or after the schedule was set when it never ran,
+// so restarting the agent does not push it back. A run missed while the agent was stopped runs right away.
+func (s *ScheduleService) nextRun(now time.Time) time.Time {
+	if s.schedule.Cron != "" {
+		// the expression was validated when the schedule was set
+		schedule, err := cron.ParseStandard(s.schedule.Cron)
+		if err != nil {
+			return time.Time{}
+		}
+		return schedule.Next(now)
+	}
+
+	base := s.schedule.UpdatedAt
+	if s.schedule.LastRun != nil && s.schedule.LastRun.StartedAt.After(base) {
+		base = s.schedule.LastRun.StartedAt
+	}
+	if next := base.Add(s.schedule.Interval); next.After(now) {
+		return next
+	}
+	return now
+}
+
+// trigger starts the re-collections in the scheduler and records their outcome.
+func (s *ScheduleService) trigger() {
+	startedAt := time.Now()
+
+	// the next run is computed from this one, before the job records it
+	s.mu.Lock()
+	if s.schedule != nil {
+		s.schedule.LastRun = &models.ScheduledRun{StartedAt: startedAt, Status: models.ScheduledRunStatusRunning}
+	}
+	s.mu.Unlock()
+
+	s.scheduler.AddWork(func(ctx context.Context) (any, error) {
+		run := models.ScheduledRun{StartedAt: startedAt, Status: models.ScheduledRunStatusRunning}
+
+		jobs, err := s.recollect(ctx)
+		switch {
+		case len(jobs) == 0 && err == nil:
+			zap.S().Info("collections in progress, skipping scheduled collection")
+			run.Status = models.ScheduledRunStatusSkipped
+		case len(jobs) == 0:
+			zap.S().Errorw("failed to start scheduled collection", "error", err)
+			run.Status = models.ScheduledRunStatusFailed
+			run.Error = err.Error()
+		default:
+			zap.S().Infow("scheduled collection started", "vcenters", len(jobs))
+		}
+
+		s.recordRun(run)
+		if len(jobs) > 0 {
+			go s.await(jobs, err, run)
+		}
+		return nil, err
+	})
+}
+
+// recollect starts the collection of every vCenter and returns the collection jobs by vCenter name,
+// the empty name being the vCenter of the collector. The errors are prefixed with the name of the vCenter.
+func (s *ScheduleService) recollect(ctx context.Context) (map[string]*models.Future[models.Result[any]], error) {
+	collectors := map[string]*CollectorService{"": s.collector}
+	if s.vcenters != nil {
+		maps.Copy(collectors, s.vcenters.Collectors())
+	}
+
+	jobs := make(map[string]*models.Future[models.Result[any]])
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(collectors)) {
+		job, err := collectors[name].Recollect(ctx)
+		switch {
+		case errors.Is(err, ErrCollectionInProgress):
+			zap.S().Infow("collection in progress, leaving it out of the scheduled collection", "vcenter", name)
+		// the agent may only collect named vCenters
+		case name == "" && errors.Is(err, store.ErrNotFound) && len(collectors) > 1:
+			zap.S().Debug("no credentials, leaving the collector vCenter out of the scheduled collection")
+		case err != nil:
+			errs = append(errs, vcenterError(name, err))
+		default:
+			jobs[name] = job
+		}
+	}
+
+	return jobs, errors.Join(errs...)
+}
+
+// await records the outcome of the collection jobs once they are all done, along with the errors
+// of the collections which did not start. The run fails when any collection failed.
+func (s *ScheduleService) await(jobs map[string]*models.Future[models.Result[any]], startErr error, run models.ScheduledRun) {
+	errs := []error{startErr}
+	cancelled := false
+	for _, name := range slices.Sorted(maps.Keys(jobs)) {
+		job := jobs[name]
+		<-job.Done()
+
+		result := job.Result()
+		switch {
+		case errors.Is(result.Err, context.Canceled):
+			cancelled = true
+		case result.Err != nil:
+			errs = append(errs, vcenterError(name, result.Err))
+		}
+	}
+
+	switch err := errors.Join(errs...); {
+	case err != nil:
+		run.Status = models.ScheduledRunStatusFailed
+		run.Error = err.Error()
+	case cancelled:
+		run.Status = models.ScheduledRunStatusCancelled
+	default:
+		run.Status = models.ScheduledRunStatusSucceeded
+	}
+
+	zap.S().Infow("scheduled collection finished", "status", run.Status, "error", run.Error)
+	s.recordRun(run)
+}
+
+// vcenterError prefixes the error with the name of the vCenter, unless it is the vCenter of the collector.
+func vcenterError(name string, err error) error {
+	if name == "" {
+		return err
+	}
+	return fmt.Errorf("vcenter %q: %w", name, err)
+}
+
+func (s *ScheduleService) recordRun(run models.ScheduledRun) {
+	s.mu.Lock()
+	if s.schedule != nil {
+		s.schedule.LastRun = &run
+	}
+	s.mu.Unlock()
+
+	if err := s.store.Schedule().RecordRun(context.Background(), run); err != nil {
+		zap.S().Errorw("failed to record scheduled collection", "error", err)
+	}
+}
@@ -0,0 +1,244 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/simulator"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+)
+
+var _ = Describe("ParseSchedule", func() {
+	It("should parse an interval", func() {
+		schedule, err := services.ParseSchedule("6h")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.Interval).To(Equal(6 * time.Hour))
+		Expect(schedule.Cron).To(BeEmpty())
+	})
+
+	It("should parse a cron expression", func() {
+		schedule, err := services.ParseSchedule("0 2 * * *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.Interval).To(BeZero())
+		Expect(schedule.Cron).To(Equal("0 2 * * *"))
+	})
+
+	It("should reject an interval shorter than a minute", func() {
+		_, err := services.ParseSchedule("30s")
+		Expect(err).To(MatchError(services.ErrInvalidSchedule))
+	})
+
+	It("should reject an invalid cron expression", func() {
+		_, err := services.ParseSchedule("every day")
+		Expect(err).To(MatchError(services.ErrInvalidSchedule))
+	})
+})
+
+var _ = Describe("ScheduleService", func() {
+	var (
+		ctx       context.Context
+		sched     *scheduler.Scheduler
+		db        *sql.DB
+		st        *store.Store
+		collector *services.CollectorService
+		schedule  *services.ScheduleService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		// a scheduled run may wait for a collection in progress
+		sched = scheduler.NewScheduler(2)
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrations.Run(ctx, db)).To(Succeed())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		st = store.NewStore(db, encryption.NewKeyring(key))
+
+		collector = services.NewCollectorService(sched, st, config.Agent{
+			DataFolder:       GinkgoT().TempDir(),
+			CollectorTimeout: time.Minute,
+		})
+		schedule = services.NewScheduleService(sched, collector, nil, st)
+	})
+
+	AfterEach(func() {
+		schedule.Close()
+		sched.Close()
+		_ = db.Close()
+	})
+
+	It("should return ErrNotFound when no schedule is set", func() {
+		_, err := schedule.Get()
+		Expect(err).To(MatchError(store.ErrNotFound))
+	})
+
+	It("should persist the schedule and compute the next run", func() {
+		before := time.Now()
+		saved, err := schedule.Set(ctx, &models.CollectionSchedule{Interval: time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved.Interval).To(Equal(time.Hour))
+		Expect(saved.NextRun).To(BeTemporally("~", before.Add(time.Hour), time.Second))
+
+		stored, err := st.Schedule().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Interval).To(Equal(time.Hour))
+	})
+
+	It("should compute the next run of a cron schedule", func() {
+		saved, err := schedule.Set(ctx, &models.CollectionSchedule{Cron: "0 2 * * *"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved.NextRun.Hour()).To(Equal(2))
+		Expect(saved.NextRun.Minute()).To(Equal(0))
+		Expect(saved.NextRun).To(BeTemporally(">", time.Now()))
+	})
+
+	It("should reject an invalid schedule", func() {
+		_, err := schedule.Set(ctx, &models.CollectionSchedule{Interval: time.Hour, Cron: "0 2 * * *"})
+		Expect(err).To(MatchError(services.ErrInvalidSchedule))
+	})
+
+	It("should remove the schedule", func() {
+		_, err := schedule.Set(ctx, &models.CollectionSchedule{Interval: time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(schedule.Delete(ctx)).To(Succeed())
+
+		_, err = schedule.Get()
+		Expect(err).To(MatchError(store.ErrNotFound))
+	})
+
+	It("should not push back the next run when the same schedule is set again", func() {
+		_, err := schedule.Set(ctx, &models.CollectionSchedule{Interval: time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+		setAt := time.Now().Add(-30 * time.Minute)
+		_, err = db.ExecContext(ctx, `UPDATE collection_schedule SET updated_at = ?`, setAt)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("restarting the agent with the same schedule")
+		schedule.Close()
+		schedule = services.NewScheduleService(sched, collector, nil, st)
+		saved, err := schedule.Set(ctx, &models.CollectionSchedule{Interval: time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved.NextRun).To(BeTemporally("~", setAt.Add(time.Hour), time.Second))
+
+		By("changing the schedule")
+		before := time.Now()
+		saved, err = schedule.Set(ctx, &models.CollectionSchedule{Interval: 2 * time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(saved.NextRun).To(BeTemporally("~", before.Add(2*time.Hour), time.Second))
+	})
+
+	Describe("scheduled runs", func() {
+		var server *simulator.Server
+
+		// restart stores an interval schedule whose last run is older than the interval and restarts the service,
+		// so the run missed while the agent was stopped is due right away.
+		restart := func() {
+			Expect(st.Schedule().Save(ctx, &models.CollectionSchedule{Interval: time.Hour})).To(Succeed())
+			_, err := db.ExecContext(ctx, `UPDATE collection_schedule SET updated_at = ?`, time.Now().Add(-3*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.Schedule().RecordRun(ctx, models.ScheduledRun{
+				StartedAt: time.Now().Add(-2 * time.Hour),
+				Status:    models.ScheduledRunStatusSucceeded,
+			})).To(Succeed())
+
+			schedule.Close()
+			schedule = services.NewScheduleService(sched, collector, nil, st)
+		}
+
+		lastRun := func() models.ScheduledRun {
+			stored, err := st.Schedule().Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.LastRun).NotTo(BeNil())
+			return *stored.LastRun
+		}
+
+		newSimulator := func(model *simulator.Model) *simulator.Server {
+			Expect(model.Create()).To(Succeed())
+			DeferCleanup(model.Remove)
+			model.Service.Listen = &url.URL{User: url.UserPassword("user", "pass")}
+			server := model.Service.NewServer()
+			DeferCleanup(server.Close)
+			return server
+		}
+
+		credentials := func() *models.Credentials {
+			u := *server.URL
+			u.User = nil
+			return &models.Credentials{URL: u.String(), Username: "user", Password: "pass"}
+		}
+
+		It("should collect with the stored credentials and record the outcome", func() {
+			server = newSimulator(simulator.VPX())
+			Expect(st.Credentials().Save(ctx, credentials())).To(Succeed())
+			startedAt := time.Now()
+			restart()
+
+			Eventually(lastRun, "60s").Should(And(
+				HaveField("StartedAt", BeTemporally(">=", startedAt.Truncate(time.Microsecond))),
+				HaveField("Status", models.ScheduledRunStatusSucceeded),
+			))
+
+			runs, total, err := collector.ListRuns(ctx, 20, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(1))
+			Expect(runs[0].Trigger).To(Equal(models.CollectionTriggerScheduled))
+			Expect(runs[0].VCenterURL).To(Equal(credentials().URL))
+			Expect(runs[0].State).To(Equal(models.CollectionRunStateSucceeded))
+
+			current, err := schedule.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(current.NextRun).To(BeTemporally("~", lastRun().StartedAt.Add(time.Hour), time.Second))
+		})
+
+		It("should record a failed run without credentials", func() {
+			restart()
+
+			Eventually(func() models.ScheduledRunStatus {
+				return lastRun().Status
+			}, "10s").Should(Equal(models.ScheduledRunStatusFailed))
+			Expect(lastRun().Error).To(ContainSubstring("failed to get credentials"))
+		})
+
+		It("should skip the run while a collection is in progress", func() {
+			model := simulator.VPX()
+			// keep the collection in progress while the scheduled run is due
+			model.DelayConfig.MethodDelay = map[string]int{"WaitForUpdatesEx": 2000}
+			server = newSimulator(model)
+			Expect(collector.Start(ctx, credentials(), false)).To(Succeed())
+			restart()
+
+			Eventually(func() models.ScheduledRunStatus {
+				return lastRun().Status
+			}, "10s").Should(Equal(models.ScheduledRunStatusSkipped))
+
+			runs, total, err := collector.ListRuns(ctx, 20, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(total).To(Equal(1))
+			Expect(runs[0].Trigger).To(Equal(models.CollectionTriggerManual))
+
+			// the collection job returns once its run is finished
+			Expect(collector.Stop(ctx)).To(Succeed())
+			Eventually(func() models.CollectionRunState {
+				runs, _, err := collector.ListRuns(ctx, 1, 0)
+				Expect(err).NotTo(HaveOccurred())
+				return runs[0].State
+			}, "10s").Should(Equal(models.CollectionRunStateCancelled))
+		})
+	})
+})
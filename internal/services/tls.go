@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/soap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	agentErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// newSoapClient creates a vSphere client verifying the vCenter certificate.
+// The certificate is verified with the CA bundle of the credentials, or the system roots when not set.
+// When this verification fails, the certificate is accepted if it matches the pinned thumbprint.
+func newSoapClient(u *url.URL, creds *models.Credentials) (*soap.Client, error) {
+	client := soap.NewClient(u, false)
+
+	if creds.CACert != "" {
+		pool, err := parseCACert(creds.CACert)
+		if err != nil {
+			return nil, err
+		}
+		client.DefaultTransport().TLSClientConfig.RootCAs = pool
+	}
+	if creds.Thumbprint != "" {
+		client.SetThumbprint(u.Host, creds.Thumbprint)
+	}
+
+	return client, nil
+}
+
+func parseCACert(caCert string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caCert)) {
+		return nil, errors.New("failed to parse the CA certificate bundle")
+	}
+	return pool, nil
+}
+
+// NormalizeThumbprint returns the thumbprint in the upper case, colon separated form used by vSphere.
+// It accepts SHA-1 and SHA-256 thumbprints with or without separators.
+func NormalizeThumbprint(thumbprint string) (string, error) {
+	hex := strings.ToUpper(strings.NewReplacer(":", "", " ", "").Replace(thumbprint))
+	if len(hex) != 40 && len(hex) != 64 {
+		return "", fmt.Errorf("invalid thumbprint %q: must be a SHA-1 or SHA-256 fingerprint", thumbprint)
+	}
+
+	pairs := make([]string, 0, len(hex)/2)
+	for i := 0; i < len(hex); i += 2 {
+		if !isHex(hex[i]) || !isHex(hex[i+1]) {
+			return "", fmt.Errorf("invalid thumbprint %q: must be hexadecimal", thumbprint)
+		}
+		pairs = append(pairs, hex[i:i+2])
+	}
+	return strings.Join(pairs, ":"), nil
+}
+
+// ValidateCACert checks the CA bundle contains at least one PEM certificate.
+func ValidateCACert(caCert string) error {
+	_, err := parseCACert(caCert)
+	return err
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'A' && c <= 'F')
+}
+
+// isCertificateError reports whether err was caused by the vCenter certificate verification.
+func isCertificateError(err error) bool {
+	if soap.IsCertificateUntrusted(err) {
+		return true
+	}
+	var invalid x509.CertificateInvalidError
+	if errors.As(err, &invalid) {
+		return true
+	}
+	var verification *tls.CertificateVerificationError
+	if errors.As(err, &verification) {
+		return true
+	}
+	// returned by govmomi when the pinned thumbprint does not match
+	return strings.Contains(err.Error(), "thumbprint does not match")
+}
+
+// certificateError wraps a certificate verification error with the certificate presented by vCenter.
+func certificateError(ctx context.Context, u *url.URL, err error) error {
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	cert, fetchErr := presentedCertificate(ctx, host)
+	if fetchErr != nil {
+		return fmt.Errorf("%w (failed to get the presented certificate: %v)", err, fetchErr)
+	}
+	return agentErrors.NewCertificateError(u.Host, cert, err)
+}
+
+// presentedCertificate returns the leaf certificate presented by host, without verifying it.
+func presentedCertificate(ctx context.Context, host string) (*x509.Certificate, error) {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: 10 * time.Second},
+		// the certificate is only read to be shown to the user, it is not trusted
+		Config: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate presented")
+	}
+	return certs[0], nil
+}
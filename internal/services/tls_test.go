@@ -0,0 +1,39 @@
+package services_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+)
+
+var _ = Describe("NormalizeThumbprint", func() {
+	It("should format a SHA-1 thumbprint", func() {
+		tp, err := services.NormalizeThumbprint("0123456789abcdef0123456789abcdef01234567")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp).To(Equal("01:23:45:67:89:AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67"))
+	})
+
+	It("should accept a colon separated SHA-256 thumbprint", func() {
+		in := "AA:BB:CC:DD:EE:FF:00:11:22:33:44:55:66:77:88:99:AA:BB:CC:DD:EE:FF:00:11:22:33:44:55:66:77:88:99"
+		tp, err := services.NormalizeThumbprint(in)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tp).To(Equal(in))
+	})
+
+	It("should reject an invalid length", func() {
+		_, err := services.NormalizeThumbprint("AB:CD")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject non hexadecimal characters", func() {
+		_, err := services.NormalizeThumbprint("zz23456789abcdef0123456789abcdef01234567")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ValidateCACert", func() {
+	It("should reject a bundle without certificates", func() {
+		Expect(services.ValidateCACert("not a certificate")).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/vim25/types"
+
+	agentErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// vCenterAPIType is the API type reported by vCenter. Standalone ESXi hosts report HostAgent.
+const vCenterAPIType = "VirtualCenter"
+
+// connectionError classifies an error raised while connecting to vCenter.
+// Errors which are not caused by the network or the certificate mean the endpoint does not speak the vSphere API.
+func connectionError(ctx context.Context, u *url.URL, err error) error {
+	if isCertificateError(err) {
+		return certificateError(ctx, u, err)
+	}
+	if classified := networkError(u, err); classified != nil {
+		return classified
+	}
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	return agentErrors.NewNotVCenterError(u.Host, "", err)
+}
+
+// sessionError classifies an error raised by the login or a call made in a vCenter session.
+func sessionError(u *url.URL, err error) error {
+	if fault.Is(err, &types.InvalidLogin{}) {
+		return ErrInvalidCredentials
+	}
+
+	var noPermission *types.NoPermission
+	if _, ok := fault.As(err, &noPermission); ok {
+		return agentErrors.NewInsufficientPrivilegesError(u.User.Username(), missingPrivileges(noPermission), err)
+	}
+
+	if classified := networkError(u, err); classified != nil {
+		return classified
+	}
+	return err
+}
+
+// networkError returns the typed error for DNS, timeout and connection failures, nil for any other error.
+func networkError(u *url.URL, err error) error {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && !dnsErr.IsTimeout {
+		return agentErrors.NewVCenterDNSError(u.Hostname(), err)
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return agentErrors.NewVCenterTimeoutError(u.Host, err)
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return agentErrors.NewVCenterUnreachableError(u.Host, err)
+	}
+	return nil
+}
+
+func missingPrivileges(f *types.NoPermission) []string {
+	var privileges []string
+	for _, entity := range f.MissingPrivileges {
+		privileges = append(privileges, entity.PrivilegeIds...)
+	}
+	if len(privileges) == 0 && f.PrivilegeId != "" {
+		privileges = append(privileges, f.PrivilegeId)
+	}
+	return privileges
+}
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+)
+
+// vcentersFolder is the folder of the data folder holding the forklift databases of the named vCenters.
+const vcentersFolder = "vcenters"
+
+var vcenterNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// ValidateVCenterName checks the name is a lowercase DNS label, so it can be used in paths and cluster keys.
+func ValidateVCenterName(name string) error {
+	if !vcenterNameRegexp.MatchString(name) {
+		return errors.New("name must consist of at most 63 lowercase alphanumeric characters or '-', and start and end with an alphanumeric character")
+	}
+	return nil
+}
+
+// VCenterService manages the named vCenter endpoints collected in addition to the vCenter of the collector.
+// Each vCenter is collected by its own CollectorService, with its own credentials, state, runs and snapshots.
+type VCenterService struct {
+	scheduler  *scheduler.Scheduler
+	store      *store.Store
+	cfg        config.Agent
+	dataFolder string
+
+	mu sync.Mutex
+	// collectors holds the collector of each vCenter
+	collectors map[string]*CollectorService
+}
+
+func NewVCenterService(s *scheduler.Scheduler, st *store.Store, cfg config.Agent) *VCenterService {
+	v := &VCenterService{
+		scheduler:  s,
+		store:      st,
+		cfg:        cfg,
+		dataFolder: cfg.DataFolder,
+		collectors: make(map[string]*CollectorService),
+	}
+
+	vcenters, err := st.VCenters().List(context.Background())
+	if err != nil {
+		zap.S().Warnw("failed to list vcenters", "error", err)
+	}
+	for _, vc := range vcenters {
+		v.collectors[vc.Name] = newCollectorService(s, st, cfg, vc.Name)
+	}
+
+	return v
+}
+
+// List returns the vCenters sorted by name, with the status of their collection.
+func (v *VCenterService) List(ctx context.Context) ([]models.VCenter, error) {
+	vcenters, err := v.store.VCenters().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range vcenters {
+		if collector, err := v.Collector(vcenters[i].Name); err == nil {
+			withStatus(&vcenters[i], collector.GetStatus(ctx))
+		}
+	}
+	return vcenters, nil
+}
+
+// Get returns the vCenter with the given name, with the status of its collection.
+func (v *VCenterService) Get(ctx context.Context, name string) (*models.VCenter, error) {
+	collector, err := v.Collector(name)
+	if err != nil {
+		return nil, err
+	}
+
+	vc, err := v.store.VCenters().Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	withStatus(vc, collector.GetStatus(ctx))
+	return vc, nil
+}
+
+// Collector returns the collector of the vCenter, store.ErrNotFound when the vCenter does not exist.
+func (v *VCenterService) Collector(name string) (*CollectorService, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	collector, found := v.collectors[name]
+	if !found {
+		return nil, store.ErrNotFound
+	}
+	return collector, nil
+}
+
+// Collectors returns the collectors of the vCenters sorted by name.
+func (v *VCenterService) Collectors() map[string]*CollectorService {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return maps.Clone(v.collectors)
+}
+
+// Create adds a vCenter. The name must have been validated with ValidateVCenterName.
+// The vCenter is collected with Collect.
+func (v *VCenterService) Create(ctx context.Context, name string, creds *models.Credentials) (*models.VCenter, error) {
+	v.mu.Lock()
+	err := v.store.VCenters().Create(ctx, &models.VCenter{
+		Name:        name,
+		Credentials: *creds,
+		State:       models.CollectorStateReady,
+	})
+	if err == nil {
+		v.collectors[name] = newCollectorService(v.scheduler, v.store, v.cfg, name)
+	}
+	v.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	zap.S().Infow("vcenter added", "vcenter", name, "url", creds.URL)
+
+	return v.Get(ctx, name)
+}
+
+// Update replaces the credentials of the vCenter. The snapshots collected with the previous credentials are kept.
+func (v *VCenterService) Update(ctx context.Context, name string, creds *models.Credentials) (*models.VCenter, error) {
+	collector, err := v.Collector(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := collector.UpdateCredentials(ctx, creds); err != nil {
+		return nil, err
+	}
+
+	return v.Get(ctx, name)
+}
+
+// Delete stops the collection of the vCenter if any and removes it along with its runs and snapshots.
+// The vCenter is left out of the collectors while its collection stops, and put back when it cannot be removed.
+func (v *VCenterService) Delete(ctx context.Context, name string) error {
+	v.mu.Lock()
+	collector, found := v.collectors[name]
+	delete(v.collectors, name)
+	v.mu.Unlock()
+	if !found {
+		return store.ErrNotFound
+	}
+
+	err := collector.close(ctx)
+	if err == nil {
+		err = v.store.VCenters().Delete(ctx, name)
+	}
+	if err != nil {
+		v.mu.Lock()
+		v.collectors[name] = collector
+		v.mu.Unlock()
+		return err
+	}
+
+	if err := os.RemoveAll(v.folder(name)); err != nil {
+		zap.S().Warnw("failed to remove vcenter data folder", "vcenter", name, "error", err)
+	}
+	zap.S().Infow("vcenter removed", "vcenter", name)
+
+	return nil
+}
+
+// Collect verifies the credentials and privileges of the vCenter and starts its collection.
+// With fullResync, the inventory is collected from scratch even in incremental mode.
+func (v *VCenterService) Collect(ctx context.Context, name string, fullResync bool) (*models.VCenter, error) {
+	collector, err := v.Collector(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := collector.Collect(ctx, fullResync); err != nil {
+		return nil, err
+	}
+
+	return v.Get(ctx, name)
+}
+
+// Stop cancels the collection of the vCenter if any.
+func (v *VCenterService) Stop(ctx context.Context, name string) (*models.VCenter, error) {
+	collector, err := v.Collector(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := collector.Stop(ctx); err != nil {
+		return nil, err
+	}
+
+	return v.Get(ctx, name)
+}
+
+// Inventories returns the latest snapshot of each collected vCenter, named after the vCenter.
+func (v *VCenterService) Inventories(ctx context.Context) ([]InventorySource, error) {
+	collectors := v.Collectors()
+
+	sources := make([]InventorySource, 0, len(collectors))
+	for _, name := range slices.Sorted(maps.Keys(collectors)) {
+		latest, err := collectors[name].GetInventory(ctx)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get inventory of vcenter %q: %w", name, err)
+		}
+
+		var inventory apiplanner.Inventory
+		if err := json.Unmarshal(latest.Data, &inventory); err != nil {
+			return nil, fmt.Errorf("failed to decode inventory of vcenter %q: %w", name, err)
+		}
+		sources = append(sources, InventorySource{Name: name, Inventory: &inventory})
+	}
+
+	return sources, nil
+}
+
+// folder returns the folder of the forklift database of the vCenter.
+func (v *VCenterService) folder(name string) string {
+	return filepath.Join(v.dataFolder, vcentersFolder, name)
+}
+
+// withStatus sets the status of the collector of the vCenter, which is more recent than the persisted one.
+func withStatus(vc *models.VCenter, status models.CollectorStatus) {
+	vc.State = status.State
+	vc.Error = status.Error
+	vc.Progress = status.Progress
+	vc.Preflight = status.Preflight
+}
+
+// vcenterEndpoint persists the credentials and the state of a named vCenter.
+type vcenterEndpoint struct {
+	store *store.Store
+	name  string
+}
+
+func (e *vcenterEndpoint) Credentials(ctx context.Context) (*models.Credentials, error) {
+	vc, err := e.store.VCenters().Get(ctx, e.name)
+	if err != nil {
+		return nil, err
+	}
+	return &vc.Credentials, nil
+}
+
+func (e *vcenterEndpoint) SaveCredentials(ctx context.Context, creds *models.Credentials) error {
+	return e.store.VCenters().UpdateCredentials(ctx, e.name, creds)
+}
+
+func (e *vcenterEndpoint) State(ctx context.Context) (models.CollectorState, string, error) {
+	return e.store.VCenters().GetState(ctx, e.name)
+}
+
+// SaveState ignores the vCenters removed meanwhile.
+func (e *vcenterEndpoint) SaveState(ctx context.Context, state models.CollectorState, errorMsg string) error {
+	err := e.store.VCenters().SaveState(ctx, e.name, state, errorMsg)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// MergedCollector reports the status of the collector and the inventory of the collector
+// merged with the inventories of the named vCenters, to send to console a single inventory.
+type MergedCollector struct {
+	collector *CollectorService
+	vcenters  *VCenterService
+}
+
+func NewMergedCollector(collector *CollectorService, vcenters *VCenterService) *MergedCollector {
+	return &MergedCollector{collector: collector, vcenters: vcenters}
+}
+
+// mergedStatusPrecedence orders the statuses of the endpoints, the merged status is the first one
+// reported by any endpoint: an error anywhere is reported, then any collection in progress.
+var mergedStatusPrecedence = []models.CollectorStatusType{
+	models.CollectorStatusError,
+	models.CollectorStatusCollecting,
+	models.CollectorStatusConnected,
+	models.CollectorStatusConnecting,
+	models.CollectorStatusCollected,
+	models.CollectorStatusCancelled,
+}
+
+// Status returns the status derived from the collector and the named vCenters.
+func (m *MergedCollector) Status() models.CollectorStatusType {
+	statuses := []models.CollectorStatusType{m.collector.Status()}
+	for _, collector := range m.vcenters.Collectors() {
+		statuses = append(statuses, collector.Status())
+	}
+
+	for _, status := range mergedStatusPrecedence {
+		if slices.Contains(statuses, status) {
+			return status
+		}
+	}
+	return models.CollectorStatusReady
+}
+
+// GetStatus returns the status derived from the collector and the named vCenters. The error lists
+// the errors of the endpoints, prefixed with the name of the vCenter for the named vCenters.
+// Progress and preflight are the ones of the collector.
+func (m *MergedCollector) GetStatus(ctx context.Context) models.CollectorStatus {
+	status := m.collector.GetStatus(ctx)
+
+	var errs []string
+	if status.Error != "" {
+		errs = append(errs, status.Error)
+	}
+	collectors := m.vcenters.Collectors()
+	for _, name := range slices.Sorted(maps.Keys(collectors)) {
+		if vcStatus := collectors[name].GetStatus(ctx); vcStatus.Error != "" {
+			errs = append(errs, fmt.Sprintf("vcenter %q: %s", name, vcStatus.Error))
+		}
+	}
+
+	status.State = models.CollectorState(m.Status())
+	status.Error = strings.Join(errs, "; ")
+	return status
+}
+
+// Inventory returns the merged inventory, store.ErrNotFound if nothing was collected yet.
+// The inventory of the collector is returned unchanged when no named vCenter was collected.
+func (m *MergedCollector) Inventory() (io.Reader, error) {
+	ctx := context.Background()
+
+	var sources []InventorySource
+	latest, err := m.collector.GetInventory(ctx)
+	switch {
+	case err == nil:
+		var inventory apiplanner.Inventory
+		if err := json.Unmarshal(latest.Data, &inventory); err != nil {
+			return nil, fmt.Errorf("failed to decode inventory: %w", err)
+		}
+		sources = append(sources, InventorySource{Inventory: &inventory})
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, err
+	}
+
+	named, err := m.vcenters.Inventories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(named) == 0 && latest != nil:
+		return bytes.NewReader(latest.Data), nil
+	case len(sources)+len(named) == 0:
+		return nil, store.ErrNotFound
+	}
+
+	data, err := json.Marshal(MergeInventories(append(sources, named...)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged inventory: %w", err)
+	}
+	return bytes.NewReader(data), nil
+}
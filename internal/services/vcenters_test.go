@@ -0,0 +1,290 @@
+package services_test
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/url"
+	"time"
+
+	apiplanner "github.com/kubev2v/migration-planner/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/soap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/config"
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	"github.com/kubev2v/assisted-migration-agent/pkg/scheduler"
+)
+
+var _ = Describe("VCenterService", func() {
+	var (
+		ctx      context.Context
+		sched    *scheduler.Scheduler
+		db       *sql.DB
+		st       *store.Store
+		cfg      config.Agent
+		vcenters *services.VCenterService
+		server   *simulator.Server
+		creds    *models.Credentials
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sched = scheduler.NewScheduler(1)
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrations.Run(ctx, db)).To(Succeed())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		st = store.NewStore(db, encryption.NewKeyring(key))
+		cfg = config.Agent{
+			DataFolder:       GinkgoT().TempDir(),
+			CollectorTimeout: time.Minute,
+		}
+		vcenters = services.NewVCenterService(sched, st, cfg)
+
+		model := simulator.VPX()
+		Expect(model.Create()).To(Succeed())
+		DeferCleanup(model.Remove)
+		model.Service.Listen = &url.URL{User: url.UserPassword("user", "pass")}
+		model.Service.TLS = new(tls.Config)
+		server = model.Service.NewServer()
+		DeferCleanup(server.Close)
+
+		u := *server.URL
+		u.User = nil
+		creds = &models.Credentials{
+			URL:        u.String(),
+			Username:   "user",
+			Password:   "pass",
+			Thumbprint: soap.ThumbprintSHA1(server.Certificate()),
+		}
+	})
+
+	AfterEach(func() {
+		sched.Close()
+		_ = db.Close()
+	})
+
+	// collect starts a collection of the vCenter, waits for its run to end and returns the run.
+	collect := func(name string, fullResync bool) models.CollectionRun {
+		Eventually(func() error {
+			_, err := vcenters.Collect(ctx, name, fullResync)
+			return err
+		}).Should(Succeed())
+
+		collector, err := vcenters.Collector(name)
+		Expect(err).NotTo(HaveOccurred())
+
+		var run models.CollectionRun
+		Eventually(func() models.CollectionRunState {
+			runs, _, err := collector.ListRuns(ctx, 1, 0)
+			Expect(err).NotTo(HaveOccurred())
+			run = runs[0]
+			return run.State
+		}, "60s").ShouldNot(Equal(models.CollectionRunStateRunning))
+		return run
+	}
+
+	It("should collect the inventory of a vCenter as a run saving a snapshot", func() {
+		_, err := vcenters.Create(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+
+		run := collect("east", false)
+		Expect(run.Error).To(BeEmpty())
+		Expect(run.State).To(Equal(models.CollectionRunStateSucceeded))
+		Expect(run.Trigger).To(Equal(models.CollectionTriggerManual))
+		Expect(run.SnapshotID).NotTo(BeZero())
+
+		vcenter, err := vcenters.Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vcenter.State).To(Equal(models.CollectorStateReady))
+		Expect(vcenter.Preflight).NotTo(BeNil())
+		Expect(vcenter.CollectedAt.IsZero()).To(BeFalse())
+
+		collector, err := vcenters.Collector("east")
+		Expect(err).NotTo(HaveOccurred())
+		snapshot, err := collector.GetInventory(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot.ID).To(Equal(run.SnapshotID))
+		var inventory apiplanner.Inventory
+		Expect(json.Unmarshal(snapshot.Data, &inventory)).To(Succeed())
+		Expect(inventory.Vcenter.Vms.Total).To(BeNumerically(">", 0))
+
+		By("keeping the runs and snapshots apart from the collector ones")
+		_, err = st.Inventory().Latest(ctx)
+		Expect(err).To(MatchError(store.ErrNotFound))
+		_, total, err := st.CollectionRuns().List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(BeZero())
+
+		By("merging the inventory for console")
+		reader, err := services.NewMergedCollector(services.NewCollectorService(sched, st, cfg), vcenters).Inventory()
+		Expect(err).NotTo(HaveOccurred())
+		merged, err := io.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		var mergedInventory apiplanner.Inventory
+		Expect(json.Unmarshal(merged, &mergedInventory)).To(Succeed())
+		Expect(mergedInventory.Vcenter.Vms.Total).To(Equal(inventory.Vcenter.Vms.Total))
+		for id := range inventory.Clusters {
+			Expect(mergedInventory.Clusters).To(HaveKey("east/" + id))
+		}
+	})
+
+	It("should apply the vCenter changes since the previous collection in incremental mode", func() {
+		cfg.IncrementalCollection = true
+		vcenters = services.NewVCenterService(sched, st, cfg)
+		_, err := vcenters.Create(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+		// stops the collector kept in sync before the simulator is closed
+		DeferCleanup(func() { _, _ = vcenters.Stop(ctx, "east") })
+
+		Expect(collect("east", false).Incremental).To(BeFalse())
+		Expect(collect("east", false).Incremental).To(BeTrue())
+		Expect(collect("east", true).Incremental).To(BeFalse())
+
+		collector, err := vcenters.Collector("east")
+		Expect(err).NotTo(HaveOccurred())
+		snapshots, err := collector.ListInventorySnapshots(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(HaveLen(3))
+	})
+
+	It("should cancel the collection of a vCenter", func() {
+		_, err := vcenters.Create(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = vcenters.Collect(ctx, "east", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		vcenter, err := vcenters.Stop(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vcenter.State).To(Equal(models.CollectorStateCancelled))
+
+		collector, err := vcenters.Collector("east")
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() models.CollectionRunState {
+			runs, _, err := collector.ListRuns(ctx, 1, 0)
+			Expect(err).NotTo(HaveOccurred())
+			return runs[0].State
+		}, "60s").Should(Equal(models.CollectionRunStateCancelled))
+	})
+
+	It("should record the error of a collection with invalid credentials", func() {
+		creds.Password = "wrong"
+		_, err := vcenters.Create(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = vcenters.Collect(ctx, "east", false)
+		Expect(err).To(MatchError(services.ErrInvalidCredentials))
+
+		vcenter, err := vcenters.Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vcenter.State).To(Equal(models.CollectorStateError))
+		Expect(vcenter.Error).To(Equal(services.ErrInvalidCredentials.Error()))
+
+		collector, err := vcenters.Collector("east")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = collector.GetInventory(ctx)
+		Expect(err).To(MatchError(store.ErrNotFound))
+
+		By("deriving the merged status from all the endpoints")
+		merged := services.NewMergedCollector(services.NewCollectorService(sched, st, cfg), vcenters)
+		Expect(merged.Status()).To(Equal(models.CollectorStatusError))
+		status := merged.GetStatus(ctx)
+		Expect(status.State).To(Equal(models.CollectorStateError))
+		Expect(status.Error).To(Equal(`vcenter "east": ` + services.ErrInvalidCredentials.Error()))
+	})
+
+	It("should reset the state when the credentials are updated", func() {
+		creds.Password = "wrong"
+		_, err := vcenters.Create(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = vcenters.Collect(ctx, "east", false)
+		Expect(err).To(HaveOccurred())
+
+		creds.Password = "pass"
+		vcenter, err := vcenters.Update(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vcenter.State).To(Equal(models.CollectorStateReady))
+		Expect(vcenter.Error).To(BeEmpty())
+
+		stored, err := st.VCenters().Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Credentials.Password).To(Equal("pass"))
+	})
+
+	It("should record a collection interrupted by a restart as failed", func() {
+		_, err := vcenters.Create(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+		collector, err := vcenters.Collector("east")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = st.CollectionRuns().VCenter("east").Create(ctx, &models.CollectionRun{
+			Trigger:    models.CollectionTriggerManual,
+			VCenterURL: creds.URL,
+			State:      models.CollectionRunStateRunning,
+			StartedAt:  time.Now(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(st.VCenters().SaveState(ctx, "east", models.CollectorStateCollecting, "")).To(Succeed())
+
+		restarted := services.NewVCenterService(sched, st, cfg)
+
+		vcenter, err := restarted.Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vcenter.State).To(Equal(models.CollectorStateError))
+		Expect(vcenter.Error).To(Equal(services.ErrCollectionInterrupted.Error()))
+
+		runs, _, err := collector.ListRuns(ctx, 1, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runs[0].State).To(Equal(models.CollectionRunStateFailed))
+	})
+
+	It("should remove a vCenter along with its runs and snapshots", func() {
+		_, err := vcenters.Create(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+		collect("east", false)
+
+		Expect(vcenters.Delete(ctx, "east")).To(Succeed())
+
+		_, err = vcenters.Get(ctx, "east")
+		Expect(err).To(MatchError(store.ErrNotFound))
+		_, err = vcenters.Collector("east")
+		Expect(err).To(MatchError(store.ErrNotFound))
+		Expect(vcenters.Delete(ctx, "east")).To(MatchError(store.ErrNotFound))
+
+		snapshots, err := st.Inventory().VCenter("east").List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(BeEmpty())
+		_, total, err := st.CollectionRuns().VCenter("east").List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(BeZero())
+	})
+
+	It("should keep a vCenter which cannot be removed", func() {
+		_, err := vcenters.Create(ctx, "east", creds)
+		Expect(err).NotTo(HaveOccurred())
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		Expect(vcenters.Delete(cancelled, "east")).To(MatchError(context.Canceled))
+
+		_, err = vcenters.Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = vcenters.Collector("east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vcenters.Delete(ctx, "east")).To(Succeed())
+	})
+})
@@ -2,19 +2,18 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sync"
 	"time"
 
 	api "github.com/kubev2v/forklift/pkg/apis/forklift/v1beta1"
 	"github.com/kubev2v/forklift/pkg/controller/provider/container/vsphere"
 	"github.com/kubev2v/forklift/pkg/controller/provider/model"
-	webprovider "github.com/kubev2v/forklift/pkg/controller/provider/web"
-	"github.com/kubev2v/forklift/pkg/controller/provider/web/base"
-	web "github.com/kubev2v/forklift/pkg/controller/provider/web/vsphere"
+	vspheremodel "github.com/kubev2v/forklift/pkg/controller/provider/model/vsphere"
 	libcontainer "github.com/kubev2v/forklift/pkg/lib/inventory/container"
 	libmodel "github.com/kubev2v/forklift/pkg/lib/inventory/model"
-	libweb "github.com/kubev2v/forklift/pkg/lib/inventory/web"
 	"go.uber.org/zap"
 	core "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -22,21 +21,33 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 )
 
+// shutdownTimeout bounds the wait for the forklift collector to stop when closing.
+const shutdownTimeout = 10 * time.Second
+
 // VSphereCollector wraps the forklift vSphere collector.
 type VSphereCollector struct {
 	collector *vsphere.Collector
 	container *libcontainer.Container
-	db        libmodel.DB
+	db        *collectorDB
 	dbPath    string
+	creds     models.Credentials
+	// proxy verifies the vCenter certificate on behalf of the forklift collector
+	proxy *vCenterProxy
 }
 
 func NewVSphereCollector(creds *models.Credentials, dataDir string) (*VSphereCollector, error) {
-	provider := createProvider(creds)
+	proxy, err := newVCenterProxy(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := createProvider(proxy.URL())
 	secret := createSecret(creds)
 
 	dbPath := filepath.Join(dataDir, "vsphere.db")
 	db, err := createDB(provider, dbPath)
 	if err != nil {
+		proxy.Close()
 		return nil, err
 	}
 
@@ -46,120 +57,251 @@ func NewVSphereCollector(creds *models.Credentials, dataDir string) (*VSphereCol
 		collector: collector,
 		db:        db,
 		dbPath:    dbPath,
+		creds:     *creds,
+		proxy:     proxy,
 	}, nil
 }
 
 // Collect runs the vSphere collection process.
 // This starts the forklift collector which populates the SQLite database.
-// The method blocks until collection is complete or the context is cancelled.
+// The method blocks until collection is complete or the context is done.
+//
+// Once started, the forklift collector keeps applying the vCenter updates to the database until Close.
+// Collecting again with the same collector only waits for it to be in sync.
 func (c *VSphereCollector) Collect(ctx context.Context) error {
-	zap.S().Info("starting forklift vSphere collector")
+	if c.container == nil {
+		zap.S().Info("starting forklift vSphere collector")
 
-	// Start the web container and wait for collection to complete
-	container, err := startWebContainer(c.collector)
-	if err != nil {
+		// Adding the collector to the container starts the collection
+		container := libcontainer.New()
+		if err := container.Add(c.collector); err != nil {
+			return err
+		}
+		c.container = container
+	}
+
+	if err := waitForParity(ctx, c.db, c.proxy); err != nil {
 		return err
 	}
-	c.container = container
 
 	zap.S().Info("forklift vSphere collection completed (parity reached)")
 	return nil
 }
 
+// Matches reports whether the collector collects the vCenter with the given credentials.
+func (c *VSphereCollector) Matches(creds *models.Credentials) bool {
+	return c.creds.URL == creds.URL &&
+		c.creds.Username == creds.Username &&
+		c.creds.Password == creds.Password &&
+		c.creds.CACert == creds.CACert &&
+		c.creds.Thumbprint == creds.Thumbprint
+}
+
 // DBPath returns the path to the SQLite database.
 func (c *VSphereCollector) DBPath() string {
 	return c.dbPath
 }
 
+// DB returns the forklift database populated by the collector.
+func (c *VSphereCollector) DB() libmodel.DB {
+	return c.db
+}
+
+// SyncedCounts returns the number of VMs, hosts and datastores already synced into the database.
+func (c *VSphereCollector) SyncedCounts() (vms, hosts, datastores int, err error) {
+	counts := make([]int, 0, 3)
+	for _, m := range []libmodel.Model{&vspheremodel.VM{}, &vspheremodel.Host{}, &vspheremodel.Datastore{}} {
+		n, err := c.db.Count(m, nil)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		counts = append(counts, int(n))
+	}
+	return counts[0], counts[1], counts[2], nil
+}
+
 // ForkliftCollector returns the underlying forklift vSphere collector.
 // This is needed by the inventory builder to access the collected data.
 func (c *VSphereCollector) ForkliftCollector() *vsphere.Collector {
 	return c.collector
 }
 
-// Close cleans up collector resources.
+// Close stops the forklift collector and deletes its database.
 func (c *VSphereCollector) Close() {
 	if c.container != nil {
 		c.container.Delete(c.collector.Owner())
+		waitForShutdown(c.db)
+	}
+	_ = c.db.Close(true)
+	c.proxy.Close()
+}
+
+// waitForShutdown waits for the collector to stop applying the vCenter updates, so the database
+// is not closed under it. The collector ends its watches when its update loop returns.
+func waitForShutdown(db *collectorDB) {
+	deadline := time.After(shutdownTimeout)
+	for {
+		watched, changed := db.watched()
+		if !watched {
+			break
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			zap.S().Warn("timed out waiting for the forklift collector to stop")
+			return
+		}
+	}
+	db.sync()
+}
+
+// collectorDB is the database of the forklift collector. It follows the watches the collector starts
+// on the database once in sync with vCenter, and ends when its update loop returns: the parity flag
+// of the collector is not safe to read while the collector runs.
+type collectorDB struct {
+	libmodel.DB
+
+	mu sync.Mutex
+	// watches is the number of watches of the collector which have not ended
+	watches int
+	// changed is closed, and replaced, whenever the number of watches changes
+	changed chan struct{}
+	// last is the last watch started by the collector
+	last *libmodel.Watch
+}
+
+// Watch starts the watch, following when its handler ends.
+func (d *collectorDB) Watch(model libmodel.Model, handler libmodel.EventHandler) (*libmodel.Watch, error) {
+	d.add(1)
+	// the handler only ends once the watch is started
+	watch, err := d.DB.Watch(model, &collectorWatch{EventHandler: handler, end: func() { d.add(-1) }})
+	if err != nil {
+		d.add(-1)
+		return nil, err
 	}
-	if c.db != nil {
-		_ = c.db.Close(true)
+
+	d.mu.Lock()
+	d.last = watch
+	d.mu.Unlock()
+	return watch, nil
+}
+
+// watched reports whether the collector watches the database, along with a channel closed when it changes.
+func (d *collectorDB) watched() (bool, <-chan struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.watches > 0, d.changed
+}
+
+func (d *collectorDB) add(n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.watches += n
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// sync waits for the collector to be done ending its watches. The handler of a watch ends before
+// the journal of the database drops the watch, and the journal only locks its watches when
+// ending one: ending the last watch again waits for the journal to be unlocked.
+func (d *collectorDB) sync() {
+	d.mu.Lock()
+	last := d.last
+	d.mu.Unlock()
+	if last != nil {
+		d.DB.EndWatch(last)
 	}
 }
 
-// createProvider creates a forklift Provider object from credentials.
-func createProvider(creds *models.Credentials) *api.Provider {
+// collectorWatch is the handler of a watch of the collector, reporting when the watch ends.
+type collectorWatch struct {
+	libmodel.EventHandler
+	end func()
+}
+
+func (w *collectorWatch) End() {
+	w.EventHandler.End()
+	w.end()
+}
+
+// createProvider creates a forklift Provider object collecting the vCenter at the given URL.
+func createProvider(vcenterURL string) *api.Provider {
 	vsphereType := api.VSphere
 	return &api.Provider{
 		ObjectMeta: meta.ObjectMeta{
 			UID: "1",
 		},
 		Spec: api.ProviderSpec{
-			URL:  creds.URL,
+			URL:  vcenterURL,
 			Type: &vsphereType,
 		},
 	}
 }
 
 // createSecret creates a Kubernetes Secret with vCenter credentials.
+//
+// The CA bundle and the thumbprint of the credentials are not passed to forklift, which does not enforce them:
+// the certificate of vCenter is verified with them by the proxy the collector connects to.
 func createSecret(creds *models.Credentials) *core.Secret {
+	data := map[string][]byte{
+		"user":               []byte(creds.Username),
+		"password":           []byte(creds.Password),
+		"insecureSkipVerify": []byte("false"),
+	}
+
 	return &core.Secret{
 		ObjectMeta: meta.ObjectMeta{
 			Name:      "vsphere-secret",
 			Namespace: "default",
 		},
-		Data: map[string][]byte{
-			"user":               []byte(creds.Username),
-			"password":           []byte(creds.Password),
-			"insecureSkipVerify": []byte("true"),
-		},
+		Data: data,
 	}
 }
 
 // createDB creates the SQLite database for the collector.
-func createDB(provider *api.Provider, path string) (libmodel.DB, error) {
+func createDB(provider *api.Provider, path string) (*collectorDB, error) {
 	models := model.Models(provider)
 	db := libmodel.New(path, models...)
 	if err := db.Open(true); err != nil {
 		return nil, err
 	}
-	return db, nil
+	return &collectorDB{DB: db, changed: make(chan struct{})}, nil
 }
 
-// startWebContainer starts the forklift web container which triggers collection.
-// It blocks until the collector reaches parity (fully synchronized with vCenter).
-func startWebContainer(collector *vsphere.Collector) (*libcontainer.Container, error) {
-	container := libcontainer.New()
-	if err := container.Add(collector); err != nil {
-		return nil, err
+// waitForParity blocks until the collector reaches parity (fully synchronized with vCenter) or the context is done.
+// The collector starts watching the database once it reached parity, and the watches end when it loses it.
+// It fails right away when the proxy could not verify the vCenter certificate, since forklift would retry forever.
+func waitForParity(ctx context.Context, db *collectorDB, proxy *vCenterProxy) error {
+	// A running collector is already in sync
+	watched, changed := db.watched()
+	if watched {
+		return nil
 	}
 
-	handlers := []libweb.RequestHandler{
-		&libweb.SchemaHandler{},
-		&webprovider.ProviderHandler{
-			Handler: base.Handler{
-				Container: container,
-			},
-		},
-	}
-	handlers = append(handlers, web.Handlers(container)...)
-
-	webServer := libweb.New(container, handlers...)
-	webServer.Start()
-
 	// Wait for collector to reach parity (fully synchronized with vCenter)
-	// This matches the migration-planner implementation
-	const maxRetries = 300 // 5 minutes timeout (300 * 1 second)
-	for i := 0; i < maxRetries; i++ {
-		time.Sleep(1 * time.Second)
-		if collector.HasParity() {
-			zap.S().Debug("collector reached parity")
-			return container, nil
-		}
-		if i > 0 && i%30 == 0 {
-			zap.S().Infof("waiting for vSphere collection... (%d seconds)", i)
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for ticks := 0; ; {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("timed out waiting for collector parity after %s", time.Since(start).Round(time.Second))
+			}
+			return ctx.Err()
+		case <-changed:
+			if watched, changed = db.watched(); watched {
+				zap.S().Debug("collector reached parity")
+				return nil
+			}
+		case <-ticker.C:
+			if err := proxy.Err(); err != nil && isCertificateError(err) {
+				return err
+			}
+			if ticks++; ticks%30 == 0 {
+				zap.S().Infof("waiting for vSphere collection... (%d seconds)", ticks)
+			}
 		}
 	}
-
-	return container, fmt.Errorf("timed out waiting for collector parity")
 }
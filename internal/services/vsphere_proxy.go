@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// vCenterProxy relays the requests of the forklift collector to vCenter.
+//
+// Forklift trusts the certificate presented by vCenter whenever it connects, so it verifies neither
+// the CA bundle nor the thumbprint of the credentials. The collector connects instead to the proxy,
+// on the loopback interface with a certificate generated for it, and the proxy connects to vCenter
+// verifying its certificate like verifyCredentials does.
+type vCenterProxy struct {
+	target   *url.URL
+	listener net.Listener
+	server   *http.Server
+
+	mu sync.Mutex
+	// lastErr is the error of the last request which failed to reach vCenter
+	lastErr error
+}
+
+func newVCenterProxy(creds *models.Credentials) (*vCenterProxy, error) {
+	target, err := parseVCenterURL(creds)
+	if err != nil {
+		return nil, err
+	}
+	soapClient, err := newSoapClient(target, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := loopbackCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the vCenter proxy certificate: %w", err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the vCenter proxy: %w", err)
+	}
+
+	p := &vCenterProxy{target: target, listener: listener}
+	p.server = &http.Server{
+		Handler: &httputil.ReverseProxy{
+			Rewrite: func(r *httputil.ProxyRequest) {
+				r.SetURL(&url.URL{Scheme: target.Scheme, Host: target.Host})
+			},
+			Transport:      soapClient.DefaultTransport(),
+			ModifyResponse: p.handleResponse,
+			ErrorHandler:   p.handleError,
+		},
+		ReadHeaderTimeout: 30 * time.Second,
+		// forklift first tries to verify the proxy certificate with the system roots, before falling back
+		// to its thumbprint, so every connection would log a handshake error
+		ErrorLog: log.New(io.Discard, "", 0),
+	}
+	go func() {
+		if err := p.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			zap.S().Warnw("vCenter proxy stopped", "error", err)
+		}
+	}()
+
+	return p, nil
+}
+
+// URL returns the URL of vCenter as seen through the proxy.
+func (p *vCenterProxy) URL() string {
+	u := url.URL{Scheme: "https", Host: p.listener.Addr().String(), Path: p.target.Path}
+	return u.String()
+}
+
+// Err returns the error of the last request which failed to reach vCenter, classified like the
+// errors of verifyCredentials, or nil.
+func (p *vCenterProxy) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastErr
+}
+
+// Close stops the proxy and closes the connections in progress.
+func (p *vCenterProxy) Close() {
+	_ = p.server.Close()
+}
+
+func (p *vCenterProxy) handleResponse(*http.Response) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastErr = nil
+	return nil
+}
+
+func (p *vCenterProxy) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if !errors.Is(err, context.Canceled) {
+		zap.S().Warnw("failed to relay the forklift request to vCenter", "error", err)
+
+		p.mu.Lock()
+		p.lastErr = connectionError(context.Background(), p.target, err)
+		p.mu.Unlock()
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// loopbackCertificate generates a self-signed certificate for 127.0.0.1.
+func loopbackCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "assisted-migration-agent vCenter proxy"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		// the certificate lives as long as the collector, which is kept in sync between collections
+		NotAfter:    time.Now().AddDate(10, 0, 0),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
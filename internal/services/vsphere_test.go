@@ -0,0 +1,91 @@
+package services_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"time"
+
+	vspheremodel "github.com/kubev2v/forklift/pkg/controller/provider/model/vsphere"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/soap"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/services"
+	agentErrors "github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+var _ = Describe("VSphereCollector", func() {
+	It("should count the objects synced into the database", func() {
+		collector, err := services.NewVSphereCollector(&models.Credentials{
+			URL:      "https://vcenter.example.com",
+			Username: "admin",
+			Password: "secret",
+		}, GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		defer collector.Close()
+
+		Expect(collector.DB().Insert(&vspheremodel.VM{Base: vspheremodel.Base{ID: "vm-1"}})).To(Succeed())
+		Expect(collector.DB().Insert(&vspheremodel.VM{Base: vspheremodel.Base{ID: "vm-2"}})).To(Succeed())
+		Expect(collector.DB().Insert(&vspheremodel.Host{Base: vspheremodel.Base{ID: "host-1"}})).To(Succeed())
+
+		vms, hosts, datastores, err := collector.SyncedCounts()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vms).To(Equal(2))
+		Expect(hosts).To(Equal(1))
+		Expect(datastores).To(Equal(0))
+	})
+
+	Context("with a vCenter presenting a self-signed certificate", func() {
+		var creds *models.Credentials
+
+		BeforeEach(func() {
+			model := simulator.VPX()
+			Expect(model.Create()).To(Succeed())
+			DeferCleanup(model.Remove)
+			model.Service.Listen = &url.URL{User: url.UserPassword("user", "pass")}
+			model.Service.TLS = new(tls.Config)
+			server := model.Service.NewServer()
+			DeferCleanup(server.Close)
+
+			u := *server.URL
+			u.User = nil
+			creds = &models.Credentials{
+				URL:        u.String(),
+				Username:   "user",
+				Password:   "pass",
+				Thumbprint: soap.ThumbprintSHA1(server.Certificate()),
+			}
+		})
+
+		collect := func() error {
+			collector, err := services.NewVSphereCollector(creds, GinkgoT().TempDir())
+			Expect(err).NotTo(HaveOccurred())
+			defer collector.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			return collector.Collect(ctx)
+		}
+
+		It("should collect with the pinned thumbprint", func() {
+			Expect(collect()).To(Succeed())
+		})
+
+		It("should refuse to collect when the thumbprint does not match", func() {
+			creds.Thumbprint = "00:11:22:33:44:55:66:77:88:99:AA:BB:CC:DD:EE:FF:00:11:22:33"
+
+			err := collect()
+			Expect(agentErrors.IsCertificateError(err)).To(BeTrue(), "unexpected error: %v", err)
+		})
+
+		It("should refuse to collect without trusting the certificate", func() {
+			creds.Thumbprint = ""
+
+			err := collect()
+			Expect(agentErrors.IsCertificateError(err)).To(BeTrue(), "unexpected error: %v", err)
+		})
+	})
+})
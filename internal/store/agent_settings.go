@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// AgentSettingsStore handles the agent settings storage using DuckDB.
+type AgentSettingsStore struct {
+	db *sql.DB
+}
+
+// NewAgentSettingsStore creates a new agent settings store.
+func NewAgentSettingsStore(db *sql.DB) *AgentSettingsStore {
+	return &AgentSettingsStore{db: db}
+}
+
+// Get retrieves the agent settings.
+func (s *AgentSettingsStore) Get(ctx context.Context) (*models.AgentSettings, error) {
+	var (
+		settings     models.AgentSettings
+		modeSetAt    sql.NullTime
+		consentSetAt sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, queryGetAgentSettings).Scan(
+		&settings.Mode, &modeSetAt, &settings.ModeSetBy,
+		&settings.DataSharingAllowed, &settings.ConsentTermsVersion, &consentSetAt, &settings.ConsentSetBy, &settings.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings.ModeSetAt = modeSetAt.Time
+	settings.ConsentSetAt = consentSetAt.Time
+	return &settings, nil
+}
+
+// SaveMode stores the agent mode along with what set it. The consent is kept.
+func (s *AgentSettingsStore) SaveMode(ctx context.Context, mode models.AgentMode, setBy string) error {
+	_, err := s.db.ExecContext(ctx, queryUpsertAgentMode, string(mode), setBy)
+	return err
+}
+
+// SaveConsent stores the data-sharing consent, the version of the terms it was given for and what set it.
+// The mode is kept.
+func (s *AgentSettingsStore) SaveConsent(ctx context.Context, allowed bool, termsVersion, setBy string) error {
+	_, err := s.db.ExecContext(ctx, queryUpsertAgentConsent, allowed, termsVersion, setBy)
+	return err
+}
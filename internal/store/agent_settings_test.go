@@ -0,0 +1,74 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AgentSettingsStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrations.Run(ctx, db)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	It("should return ErrNotFound when nothing was set", func() {
+		_, err := s.AgentSettings().Get(ctx)
+		Expect(err).To(Equal(store.ErrNotFound))
+	})
+
+	It("should save the mode without consent", func() {
+		Expect(s.AgentSettings().SaveMode(ctx, models.AgentModeDisconnected, "api")).To(Succeed())
+
+		settings, err := s.AgentSettings().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(settings.Mode).To(Equal(models.AgentModeDisconnected))
+		Expect(settings.ModeSetBy).To(Equal("api"))
+		Expect(settings.ModeSetAt.IsZero()).To(BeFalse())
+		Expect(settings.DataSharingAllowed).To(BeFalse())
+		Expect(settings.ConsentSetAt.IsZero()).To(BeTrue())
+	})
+
+	It("should keep the mode and the consent when the other is saved", func() {
+		Expect(s.AgentSettings().SaveMode(ctx, models.AgentModeConnected, "api")).To(Succeed())
+		Expect(s.AgentSettings().SaveConsent(ctx, true, "v1", "consent api")).To(Succeed())
+		Expect(s.AgentSettings().SaveMode(ctx, models.AgentModeDisconnected, "api")).To(Succeed())
+
+		settings, err := s.AgentSettings().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(settings.Mode).To(Equal(models.AgentModeDisconnected))
+		Expect(settings.DataSharingAllowed).To(BeTrue())
+		Expect(settings.ConsentTermsVersion).To(Equal("v1"))
+		Expect(settings.ConsentSetBy).To(Equal("consent api"))
+		Expect(settings.ConsentSetAt.IsZero()).To(BeFalse())
+	})
+})
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// AnonymizationStore handles the storage of the anonymization salt using DuckDB.
+type AnonymizationStore struct {
+	db *sql.DB
+}
+
+// NewAnonymizationStore creates a new anonymization store.
+func NewAnonymizationStore(db *sql.DB) *AnonymizationStore {
+	return &AnonymizationStore{db: db}
+}
+
+// GetSalt retrieves the anonymization salt.
+func (s *AnonymizationStore) GetSalt(ctx context.Context) ([]byte, error) {
+	var salt []byte
+	err := s.db.QueryRowContext(ctx, queryGetAnonymizationSalt).Scan(&salt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// CreateSalt stores the anonymization salt unless one is stored already, which is kept.
+func (s *AnonymizationStore) CreateSalt(ctx context.Context, salt []byte) error {
+	_, err := s.db.ExecContext(ctx, queryInsertAnonymizationSalt, salt)
+	return err
+}
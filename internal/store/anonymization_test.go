@@ -0,0 +1,56 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AnonymizationStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrations.Run(ctx, db)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	It("should return ErrNotFound when no salt was created", func() {
+		_, err := s.Anonymization().GetSalt(ctx)
+		Expect(err).To(Equal(store.ErrNotFound))
+	})
+
+	It("should keep the first salt created", func() {
+		Expect(s.Anonymization().CreateSalt(ctx, []byte("first"))).To(Succeed())
+		Expect(s.Anonymization().CreateSalt(ctx, []byte("second"))).To(Succeed())
+
+		salt, err := s.Anonymization().GetSalt(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(salt).To(Equal([]byte("first")))
+	})
+})
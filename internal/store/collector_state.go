@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// CollectorStateStore handles the collector state storage using DuckDB.
+type CollectorStateStore struct {
+	db *sql.DB
+}
+
+// NewCollectorStateStore creates a new collector state store.
+func NewCollectorStateStore(db *sql.DB) *CollectorStateStore {
+	return &CollectorStateStore{db: db}
+}
+
+// Get retrieves the last collector state and its error message, empty unless the state is error.
+func (s *CollectorStateStore) Get(ctx context.Context) (models.CollectorState, string, error) {
+	var (
+		state    models.CollectorState
+		errorMsg sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, queryGetCollectorState).Scan(&state, &errorMsg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", ErrNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return state, errorMsg.String, nil
+}
+
+// Save stores the collector state along with its error message.
+func (s *CollectorStateStore) Save(ctx context.Context, state models.CollectorState, errorMsg string) error {
+	var errorValue any
+	if errorMsg != "" {
+		errorValue = errorMsg
+	}
+
+	_, err := s.db.ExecContext(ctx, queryUpsertCollectorState, string(state), errorValue)
+	return err
+}
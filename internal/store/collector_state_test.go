@@ -0,0 +1,67 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CollectorStateStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrations.Run(ctx, db)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	It("should return ErrNotFound when no state was saved", func() {
+		_, _, err := s.CollectorState().Get(ctx)
+		Expect(err).To(Equal(store.ErrNotFound))
+	})
+
+	It("should save the state with its error", func() {
+		Expect(s.CollectorState().Save(ctx, models.CollectorStateError, "connection refused")).To(Succeed())
+
+		state, errorMsg, err := s.CollectorState().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(models.CollectorStateError))
+		Expect(errorMsg).To(Equal("connection refused"))
+	})
+
+	It("should replace the state", func() {
+		Expect(s.CollectorState().Save(ctx, models.CollectorStateError, "connection refused")).To(Succeed())
+		Expect(s.CollectorState().Save(ctx, models.CollectorStateReady, "")).To(Succeed())
+
+		state, errorMsg, err := s.CollectorState().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(models.CollectorStateReady))
+		Expect(errorMsg).To(BeEmpty())
+	})
+})
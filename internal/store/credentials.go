@@ -4,21 +4,29 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
 )
 
-// ErrNotFound is returned when a record is not found.
-var ErrNotFound = errors.New("not found")
+var (
+	// ErrNotFound is returned when a record is not found.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when creating a record which already exists.
+	ErrAlreadyExists = errors.New("already exists")
+)
 
 // CredentialsStore handles credentials storage using DuckDB.
+// Passwords are encrypted with the keyring before being written.
 type CredentialsStore struct {
-	db *sql.DB
+	db      *sql.DB
+	keyring *encryption.Keyring
 }
 
 // NewCredentialsStore creates a new credentials store.
-func NewCredentialsStore(db *sql.DB) *CredentialsStore {
-	return &CredentialsStore{db: db}
+func NewCredentialsStore(db *sql.DB, keyring *encryption.Keyring) *CredentialsStore {
+	return &CredentialsStore{db: db, keyring: keyring}
 }
 
 // Get retrieves the stored credentials.
@@ -26,20 +34,35 @@ func (s *CredentialsStore) Get(ctx context.Context) (*models.Credentials, error)
 	row := s.db.QueryRowContext(ctx, queryGetCredentials)
 
 	var c models.Credentials
-	err := row.Scan(&c.URL, &c.Username, &c.Password, &c.IsDataSharingAllowed, &c.CreatedAt, &c.UpdatedAt)
+	var keyID sql.NullString
+	err := row.Scan(&c.URL, &c.Username, &c.Password, &keyID, &c.CACert, &c.Thumbprint, &c.IsDataSharingAllowed, &c.CreatedAt, &c.UpdatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
+
+	if keyID.Valid {
+		password, err := s.keyring.Decrypt(keyID.String, c.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt password: %w", err)
+		}
+		c.Password = string(password)
+	}
+
 	return &c, nil
 }
 
 // Save stores or updates the credentials.
 func (s *CredentialsStore) Save(ctx context.Context, creds *models.Credentials) error {
-	_, err := s.db.ExecContext(ctx, queryUpsertCredentials,
-		creds.URL, creds.Username, creds.Password, creds.IsDataSharingAllowed)
+	keyID, password, err := s.keyring.Encrypt([]byte(creds.Password))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt password: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, queryUpsertCredentials,
+		creds.URL, creds.Username, password, keyID, creds.CACert, creds.Thumbprint, creds.IsDataSharingAllowed)
 	return err
 }
 
@@ -48,3 +71,46 @@ func (s *CredentialsStore) Delete(ctx context.Context) error {
 	_, err := s.db.ExecContext(ctx, queryDeleteCredentials)
 	return err
 }
+
+// ReEncrypt encrypts the stored password with the primary key of the keyring.
+// Plaintext passwords and passwords encrypted with a previous key are rewritten;
+// it is a no-op when no credentials are stored or the password already uses the primary key.
+func (s *CredentialsStore) ReEncrypt(ctx context.Context) (bool, error) {
+	return s.reEncrypt(ctx, s.db)
+}
+
+func (s *CredentialsStore) reEncrypt(ctx context.Context, q querier) (bool, error) {
+	row := q.QueryRowContext(ctx, queryGetCredentialsPassword)
+
+	var stored string
+	var keyID sql.NullString
+	err := row.Scan(&stored, &keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if keyID.Valid && keyID.String == s.keyring.PrimaryKeyID() {
+		return false, nil
+	}
+
+	password := []byte(stored)
+	if keyID.Valid {
+		password, err = s.keyring.Decrypt(keyID.String, stored)
+		if err != nil {
+			return false, fmt.Errorf("failed to decrypt password: %w", err)
+		}
+	}
+
+	newKeyID, encrypted, err := s.keyring.Encrypt(password)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt password: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, queryUpdateCredentialsPassword, encrypted, newKeyID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -23,6 +24,7 @@ var _ = Describe("CredentialsStore", func() {
 		s     *store.Store
 		db    *sql.DB
 		creds *models.Credentials
+		key   *encryption.Key
 	)
 
 	BeforeEach(func() {
@@ -35,7 +37,10 @@ var _ = Describe("CredentialsStore", func() {
 		err = migrations.Run(ctx, db)
 		Expect(err).NotTo(HaveOccurred())
 
-		s = store.NewStore(db)
+		key, err = encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
 
 		creds = &models.Credentials{
 			URL:                  "https://vcenter.example.com",
@@ -100,6 +105,17 @@ var _ = Describe("CredentialsStore", func() {
 			Expect(retrieved.IsDataSharingAllowed).To(Equal(creds.IsDataSharingAllowed))
 		})
 
+		It("should retrieve the TLS settings", func() {
+			creds.CACert = "-----BEGIN CERTIFICATE-----"
+			creds.Thumbprint = "AB:CD"
+			Expect(s.Credentials().Save(ctx, creds)).To(Succeed())
+
+			retrieved, err := s.Credentials().Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.CACert).To(Equal(creds.CACert))
+			Expect(retrieved.Thumbprint).To(Equal(creds.Thumbprint))
+		})
+
 		It("should have timestamps set by database", func() {
 			err := s.Credentials().Save(ctx, creds)
 			Expect(err).NotTo(HaveOccurred())
@@ -173,4 +189,80 @@ var _ = Describe("CredentialsStore", func() {
 			Expect(retrieved.Username).To(Equal(newCreds.Username))
 		})
 	})
+
+	Describe("Encryption", func() {
+		It("should not store the password in plaintext", func() {
+			err := s.Credentials().Save(ctx, creds)
+			Expect(err).NotTo(HaveOccurred())
+
+			var password, keyID string
+			err = db.QueryRowContext(ctx, "SELECT password, key_id FROM credentials WHERE id = 1").Scan(&password, &keyID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(password).NotTo(ContainSubstring(creds.Password))
+			Expect(keyID).To(Equal(key.ID))
+		})
+
+		It("should fail to read credentials encrypted with an unknown key", func() {
+			err := s.Credentials().Save(ctx, creds)
+			Expect(err).NotTo(HaveOccurred())
+
+			other, err := encryption.GenerateKey()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = store.NewStore(db, encryption.NewKeyring(other)).Credentials().Get(ctx)
+			Expect(err).To(MatchError(encryption.ErrUnknownKey))
+		})
+	})
+
+	Describe("ReEncrypt", func() {
+		It("should do nothing when no credentials exist", func() {
+			updated, err := s.Credentials().ReEncrypt(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeFalse())
+		})
+
+		It("should encrypt a plaintext password", func() {
+			_, err := db.ExecContext(ctx, "INSERT INTO credentials (id, url, username, password) VALUES (1, ?, ?, ?)",
+				creds.URL, creds.Username, creds.Password)
+			Expect(err).NotTo(HaveOccurred())
+
+			retrieved, err := s.Credentials().Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.Password).To(Equal(creds.Password))
+
+			updated, err := s.Credentials().ReEncrypt(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeTrue())
+
+			var password string
+			err = db.QueryRowContext(ctx, "SELECT password FROM credentials WHERE id = 1").Scan(&password)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(password).NotTo(Equal(creds.Password))
+
+			retrieved, err = s.Credentials().Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.Password).To(Equal(creds.Password))
+		})
+
+		It("should re-encrypt the password with the new primary key", func() {
+			err := s.Credentials().Save(ctx, creds)
+			Expect(err).NotTo(HaveOccurred())
+
+			newKey, err := encryption.GenerateKey()
+			Expect(err).NotTo(HaveOccurred())
+			rotated := store.NewStore(db, encryption.NewKeyring(newKey, key))
+
+			updated, err := rotated.Credentials().ReEncrypt(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeTrue())
+
+			updated, err = rotated.Credentials().ReEncrypt(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeFalse())
+
+			retrieved, err := store.NewStore(db, encryption.NewKeyring(newKey)).Credentials().Get(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.Password).To(Equal(creds.Password))
+		})
+	})
 })
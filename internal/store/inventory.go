@@ -2,15 +2,20 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 )
 
-// InventoryStore handles inventory storage using DuckDB.
+// InventoryStore handles inventory snapshots storage using DuckDB.
+// Every collection is stored as a new snapshot; the latest one is the current inventory.
+// The snapshots are those of the vCenter of the collector, or of the named vCenter the store is scoped to.
 type InventoryStore struct {
-	db *sql.DB
+	db      *sql.DB
+	vcenter string
 }
 
 // NewInventoryStore creates a new inventory store.
@@ -18,12 +23,80 @@ func NewInventoryStore(db *sql.DB) *InventoryStore {
 	return &InventoryStore{db: db}
 }
 
-// Get retrieves the stored inventory.
-func (s *InventoryStore) Get(ctx context.Context) (*models.Inventory, error) {
-	row := s.db.QueryRowContext(ctx, queryGetInventory)
+// VCenter returns the store of the snapshots of the named vCenter.
+func (s *InventoryStore) VCenter(name string) *InventoryStore {
+	return &InventoryStore{db: s.db, vcenter: name}
+}
+
+// Latest retrieves the most recent inventory snapshot.
+func (s *InventoryStore) Latest(ctx context.Context) (*models.Inventory, error) {
+	return scanInventory(s.db.QueryRowContext(ctx, queryGetLatestInventorySnapshot, s.vcenter))
+}
+
+// Get retrieves the inventory snapshot with the given id.
+func (s *InventoryStore) Get(ctx context.Context, id int64) (*models.Inventory, error) {
+	return scanInventory(s.db.QueryRowContext(ctx, queryGetInventorySnapshot, id, s.vcenter))
+}
+
+// List returns all inventory snapshots, newest first.
+// The inventory data is not loaded.
+func (s *InventoryStore) List(ctx context.Context) ([]models.Inventory, error) {
+	rows, err := s.db.QueryContext(ctx, queryListInventorySnapshots, s.vcenter)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	snapshots := []models.Inventory{}
+	for rows.Next() {
+		var inv models.Inventory
+		if err := rows.Scan(&inv.ID, &inv.StartedAt, &inv.CompletedAt, &inv.VCenterURL, &inv.Hash, &inv.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, inv)
+	}
+	return snapshots, rows.Err()
+}
+
+// Save stores a new inventory snapshot and returns its id.
+// The hash is computed from the data when not set.
+func (s *InventoryStore) Save(ctx context.Context, inv *models.Inventory) (int64, error) {
+	hash := inv.Hash
+	if hash == "" {
+		hash = fmt.Sprintf("%x", sha256.Sum256(inv.Data))
+	}
 
+	// a nil slice would be written as an empty blob
+	var vms any
+	if inv.VMs != nil {
+		vms = inv.VMs
+	}
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, queryInsertInventorySnapshot,
+		inv.StartedAt, inv.CompletedAt, inv.VCenterURL, hash, inv.Data, vms, s.vcenter).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Prune deletes all but the keep most recent snapshots and returns the number of deleted snapshots.
+func (s *InventoryStore) Prune(ctx context.Context, keep int) (int64, error) {
+	if keep < 1 {
+		return 0, fmt.Errorf("invalid number of snapshots to keep %d: must be at least 1", keep)
+	}
+
+	res, err := s.db.ExecContext(ctx, queryPruneInventorySnapshots, s.vcenter, s.vcenter, keep)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanInventory(row *sql.Row) (*models.Inventory, error) {
 	var inv models.Inventory
-	err := row.Scan(&inv.Data, &inv.CreatedAt, &inv.UpdatedAt)
+	err := row.Scan(&inv.ID, &inv.StartedAt, &inv.CompletedAt, &inv.VCenterURL, &inv.Hash, &inv.Data, &inv.VMs, &inv.CreatedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
@@ -32,9 +105,3 @@ func (s *InventoryStore) Get(ctx context.Context) (*models.Inventory, error) {
 	}
 	return &inv, nil
 }
-
-// Save stores or updates the inventory.
-func (s *InventoryStore) Save(ctx context.Context, data []byte) error {
-	_, err := s.db.ExecContext(ctx, queryUpsertInventory, data)
-	return err
-}
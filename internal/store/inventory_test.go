@@ -2,10 +2,15 @@ package store_test
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"fmt"
+	"time"
 
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -27,7 +32,10 @@ var _ = Describe("InventoryStore", func() {
 		err = migrations.Run(ctx, db)
 		Expect(err).NotTo(HaveOccurred())
 
-		s = store.NewStore(db)
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
 	})
 
 	AfterEach(func() {
@@ -36,56 +44,190 @@ var _ = Describe("InventoryStore", func() {
 		}
 	})
 
+	newSnapshot := func(data string) *models.Inventory {
+		return &models.Inventory{
+			StartedAt:   time.Now().Add(-time.Minute),
+			CompletedAt: time.Now(),
+			VCenterURL:  "https://vcenter.example.com",
+			Data:        []byte(data),
+		}
+	}
+
 	Describe("Save", func() {
-		It("should save inventory successfully", func() {
-			data := []byte(`{"vms": [{"name": "vm1"}]}`)
-			err := s.Inventory().Save(ctx, data)
+		It("should save a snapshot and return its id", func() {
+			id, err := s.Inventory().Save(ctx, newSnapshot(`{"vms": [{"name": "vm1"}]}`))
 			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(BeNumerically(">", 0))
 		})
 
-		It("should update inventory on second save (upsert)", func() {
-			// First save
-			data1 := []byte(`{"vms": [{"name": "vm1"}]}`)
-			err := s.Inventory().Save(ctx, data1)
+		It("should keep previous snapshots on second save", func() {
+			id1, err := s.Inventory().Save(ctx, newSnapshot(`{"vms": [{"name": "vm1"}]}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			id2, err := s.Inventory().Save(ctx, newSnapshot(`{"vms": [{"name": "vm1"}, {"name": "vm2"}]}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id2).To(BeNumerically(">", id1))
+
+			first, err := s.Inventory().Get(ctx, id1)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(first.Data).To(Equal([]byte(`{"vms": [{"name": "vm1"}]}`)))
+		})
 
-			// Update inventory
-			data2 := []byte(`{"vms": [{"name": "vm1"}, {"name": "vm2"}]}`)
-			err = s.Inventory().Save(ctx, data2)
+		It("should compute the hash of the data", func() {
+			data := `{"vms": []}`
+			id, err := s.Inventory().Save(ctx, newSnapshot(data))
 			Expect(err).NotTo(HaveOccurred())
 
-			// Verify updated values
-			retrieved, err := s.Inventory().Get(ctx)
+			retrieved, err := s.Inventory().Get(ctx, id)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(retrieved.Data).To(Equal(data2))
+			Expect(retrieved.Hash).To(Equal(fmt.Sprintf("%x", sha256.Sum256([]byte(data)))))
 		})
 	})
 
 	Describe("Get", func() {
+		It("should return ErrNotFound when the snapshot does not exist", func() {
+			_, err := s.Inventory().Get(ctx, 42)
+			Expect(err).To(Equal(store.ErrNotFound))
+		})
+
+		It("should retrieve a saved snapshot", func() {
+			snapshot := newSnapshot(`{"vms": [{"name": "vm1"}]}`)
+			id, err := s.Inventory().Save(ctx, snapshot)
+			Expect(err).NotTo(HaveOccurred())
+
+			retrieved, err := s.Inventory().Get(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.ID).To(Equal(id))
+			Expect(retrieved.Data).To(Equal(snapshot.Data))
+			Expect(retrieved.VCenterURL).To(Equal(snapshot.VCenterURL))
+			Expect(retrieved.StartedAt).To(BeTemporally("~", snapshot.StartedAt, time.Millisecond))
+			Expect(retrieved.CompletedAt).To(BeTemporally("~", snapshot.CompletedAt, time.Millisecond))
+			Expect(retrieved.CreatedAt).NotTo(BeZero())
+		})
+
+		It("should retrieve the vm summaries saved with the snapshot", func() {
+			snapshot := newSnapshot(`{}`)
+			snapshot.VMs = []byte(`[{"id": "vm-1"}]`)
+			id, err := s.Inventory().Save(ctx, snapshot)
+			Expect(err).NotTo(HaveOccurred())
+
+			retrieved, err := s.Inventory().Get(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.VMs).To(Equal(snapshot.VMs))
+
+			id, err = s.Inventory().Save(ctx, newSnapshot(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			retrieved, err = s.Inventory().Get(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retrieved.VMs).To(BeNil())
+		})
+	})
+
+	Describe("Latest", func() {
 		It("should return ErrNotFound when no inventory exists", func() {
-			_, err := s.Inventory().Get(ctx)
+			_, err := s.Inventory().Latest(ctx)
 			Expect(err).To(Equal(store.ErrNotFound))
 		})
 
-		It("should retrieve saved inventory", func() {
-			data := []byte(`{"vms": [{"name": "vm1"}]}`)
-			err := s.Inventory().Save(ctx, data)
+		It("should return the most recent snapshot", func() {
+			_, err := s.Inventory().Save(ctx, newSnapshot(`{"vms": [{"name": "vm1"}]}`))
+			Expect(err).NotTo(HaveOccurred())
+			id, err := s.Inventory().Save(ctx, newSnapshot(`{"vms": [{"name": "vm2"}]}`))
 			Expect(err).NotTo(HaveOccurred())
 
-			retrieved, err := s.Inventory().Get(ctx)
+			latest, err := s.Inventory().Latest(ctx)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(retrieved.Data).To(Equal(data))
+			Expect(latest.ID).To(Equal(id))
+			Expect(latest.Data).To(Equal([]byte(`{"vms": [{"name": "vm2"}]}`)))
 		})
+	})
 
-		It("should have timestamps set by database", func() {
-			data := []byte(`{"vms": []}`)
-			err := s.Inventory().Save(ctx, data)
+	Describe("List", func() {
+		It("should return an empty list when no inventory exists", func() {
+			snapshots, err := s.Inventory().List(ctx)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshots).To(BeEmpty())
+		})
 
-			retrieved, err := s.Inventory().Get(ctx)
+		It("should list snapshots newest first without data", func() {
+			id1, err := s.Inventory().Save(ctx, newSnapshot(`{"vms": [{"name": "vm1"}]}`))
 			Expect(err).NotTo(HaveOccurred())
-			Expect(retrieved.CreatedAt).NotTo(BeZero())
-			Expect(retrieved.UpdatedAt).NotTo(BeZero())
+			id2, err := s.Inventory().Save(ctx, newSnapshot(`{"vms": [{"name": "vm2"}]}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			snapshots, err := s.Inventory().List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshots).To(HaveLen(2))
+			Expect(snapshots[0].ID).To(Equal(id2))
+			Expect(snapshots[1].ID).To(Equal(id1))
+			Expect(snapshots[0].Data).To(BeNil())
+			Expect(snapshots[0].Hash).NotTo(BeEmpty())
+		})
+	})
+
+	Describe("Prune", func() {
+		It("should keep the most recent snapshots", func() {
+			var ids []int64
+			for i := 0; i < 5; i++ {
+				id, err := s.Inventory().Save(ctx, newSnapshot(fmt.Sprintf(`{"run": %d}`, i)))
+				Expect(err).NotTo(HaveOccurred())
+				ids = append(ids, id)
+			}
+
+			pruned, err := s.Inventory().Prune(ctx, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pruned).To(Equal(int64(3)))
+
+			snapshots, err := s.Inventory().List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshots).To(HaveLen(2))
+			Expect(snapshots[0].ID).To(Equal(ids[4]))
+			Expect(snapshots[1].ID).To(Equal(ids[3]))
+		})
+
+		It("should reject keeping no snapshot", func() {
+			_, err := s.Inventory().Prune(ctx, 0)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should only prune the snapshots of the same vCenter", func() {
+			for i := 0; i < 3; i++ {
+				_, err := s.Inventory().Save(ctx, newSnapshot(fmt.Sprintf(`{"run": %d}`, i)))
+				Expect(err).NotTo(HaveOccurred())
+			}
+			_, err := s.Inventory().VCenter("east").Save(ctx, newSnapshot(`{"run": "east"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			pruned, err := s.Inventory().Prune(ctx, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pruned).To(Equal(int64(2)))
+
+			_, err = s.Inventory().VCenter("east").Latest(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("VCenter", func() {
+		It("should keep the snapshots of each vCenter apart", func() {
+			id, err := s.Inventory().Save(ctx, newSnapshot(`{"vcenter": "collector"}`))
+			Expect(err).NotTo(HaveOccurred())
+			eastID, err := s.Inventory().VCenter("east").Save(ctx, newSnapshot(`{"vcenter": "east"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			latest, err := s.Inventory().Latest(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(latest.ID).To(Equal(id))
+
+			snapshots, err := s.Inventory().VCenter("east").List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshots).To(HaveLen(1))
+			Expect(snapshots[0].ID).To(Equal(eastID))
+
+			_, err = s.Inventory().Get(ctx, eastID)
+			Expect(err).To(MatchError(store.ErrNotFound))
+			_, err = s.Inventory().VCenter("west").Latest(ctx)
+			Expect(err).To(MatchError(store.ErrNotFound))
 		})
 	})
 })
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// InventoryUploadStore handles the storage of the inventory uploads to console using DuckDB.
+// There is one upload per source, so an agent registered to another source sends its inventory again.
+type InventoryUploadStore struct {
+	db *sql.DB
+}
+
+// NewInventoryUploadStore creates a new inventory upload store.
+func NewInventoryUploadStore(db *sql.DB) *InventoryUploadStore {
+	return &InventoryUploadStore{db: db}
+}
+
+// Get retrieves the inventory upload of the source.
+func (s *InventoryUploadStore) Get(ctx context.Context, sourceID string) (*models.InventoryUpload, error) {
+	var (
+		upload       models.InventoryUpload
+		deliveredAt  sql.NullTime
+		pendingSince sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, queryGetInventoryUpload, sourceID).Scan(
+		&upload.SourceID, &upload.DeliveredHash, &deliveredAt, &upload.PendingHash, &pendingSince,
+		&upload.Attempts, &upload.Error)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	upload.DeliveredAt = deliveredAt.Time
+	upload.PendingSince = pendingSince.Time
+	return &upload, nil
+}
+
+// MarkPending records the inventory with the given hash as waiting to be delivered.
+// It replaces the previous pending inventory and resets the failed attempts.
+func (s *InventoryUploadStore) MarkPending(ctx context.Context, sourceID, hash string) error {
+	_, err := s.db.ExecContext(ctx, queryUpsertInventoryUploadPending, sourceID, hash)
+	return err
+}
+
+// RecordFailure records a failed attempt to deliver the pending inventory.
+func (s *InventoryUploadStore) RecordFailure(ctx context.Context, sourceID, errorMsg string) error {
+	_, err := s.db.ExecContext(ctx, queryUpdateInventoryUploadFailure, errorMsg, sourceID)
+	return err
+}
+
+// ClearPending forgets the pending inventory. The last delivered inventory is kept.
+func (s *InventoryUploadStore) ClearPending(ctx context.Context, sourceID string) error {
+	_, err := s.db.ExecContext(ctx, queryClearInventoryUploadPending, sourceID)
+	return err
+}
+
+// MarkDelivered records the inventory with the given hash as delivered, which clears the pending inventory.
+func (s *InventoryUploadStore) MarkDelivered(ctx context.Context, sourceID, hash string, deliveredAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, queryUpsertInventoryUploadDelivered, sourceID, hash, deliveredAt)
+	return err
+}
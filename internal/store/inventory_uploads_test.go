@@ -0,0 +1,111 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InventoryUploadStore", func() {
+	const sourceID = "source-1"
+
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrations.Run(ctx, db)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	It("should return ErrNotFound when nothing was sent", func() {
+		_, err := s.InventoryUploads().Get(ctx, sourceID)
+		Expect(err).To(Equal(store.ErrNotFound))
+	})
+
+	It("should record the failed attempts of the pending inventory", func() {
+		Expect(s.InventoryUploads().MarkPending(ctx, sourceID, "hash-1")).To(Succeed())
+		Expect(s.InventoryUploads().RecordFailure(ctx, sourceID, "connection refused")).To(Succeed())
+		Expect(s.InventoryUploads().RecordFailure(ctx, sourceID, "service unavailable")).To(Succeed())
+
+		upload, err := s.InventoryUploads().Get(ctx, sourceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(upload.Pending()).To(BeTrue())
+		Expect(upload.PendingHash).To(Equal("hash-1"))
+		Expect(upload.PendingSince.IsZero()).To(BeFalse())
+		Expect(upload.Attempts).To(Equal(2))
+		Expect(upload.Error).To(Equal("service unavailable"))
+		Expect(upload.DeliveredHash).To(BeEmpty())
+
+		By("replacing the pending inventory")
+		Expect(s.InventoryUploads().MarkPending(ctx, sourceID, "hash-2")).To(Succeed())
+
+		upload, err = s.InventoryUploads().Get(ctx, sourceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(upload.PendingHash).To(Equal("hash-2"))
+		Expect(upload.Attempts).To(BeZero())
+		Expect(upload.Error).To(BeEmpty())
+	})
+
+	It("should clear the pending inventory when delivered", func() {
+		deliveredAt := time.Now().UTC().Truncate(time.Microsecond)
+
+		Expect(s.InventoryUploads().MarkPending(ctx, sourceID, "hash-1")).To(Succeed())
+		Expect(s.InventoryUploads().RecordFailure(ctx, sourceID, "connection refused")).To(Succeed())
+		Expect(s.InventoryUploads().MarkDelivered(ctx, sourceID, "hash-1", deliveredAt)).To(Succeed())
+
+		upload, err := s.InventoryUploads().Get(ctx, sourceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(upload.Pending()).To(BeFalse())
+		Expect(upload.DeliveredHash).To(Equal("hash-1"))
+		Expect(upload.DeliveredAt).To(BeTemporally("~", deliveredAt, time.Millisecond))
+		Expect(upload.Attempts).To(BeZero())
+		Expect(upload.Error).To(BeEmpty())
+	})
+
+	It("should keep the delivered inventory when the pending one is cleared", func() {
+		Expect(s.InventoryUploads().MarkDelivered(ctx, sourceID, "hash-1", time.Now())).To(Succeed())
+		Expect(s.InventoryUploads().MarkPending(ctx, sourceID, "hash-2")).To(Succeed())
+		Expect(s.InventoryUploads().RecordFailure(ctx, sourceID, "connection refused")).To(Succeed())
+		Expect(s.InventoryUploads().ClearPending(ctx, sourceID)).To(Succeed())
+
+		upload, err := s.InventoryUploads().Get(ctx, sourceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(upload.Pending()).To(BeFalse())
+		Expect(upload.DeliveredHash).To(Equal("hash-1"))
+		Expect(upload.Attempts).To(BeZero())
+		Expect(upload.Error).To(BeEmpty())
+	})
+
+	It("should keep the uploads of each source", func() {
+		Expect(s.InventoryUploads().MarkDelivered(ctx, sourceID, "hash-1", time.Now())).To(Succeed())
+
+		_, err := s.InventoryUploads().Get(ctx, "source-2")
+		Expect(err).To(Equal(store.ErrNotFound))
+	})
+})
@@ -0,0 +1,11 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+// RunUntil executes the pending migrations up to the given version, to get the database of a previous version.
+func RunUntil(ctx context.Context, db *sql.DB, version int) error {
+	return run(ctx, db, version)
+}
@@ -6,6 +6,7 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"math"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -19,6 +20,11 @@ var migrationFiles embed.FS
 
 // Run executes all pending migrations in order.
 func Run(ctx context.Context, db *sql.DB) error {
+	return run(ctx, db, math.MaxInt)
+}
+
+// run executes the pending migrations in order, up to the given version.
+func run(ctx context.Context, db *sql.DB, until int) error {
 	// Ensure migrations tracking table exists
 	if err := createMigrationsTable(ctx, db); err != nil {
 		return fmt.Errorf("creating migrations table: %w", err)
@@ -44,6 +50,10 @@ func Run(ctx context.Context, db *sql.DB) error {
 			continue
 		}
 
+		if version > until {
+			break
+		}
+
 		if applied[version] {
 			zap.S().Debugf("migration %03d already applied, skipping", version)
 			continue
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/store"
 	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
@@ -54,18 +55,78 @@ var _ = Describe("Migrations", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("should create inventory table", func() {
+		It("should create inventory snapshots table", func() {
 			err := migrations.Run(ctx, db)
 			Expect(err).NotTo(HaveOccurred())
 
-			// Verify inventory table exists by inserting data
+			// Verify inventory_snapshots table exists by inserting data
 			_, err = db.ExecContext(ctx, `
-				INSERT INTO inventory (id, data)
-				VALUES (1, 'test data')
+				INSERT INTO inventory_snapshots (started_at, completed_at, hash, data)
+				VALUES (now(), now(), 'hash', 'test data')
 			`)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("should carry over the data-sharing consent stored with the credentials", func() {
+			err := migrations.Run(ctx, db)
+			Expect(err).NotTo(HaveOccurred())
+
+			// replay the agent settings migration over credentials saved by a previous version
+			_, err = db.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = 13`)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = db.ExecContext(ctx, `
+				INSERT INTO credentials (id, url, username, password, is_data_sharing_allowed)
+				VALUES (1, 'https://vcenter.example.com', 'admin', 'secret', true)
+			`)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = migrations.Run(ctx, db)
+			Expect(err).NotTo(HaveOccurred())
+
+			var (
+				allowed bool
+				setBy   string
+			)
+			err = db.QueryRowContext(ctx, `SELECT data_sharing_allowed, consent_set_by FROM agent_settings`).Scan(&allowed, &setBy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(allowed).To(BeTrue())
+			Expect(setBy).To(Equal("credentials"))
+		})
+
+		It("should keep the last inventory of the named vCenters as their first snapshot", func() {
+			// the named vCenters collected by a previous version kept their last inventory only
+			err := migrations.RunUntil(ctx, db, 16)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = db.ExecContext(ctx, `
+				INSERT INTO vcenters (name, url, username, password, state, inventory, collected_at)
+				VALUES ('east', 'https://east.example.com/sdk', 'admin', 'secret', 'collected', '{"vcenter_id":"east"}', TIMESTAMP '2026-01-02 03:04:05');
+				INSERT INTO vcenters (name, url, username, password, state)
+				VALUES ('west', 'https://west.example.com/sdk', 'admin', 'secret', 'ready');
+			`)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = migrations.Run(ctx, db)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := store.NewStore(db, nil)
+			latest, err := s.Inventory().VCenter("east").Latest(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(latest.VCenterURL).To(Equal("https://east.example.com/sdk"))
+			Expect(string(latest.Data)).To(Equal(`{"vcenter_id":"east"}`))
+			Expect(latest.Hash).NotTo(BeEmpty())
+
+			vcenters, err := s.VCenters().List(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vcenters).To(HaveLen(2))
+			Expect(vcenters[0].CollectedAt).To(Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+			Expect(vcenters[1].CollectedAt.IsZero()).To(BeTrue())
+
+			_, err = s.Inventory().VCenter("west").Latest(ctx)
+			Expect(err).To(MatchError(store.ErrNotFound))
+			_, err = s.Inventory().Latest(ctx)
+			Expect(err).To(MatchError(store.ErrNotFound))
+		})
+
 		It("should be idempotent", func() {
 			// Run migrations twice
 			err := migrations.Run(ctx, db)
@@ -93,7 +154,7 @@ var _ = Describe("Migrations", func() {
 			}
 			Expect(rows.Err()).NotTo(HaveOccurred())
 
-			Expect(versions).To(ContainElements(1, 2))
+			Expect(versions).To(ContainElements(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17))
 		})
 	})
 })
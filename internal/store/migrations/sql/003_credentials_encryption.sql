@@ -0,0 +1,3 @@
+-- Id of the key used to encrypt the password. NULL marks a plaintext password
+-- written before encryption, which is re-encrypted on startup.
+ALTER TABLE credentials ADD COLUMN key_id VARCHAR;
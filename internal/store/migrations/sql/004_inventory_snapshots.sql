@@ -0,0 +1,19 @@
+-- Inventory snapshots, one per completed collection
+CREATE SEQUENCE IF NOT EXISTS inventory_snapshots_id_seq START 1;
+
+CREATE TABLE IF NOT EXISTS inventory_snapshots (
+    id BIGINT PRIMARY KEY DEFAULT nextval('inventory_snapshots_id_seq'),
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP NOT NULL,
+    vcenter_url VARCHAR NOT NULL DEFAULT '',
+    hash VARCHAR NOT NULL,
+    data BLOB NOT NULL,
+    created_at TIMESTAMP DEFAULT now()
+);
+
+-- Keep the inventory collected before snapshots were introduced
+INSERT INTO inventory_snapshots (started_at, completed_at, vcenter_url, hash, data, created_at)
+SELECT created_at, updated_at, COALESCE((SELECT url FROM credentials WHERE id = 1), ''), sha256(data), data, updated_at
+FROM inventory;
+
+DROP TABLE inventory;
@@ -0,0 +1,2 @@
+-- Per VM summary used to compare snapshots. NULL for snapshots taken before it was recorded.
+ALTER TABLE inventory_snapshots ADD COLUMN vms BLOB;
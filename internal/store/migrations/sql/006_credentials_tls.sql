@@ -0,0 +1,3 @@
+-- vCenter certificate verification settings
+ALTER TABLE credentials ADD COLUMN ca_cert VARCHAR;
+ALTER TABLE credentials ADD COLUMN thumbprint VARCHAR;
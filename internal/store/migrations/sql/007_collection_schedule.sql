@@ -0,0 +1,13 @@
+-- Periodic re-collection schedule, either an interval or a cron expression,
+-- along with the outcome of the last scheduled run
+CREATE TABLE IF NOT EXISTS collection_schedule (
+    id INTEGER PRIMARY KEY DEFAULT 1,
+    interval_seconds BIGINT,
+    cron VARCHAR,
+    last_run_started_at TIMESTAMP,
+    last_run_status VARCHAR,
+    last_run_error VARCHAR,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now(),
+    CHECK (id = 1)
+);
@@ -0,0 +1,17 @@
+-- History of the collections, manual and scheduled
+CREATE SEQUENCE IF NOT EXISTS collection_runs_id_seq START 1;
+
+CREATE TABLE IF NOT EXISTS collection_runs (
+    id BIGINT PRIMARY KEY DEFAULT nextval('collection_runs_id_seq'),
+    triggered_by VARCHAR NOT NULL,
+    vcenter_url VARCHAR NOT NULL,
+    state VARCHAR NOT NULL,
+    error VARCHAR,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,
+    vms INTEGER NOT NULL DEFAULT 0,
+    hosts INTEGER NOT NULL DEFAULT 0,
+    datastores INTEGER NOT NULL DEFAULT 0,
+    -- not a foreign key: snapshots are pruned while the history is kept
+    snapshot_id BIGINT
+);
@@ -0,0 +1,9 @@
+-- Last collector state, restored when the agent starts.
+-- The in-flight collection is the collection_runs row still running.
+CREATE TABLE IF NOT EXISTS collector_state (
+    id INTEGER PRIMARY KEY DEFAULT 1,
+    state VARCHAR NOT NULL,
+    error VARCHAR,
+    updated_at TIMESTAMP DEFAULT now(),
+    CHECK (id = 1)
+);
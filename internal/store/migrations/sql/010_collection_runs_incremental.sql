@@ -0,0 +1,2 @@
+-- Whether the collection only applied the vCenter changes since the previous one
+ALTER TABLE collection_runs ADD COLUMN incremental BOOLEAN DEFAULT false;
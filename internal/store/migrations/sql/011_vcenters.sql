@@ -0,0 +1,18 @@
+-- Named vCenter endpoints collected in addition to the vCenter of the collector,
+-- each with its credentials, collection state and last collected inventory.
+-- The password is encrypted like the collector credentials.
+CREATE TABLE IF NOT EXISTS vcenters (
+    name VARCHAR PRIMARY KEY,
+    url VARCHAR NOT NULL,
+    username VARCHAR NOT NULL,
+    password VARCHAR NOT NULL,
+    key_id VARCHAR,
+    ca_cert VARCHAR,
+    thumbprint VARCHAR,
+    state VARCHAR NOT NULL,
+    error VARCHAR,
+    inventory BLOB,
+    collected_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT now(),
+    updated_at TIMESTAMP DEFAULT now()
+);
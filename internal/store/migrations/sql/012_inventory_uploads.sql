@@ -0,0 +1,12 @@
+-- Inventory uploads to console, restored when the agent starts.
+-- The pending hash is the inventory not delivered yet, retried after the console recovers or the agent restarts.
+CREATE TABLE IF NOT EXISTS inventory_uploads (
+    source_id VARCHAR PRIMARY KEY,
+    delivered_hash VARCHAR,
+    delivered_at TIMESTAMP,
+    pending_hash VARCHAR,
+    pending_since TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error VARCHAR,
+    updated_at TIMESTAMP DEFAULT now()
+);
@@ -0,0 +1,17 @@
+-- Agent mode and data-sharing consent chosen through the API, restored when the agent starts.
+-- The consent previously stored along with the credentials is carried over.
+CREATE TABLE IF NOT EXISTS agent_settings (
+    id INTEGER PRIMARY KEY DEFAULT 1,
+    mode VARCHAR,
+    mode_set_at TIMESTAMP,
+    mode_set_by VARCHAR,
+    data_sharing_allowed BOOLEAN NOT NULL DEFAULT FALSE,
+    consent_set_at TIMESTAMP,
+    consent_set_by VARCHAR,
+    updated_at TIMESTAMP DEFAULT now(),
+    CHECK (id = 1)
+);
+
+INSERT INTO agent_settings (id, data_sharing_allowed, consent_set_at, consent_set_by)
+SELECT 1, TRUE, updated_at, 'credentials' FROM credentials WHERE is_data_sharing_allowed
+ON CONFLICT DO NOTHING;
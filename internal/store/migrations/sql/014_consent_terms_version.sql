@@ -0,0 +1,2 @@
+-- Version of the terms the data-sharing consent was given for
+ALTER TABLE agent_settings ADD COLUMN consent_terms_version VARCHAR;
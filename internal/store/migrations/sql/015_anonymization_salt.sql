@@ -0,0 +1,8 @@
+-- Salt of the hashes replacing the identifying fields of the inventory sent to console.
+-- It is kept so the anonymized inventories remain comparable across uploads and restarts.
+CREATE TABLE IF NOT EXISTS anonymization_salt (
+    id INTEGER PRIMARY KEY DEFAULT 1,
+    salt BLOB NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    CHECK (id = 1)
+);
@@ -0,0 +1,4 @@
+-- The named vCenters are collected like the vCenter of the collector: their snapshots and runs are kept
+-- along with those of the collector, with the name of the vCenter. It is empty for the vCenter of the collector.
+ALTER TABLE inventory_snapshots ADD COLUMN vcenter_name VARCHAR DEFAULT '';
+ALTER TABLE collection_runs ADD COLUMN vcenter_name VARCHAR DEFAULT '';
@@ -0,0 +1,8 @@
+-- The named vCenters keep their inventories as snapshots: the last inventory of each named vCenter
+-- becomes its first snapshot
+INSERT INTO inventory_snapshots (started_at, completed_at, vcenter_url, hash, data, vcenter_name)
+SELECT collected_at, collected_at, url, sha256(inventory), inventory, name
+FROM vcenters WHERE inventory IS NOT NULL;
+
+ALTER TABLE vcenters DROP COLUMN inventory;
+ALTER TABLE vcenters DROP COLUMN collected_at;
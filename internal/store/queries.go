@@ -3,32 +3,224 @@ package store
 // Credentials queries
 const (
 	queryGetCredentials = `
-		SELECT url, username, password, is_data_sharing_allowed, created_at, updated_at
+		SELECT url, username, password, key_id, COALESCE(ca_cert, ''), COALESCE(thumbprint, ''),
+			is_data_sharing_allowed, created_at, updated_at
 		FROM credentials WHERE id = 1`
 
 	queryUpsertCredentials = `
-		INSERT INTO credentials (id, url, username, password, is_data_sharing_allowed, updated_at)
-		VALUES (1, ?, ?, ?, ?, now())
+		INSERT INTO credentials (id, url, username, password, key_id, ca_cert, thumbprint, is_data_sharing_allowed, updated_at)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, now())
 		ON CONFLICT (id) DO UPDATE SET
 			url = EXCLUDED.url,
 			username = EXCLUDED.username,
 			password = EXCLUDED.password,
+			key_id = EXCLUDED.key_id,
+			ca_cert = EXCLUDED.ca_cert,
+			thumbprint = EXCLUDED.thumbprint,
 			is_data_sharing_allowed = EXCLUDED.is_data_sharing_allowed,
 			updated_at = now()`
 
+	queryGetCredentialsPassword = `SELECT password, key_id FROM credentials WHERE id = 1`
+
+	queryUpdateCredentialsPassword = `UPDATE credentials SET password = ?, key_id = ? WHERE id = 1`
+
 	queryDeleteCredentials = `DELETE FROM credentials WHERE id = 1`
 )
 
-// Inventory queries
+// Inventory snapshots queries
+const (
+	queryInsertInventorySnapshot = `
+		INSERT INTO inventory_snapshots (started_at, completed_at, vcenter_url, hash, data, vms, vcenter_name)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`
+
+	queryGetInventorySnapshot = `
+		SELECT id, started_at, completed_at, vcenter_url, hash, data, vms, created_at
+		FROM inventory_snapshots WHERE id = ? AND vcenter_name = ?`
+
+	queryGetLatestInventorySnapshot = `
+		SELECT id, started_at, completed_at, vcenter_url, hash, data, vms, created_at
+		FROM inventory_snapshots WHERE vcenter_name = ? ORDER BY id DESC LIMIT 1`
+
+	queryListInventorySnapshots = `
+		SELECT id, started_at, completed_at, vcenter_url, hash, created_at
+		FROM inventory_snapshots WHERE vcenter_name = ? ORDER BY id DESC`
+
+	queryPruneInventorySnapshots = `
+		DELETE FROM inventory_snapshots
+		WHERE vcenter_name = ?
+			AND id NOT IN (SELECT id FROM inventory_snapshots WHERE vcenter_name = ? ORDER BY id DESC LIMIT ?)`
+
+	queryDeleteInventorySnapshots = `DELETE FROM inventory_snapshots WHERE vcenter_name = ?`
+)
+
+// Collection schedule queries
+const (
+	queryGetCollectionSchedule = `
+		SELECT interval_seconds, cron, last_run_started_at, last_run_status, last_run_error, created_at, updated_at
+		FROM collection_schedule WHERE id = 1`
+
+	queryUpsertCollectionSchedule = `
+		INSERT INTO collection_schedule (id, interval_seconds, cron, updated_at)
+		VALUES (1, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			interval_seconds = EXCLUDED.interval_seconds,
+			cron = EXCLUDED.cron,
+			updated_at = EXCLUDED.updated_at`
+
+	queryUpdateCollectionScheduleLastRun = `
+		UPDATE collection_schedule
+		SET last_run_started_at = ?, last_run_status = ?, last_run_error = ?
+		WHERE id = 1`
+
+	queryDeleteCollectionSchedule = `DELETE FROM collection_schedule WHERE id = 1`
+)
+
+// Collection runs queries
+const (
+	queryInsertCollectionRun = `
+		INSERT INTO collection_runs (triggered_by, vcenter_url, state, started_at, vcenter_name)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id`
+
+	queryFinishCollectionRun = `
+		UPDATE collection_runs
+		SET state = ?, error = ?, ended_at = ?, vms = ?, hosts = ?, datastores = ?, snapshot_id = ?, incremental = ?
+		WHERE id = ?`
+
+	queryListCollectionRuns = `
+		SELECT id, triggered_by, vcenter_url, state, error, started_at, ended_at, vms, hosts, datastores, snapshot_id, incremental
+		FROM collection_runs WHERE vcenter_name = ? ORDER BY id DESC LIMIT ? OFFSET ?`
+
+	queryCountCollectionRuns = `SELECT count(*) FROM collection_runs WHERE vcenter_name = ?`
+
+	queryListRunningCollectionRuns = `
+		SELECT id, triggered_by, vcenter_url, state, error, started_at, ended_at, vms, hosts, datastores, snapshot_id, incremental
+		FROM collection_runs WHERE state = 'running' AND vcenter_name = ? ORDER BY id`
+
+	queryDeleteCollectionRuns = `DELETE FROM collection_runs WHERE vcenter_name = ?`
+)
+
+// Collector state queries
+const (
+	queryGetCollectorState = `SELECT state, error FROM collector_state WHERE id = 1`
+
+	queryUpsertCollectorState = `
+		INSERT INTO collector_state (id, state, error, updated_at)
+		VALUES (1, ?, ?, now())
+		ON CONFLICT (id) DO UPDATE SET
+			state = EXCLUDED.state,
+			error = EXCLUDED.error,
+			updated_at = now()`
+)
+
+// vCenters queries
 const (
-	queryGetInventory = `
-		SELECT data, created_at, updated_at
-		FROM inventory WHERE id = 1`
+	queryListVCenters = `
+		SELECT name, url, username, password, key_id, COALESCE(ca_cert, ''), COALESCE(thumbprint, ''),
+			state, COALESCE(error, ''),
+			(SELECT max(completed_at) FROM inventory_snapshots WHERE vcenter_name = vcenters.name),
+			created_at, updated_at
+		FROM vcenters ORDER BY name`
+
+	queryGetVCenter = `
+		SELECT name, url, username, password, key_id, COALESCE(ca_cert, ''), COALESCE(thumbprint, ''),
+			state, COALESCE(error, ''),
+			(SELECT max(completed_at) FROM inventory_snapshots WHERE vcenter_name = vcenters.name),
+			created_at, updated_at
+		FROM vcenters WHERE name = ?`
+
+	queryInsertVCenter = `
+		INSERT INTO vcenters (name, url, username, password, key_id, ca_cert, thumbprint, state)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO NOTHING`
+
+	queryUpdateVCenterCredentials = `
+		UPDATE vcenters
+		SET url = ?, username = ?, password = ?, key_id = ?, ca_cert = ?, thumbprint = ?, updated_at = now()
+		WHERE name = ?`
+
+	queryGetVCenterState = `SELECT state, COALESCE(error, '') FROM vcenters WHERE name = ?`
+
+	queryUpdateVCenterState = `UPDATE vcenters SET state = ?, error = ?, updated_at = now() WHERE name = ?`
+
+	queryListVCenterPasswords = `SELECT name, password, key_id FROM vcenters`
+
+	queryUpdateVCenterPassword = `UPDATE vcenters SET password = ?, key_id = ? WHERE name = ?`
+
+	queryDeleteVCenter = `DELETE FROM vcenters WHERE name = ?`
+)
+
+// Inventory uploads queries
+const (
+	queryGetInventoryUpload = `
+		SELECT source_id, COALESCE(delivered_hash, ''), delivered_at, COALESCE(pending_hash, ''), pending_since,
+			attempts, COALESCE(error, '')
+		FROM inventory_uploads WHERE source_id = ?`
+
+	queryUpsertInventoryUploadPending = `
+		INSERT INTO inventory_uploads (source_id, pending_hash, pending_since, attempts, updated_at)
+		VALUES (?, ?, now(), 0, now())
+		ON CONFLICT (source_id) DO UPDATE SET
+			pending_hash = EXCLUDED.pending_hash,
+			pending_since = EXCLUDED.pending_since,
+			attempts = 0,
+			error = NULL,
+			updated_at = now()`
 
-	queryUpsertInventory = `
-		INSERT INTO inventory (id, data, updated_at)
-		VALUES (1, ?, now())
+	queryUpdateInventoryUploadFailure = `
+		UPDATE inventory_uploads SET attempts = attempts + 1, error = ?, updated_at = now()
+		WHERE source_id = ? AND pending_hash IS NOT NULL`
+
+	queryClearInventoryUploadPending = `
+		UPDATE inventory_uploads SET pending_hash = NULL, pending_since = NULL, attempts = 0, error = NULL, updated_at = now()
+		WHERE source_id = ?`
+
+	queryUpsertInventoryUploadDelivered = `
+		INSERT INTO inventory_uploads (source_id, delivered_hash, delivered_at, updated_at)
+		VALUES (?, ?, ?, now())
+		ON CONFLICT (source_id) DO UPDATE SET
+			delivered_hash = EXCLUDED.delivered_hash,
+			delivered_at = EXCLUDED.delivered_at,
+			pending_hash = NULL,
+			pending_since = NULL,
+			attempts = 0,
+			error = NULL,
+			updated_at = now()`
+)
+
+// Agent settings queries
+const (
+	queryGetAgentSettings = `
+		SELECT COALESCE(mode, ''), mode_set_at, COALESCE(mode_set_by, ''),
+			data_sharing_allowed, COALESCE(consent_terms_version, ''), consent_set_at, COALESCE(consent_set_by, ''), updated_at
+		FROM agent_settings WHERE id = 1`
+
+	queryUpsertAgentMode = `
+		INSERT INTO agent_settings (id, mode, mode_set_at, mode_set_by, updated_at)
+		VALUES (1, ?, now(), ?, now())
 		ON CONFLICT (id) DO UPDATE SET
-			data = EXCLUDED.data,
+			mode = EXCLUDED.mode,
+			mode_set_at = EXCLUDED.mode_set_at,
+			mode_set_by = EXCLUDED.mode_set_by,
 			updated_at = now()`
+
+	queryUpsertAgentConsent = `
+		INSERT INTO agent_settings (id, data_sharing_allowed, consent_terms_version, consent_set_at, consent_set_by, updated_at)
+		VALUES (1, ?, ?, now(), ?, now())
+		ON CONFLICT (id) DO UPDATE SET
+			data_sharing_allowed = EXCLUDED.data_sharing_allowed,
+			consent_terms_version = EXCLUDED.consent_terms_version,
+			consent_set_at = EXCLUDED.consent_set_at,
+			consent_set_by = EXCLUDED.consent_set_by,
+			updated_at = now()`
+)
+
+// Anonymization queries
+const (
+	queryGetAnonymizationSalt = `SELECT salt FROM anonymization_salt WHERE id = 1`
+
+	queryInsertAnonymizationSalt = `
+		INSERT INTO anonymization_salt (id, salt) VALUES (1, ?)
+		ON CONFLICT (id) DO NOTHING`
 )
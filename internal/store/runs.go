@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// CollectionRunStore handles the collection history storage using DuckDB.
+// The runs are those of the vCenter of the collector, or of the named vCenter the store is scoped to.
+type CollectionRunStore struct {
+	db      *sql.DB
+	vcenter string
+}
+
+// NewCollectionRunStore creates a new collection run store.
+func NewCollectionRunStore(db *sql.DB) *CollectionRunStore {
+	return &CollectionRunStore{db: db}
+}
+
+// VCenter returns the store of the runs of the named vCenter.
+func (s *CollectionRunStore) VCenter(name string) *CollectionRunStore {
+	return &CollectionRunStore{db: s.db, vcenter: name}
+}
+
+// Create records a started run and returns its id.
+func (s *CollectionRunStore) Create(ctx context.Context, run *models.CollectionRun) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, queryInsertCollectionRun,
+		string(run.Trigger), run.VCenterURL, string(run.State), run.StartedAt, s.vcenter).Scan(&id)
+	return id, err
+}
+
+// Finish records the outcome of the run.
+func (s *CollectionRunStore) Finish(ctx context.Context, run *models.CollectionRun) error {
+	var runError, snapshotID any
+	if run.Error != "" {
+		runError = run.Error
+	}
+	if run.SnapshotID != 0 {
+		snapshotID = run.SnapshotID
+	}
+
+	_, err := s.db.ExecContext(ctx, queryFinishCollectionRun,
+		string(run.State), runError, run.EndedAt, run.VMs, run.Hosts, run.Datastores, snapshotID, run.Incremental, run.ID)
+	return err
+}
+
+// List returns a page of runs, newest first, along with the total number of runs.
+func (s *CollectionRunStore) List(ctx context.Context, limit, offset int) ([]models.CollectionRun, int, error) {
+	var total int
+	if err := s.db.QueryRowContext(ctx, queryCountCollectionRuns, s.vcenter).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, queryListCollectionRuns, s.vcenter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	runs, err := scanCollectionRuns(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+// Running returns the runs which are not finished, oldest first.
+func (s *CollectionRunStore) Running(ctx context.Context) ([]models.CollectionRun, error) {
+	rows, err := s.db.QueryContext(ctx, queryListRunningCollectionRuns, s.vcenter)
+	if err != nil {
+		return nil, err
+	}
+	return scanCollectionRuns(rows)
+}
+
+func scanCollectionRuns(rows *sql.Rows) ([]models.CollectionRun, error) {
+	defer func() { _ = rows.Close() }()
+
+	runs := []models.CollectionRun{}
+	for rows.Next() {
+		var (
+			run        models.CollectionRun
+			runError   sql.NullString
+			endedAt    sql.NullTime
+			snapshotID sql.NullInt64
+		)
+		if err := rows.Scan(&run.ID, &run.Trigger, &run.VCenterURL, &run.State, &runError, &run.StartedAt, &endedAt,
+			&run.VMs, &run.Hosts, &run.Datastores, &snapshotID, &run.Incremental); err != nil {
+			return nil, err
+		}
+		run.Error = runError.String
+		run.EndedAt = endedAt.Time
+		run.SnapshotID = snapshotID.Int64
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
@@ -0,0 +1,175 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CollectionRunStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrations.Run(ctx, db)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	createRun := func(trigger models.CollectionTrigger) *models.CollectionRun {
+		run := &models.CollectionRun{
+			Trigger:    trigger,
+			VCenterURL: "https://vcenter.example.com",
+			State:      models.CollectionRunStateRunning,
+			StartedAt:  time.Now().UTC().Truncate(time.Microsecond),
+		}
+		id, err := s.CollectionRuns().Create(ctx, run)
+		Expect(err).NotTo(HaveOccurred())
+		run.ID = id
+		return run
+	}
+
+	It("should return an empty list when no run was recorded", func() {
+		runs, total, err := s.CollectionRuns().List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runs).To(BeEmpty())
+		Expect(total).To(Equal(0))
+	})
+
+	It("should record a running collection", func() {
+		run := createRun(models.CollectionTriggerManual)
+
+		runs, total, err := s.CollectionRuns().List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(1))
+		Expect(runs).To(HaveLen(1))
+		Expect(runs[0].ID).To(Equal(run.ID))
+		Expect(runs[0].Trigger).To(Equal(models.CollectionTriggerManual))
+		Expect(runs[0].VCenterURL).To(Equal("https://vcenter.example.com"))
+		Expect(runs[0].State).To(Equal(models.CollectionRunStateRunning))
+		Expect(runs[0].StartedAt).To(BeTemporally("==", run.StartedAt))
+		Expect(runs[0].EndedAt.IsZero()).To(BeTrue())
+		Expect(runs[0].Error).To(BeEmpty())
+		Expect(runs[0].SnapshotID).To(BeZero())
+	})
+
+	It("should record the outcome of a collection", func() {
+		run := createRun(models.CollectionTriggerScheduled)
+		run.State = models.CollectionRunStateSucceeded
+		run.EndedAt = run.StartedAt.Add(time.Minute)
+		run.VMs, run.Hosts, run.Datastores = 10, 2, 3
+		run.SnapshotID = 42
+		run.Incremental = true
+		Expect(s.CollectionRuns().Finish(ctx, run)).To(Succeed())
+
+		runs, _, err := s.CollectionRuns().List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runs).To(HaveLen(1))
+		Expect(runs[0].State).To(Equal(models.CollectionRunStateSucceeded))
+		Expect(runs[0].EndedAt).To(BeTemporally("==", run.EndedAt))
+		Expect(runs[0].VMs).To(Equal(10))
+		Expect(runs[0].Hosts).To(Equal(2))
+		Expect(runs[0].Datastores).To(Equal(3))
+		Expect(runs[0].SnapshotID).To(Equal(int64(42)))
+		Expect(runs[0].Incremental).To(BeTrue())
+	})
+
+	It("should record the error of a failed collection", func() {
+		run := createRun(models.CollectionTriggerManual)
+		run.State = models.CollectionRunStateFailed
+		run.EndedAt = time.Now()
+		run.Error = "connection refused"
+		Expect(s.CollectionRuns().Finish(ctx, run)).To(Succeed())
+
+		runs, _, err := s.CollectionRuns().List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runs[0].State).To(Equal(models.CollectionRunStateFailed))
+		Expect(runs[0].Error).To(Equal("connection refused"))
+		Expect(runs[0].SnapshotID).To(BeZero())
+	})
+
+	It("should paginate the runs newest first", func() {
+		var ids []int64
+		for range 5 {
+			ids = append(ids, createRun(models.CollectionTriggerManual).ID)
+		}
+
+		runs, total, err := s.CollectionRuns().List(ctx, 2, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(5))
+		Expect(runs).To(HaveLen(2))
+		Expect(runs[0].ID).To(Equal(ids[4]))
+		Expect(runs[1].ID).To(Equal(ids[3]))
+
+		runs, total, err = s.CollectionRuns().List(ctx, 2, 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(5))
+		Expect(runs).To(HaveLen(1))
+		Expect(runs[0].ID).To(Equal(ids[0]))
+	})
+	It("should list the runs which are not finished", func() {
+		finished := createRun(models.CollectionTriggerManual)
+		finished.State = models.CollectionRunStateSucceeded
+		finished.EndedAt = time.Now()
+		Expect(s.CollectionRuns().Finish(ctx, finished)).To(Succeed())
+		running := createRun(models.CollectionTriggerScheduled)
+
+		runs, err := s.CollectionRuns().Running(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runs).To(HaveLen(1))
+		Expect(runs[0].ID).To(Equal(running.ID))
+		Expect(runs[0].Trigger).To(Equal(models.CollectionTriggerScheduled))
+	})
+
+	It("should keep the runs of each vCenter apart", func() {
+		createRun(models.CollectionTriggerManual)
+		east := &models.CollectionRun{
+			Trigger:    models.CollectionTriggerScheduled,
+			VCenterURL: "https://east.example.com",
+			State:      models.CollectionRunStateRunning,
+			StartedAt:  time.Now(),
+		}
+		id, err := s.CollectionRuns().VCenter("east").Create(ctx, east)
+		Expect(err).NotTo(HaveOccurred())
+
+		runs, total, err := s.CollectionRuns().VCenter("east").List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(1))
+		Expect(runs[0].ID).To(Equal(id))
+
+		_, total, err = s.CollectionRuns().List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(Equal(1))
+
+		running, err := s.CollectionRuns().VCenter("east").Running(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(running).To(HaveLen(1))
+		Expect(running[0].ID).To(Equal(id))
+	})
+})
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+)
+
+// ScheduleStore handles the collection schedule storage using DuckDB.
+type ScheduleStore struct {
+	db *sql.DB
+}
+
+// NewScheduleStore creates a new schedule store.
+func NewScheduleStore(db *sql.DB) *ScheduleStore {
+	return &ScheduleStore{db: db}
+}
+
+// Get retrieves the collection schedule.
+func (s *ScheduleStore) Get(ctx context.Context) (*models.CollectionSchedule, error) {
+	row := s.db.QueryRowContext(ctx, queryGetCollectionSchedule)
+
+	var (
+		schedule         models.CollectionSchedule
+		intervalSeconds  sql.NullInt64
+		cron             sql.NullString
+		lastRunStartedAt sql.NullTime
+		lastRunStatus    sql.NullString
+		lastRunError     sql.NullString
+	)
+	err := row.Scan(&intervalSeconds, &cron, &lastRunStartedAt, &lastRunStatus, &lastRunError, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.Interval = time.Duration(intervalSeconds.Int64) * time.Second
+	schedule.Cron = cron.String
+	if lastRunStartedAt.Valid {
+		schedule.LastRun = &models.ScheduledRun{
+			StartedAt: lastRunStartedAt.Time,
+			Status:    models.ScheduledRunStatus(lastRunStatus.String),
+			Error:     lastRunError.String,
+		}
+	}
+
+	return &schedule, nil
+}
+
+// Save stores or updates the schedule, keeping the outcome of the last run.
+func (s *ScheduleStore) Save(ctx context.Context, schedule *models.CollectionSchedule) error {
+	var intervalSeconds, cron any
+	if schedule.Interval > 0 {
+		intervalSeconds = int64(schedule.Interval / time.Second)
+	}
+	if schedule.Cron != "" {
+		cron = schedule.Cron
+	}
+
+	// the update time is the base of the next interval run, so it is set like the run times
+	_, err := s.db.ExecContext(ctx, queryUpsertCollectionSchedule, intervalSeconds, cron, time.Now())
+	return err
+}
+
+// RecordRun stores the outcome of the last scheduled run. It is a no-op when no schedule is stored.
+func (s *ScheduleStore) RecordRun(ctx context.Context, run models.ScheduledRun) error {
+	var runError any
+	if run.Error != "" {
+		runError = run.Error
+	}
+
+	_, err := s.db.ExecContext(ctx, queryUpdateCollectionScheduleLastRun, run.StartedAt, string(run.Status), runError)
+	return err
+}
+
+// Delete removes the schedule.
+func (s *ScheduleStore) Delete(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, queryDeleteCollectionSchedule)
+	return err
+}
@@ -0,0 +1,95 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScheduleStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrations.Run(ctx, db)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	It("should return ErrNotFound when no schedule is set", func() {
+		_, err := s.Schedule().Get(ctx)
+		Expect(err).To(Equal(store.ErrNotFound))
+	})
+
+	It("should save an interval schedule", func() {
+		Expect(s.Schedule().Save(ctx, &models.CollectionSchedule{Interval: 6 * time.Hour})).To(Succeed())
+
+		schedule, err := s.Schedule().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.Interval).To(Equal(6 * time.Hour))
+		Expect(schedule.Cron).To(BeEmpty())
+		Expect(schedule.LastRun).To(BeNil())
+	})
+
+	It("should replace the schedule and keep the last run", func() {
+		Expect(s.Schedule().Save(ctx, &models.CollectionSchedule{Interval: time.Hour})).To(Succeed())
+		startedAt := time.Now().UTC().Truncate(time.Second)
+		Expect(s.Schedule().RecordRun(ctx, models.ScheduledRun{
+			StartedAt: startedAt,
+			Status:    models.ScheduledRunStatusFailed,
+			Error:     "boom",
+		})).To(Succeed())
+
+		Expect(s.Schedule().Save(ctx, &models.CollectionSchedule{Cron: "0 2 * * *"})).To(Succeed())
+
+		schedule, err := s.Schedule().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.Interval).To(BeZero())
+		Expect(schedule.Cron).To(Equal("0 2 * * *"))
+		Expect(schedule.LastRun).NotTo(BeNil())
+		Expect(schedule.LastRun.StartedAt.Equal(startedAt)).To(BeTrue())
+		Expect(schedule.LastRun.Status).To(Equal(models.ScheduledRunStatusFailed))
+		Expect(schedule.LastRun.Error).To(Equal("boom"))
+	})
+
+	It("should ignore runs recorded without a schedule", func() {
+		Expect(s.Schedule().RecordRun(ctx, models.ScheduledRun{StartedAt: time.Now(), Status: models.ScheduledRunStatusSkipped})).To(Succeed())
+
+		_, err := s.Schedule().Get(ctx)
+		Expect(err).To(Equal(store.ErrNotFound))
+	})
+
+	It("should delete the schedule", func() {
+		Expect(s.Schedule().Save(ctx, &models.CollectionSchedule{Interval: time.Hour})).To(Succeed())
+		Expect(s.Schedule().Delete(ctx)).To(Succeed())
+
+		_, err := s.Schedule().Get(ctx)
+		Expect(err).To(Equal(store.ErrNotFound))
+	})
+})
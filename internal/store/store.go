@@ -1,19 +1,46 @@
 package store
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+)
+
+// querier is implemented by *sql.DB and *sql.Tx, so a query can run inside a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
 
 // Store provides access to all storage repositories.
 type Store struct {
 	db          *sql.DB
 	credentials *CredentialsStore
 	inventory   *InventoryStore
+	schedule    *ScheduleStore
+	runs        *CollectionRunStore
+	collector   *CollectorStateStore
+	vcenters    *VCenterStore
+	uploads     *InventoryUploadStore
+	settings    *AgentSettingsStore
+	anonymize   *AnonymizationStore
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db *sql.DB, keyring *encryption.Keyring) *Store {
 	return &Store{
 		db:          db,
-		credentials: NewCredentialsStore(db),
+		credentials: NewCredentialsStore(db, keyring),
 		inventory:   NewInventoryStore(db),
+		schedule:    NewScheduleStore(db),
+		runs:        NewCollectionRunStore(db),
+		collector:   NewCollectorStateStore(db),
+		vcenters:    NewVCenterStore(db, keyring),
+		uploads:     NewInventoryUploadStore(db),
+		settings:    NewAgentSettingsStore(db),
+		anonymize:   NewAnonymizationStore(db),
 	}
 }
 
@@ -25,6 +52,59 @@ func (s *Store) Inventory() *InventoryStore {
 	return s.inventory
 }
 
+func (s *Store) Schedule() *ScheduleStore {
+	return s.schedule
+}
+
+func (s *Store) CollectionRuns() *CollectionRunStore {
+	return s.runs
+}
+
+func (s *Store) CollectorState() *CollectorStateStore {
+	return s.collector
+}
+
+func (s *Store) VCenters() *VCenterStore {
+	return s.vcenters
+}
+
+func (s *Store) InventoryUploads() *InventoryUploadStore {
+	return s.uploads
+}
+
+func (s *Store) AgentSettings() *AgentSettingsStore {
+	return s.settings
+}
+
+func (s *Store) Anonymization() *AnonymizationStore {
+	return s.anonymize
+}
+
+// ReEncrypt encrypts the passwords of the credentials and of the named vCenters with the primary key
+// of the keyring, in a single transaction so a failure leaves every password with its previous key.
+// It returns whether the credentials were rewritten and the number of vCenter passwords rewritten.
+func (s *Store) ReEncrypt(ctx context.Context) (bool, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	credentials, err := s.credentials.reEncrypt(ctx, tx)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to re-encrypt credentials: %w", err)
+	}
+	vcenters, err := s.vcenters.reEncrypt(ctx, tx)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to re-encrypt vcenter credentials: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, 0, err
+	}
+	return credentials, vcenters, nil
+}
+
 func (s *Store) Close() error {
 	return s.db.Close()
 }
@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+)
+
+// VCenterStore handles the named vCenter endpoints storage using DuckDB.
+// Passwords are encrypted with the keyring before being written.
+type VCenterStore struct {
+	db      *sql.DB
+	keyring *encryption.Keyring
+}
+
+// NewVCenterStore creates a new vCenter store.
+func NewVCenterStore(db *sql.DB, keyring *encryption.Keyring) *VCenterStore {
+	return &VCenterStore{db: db, keyring: keyring}
+}
+
+// List returns all vCenters sorted by name.
+func (s *VCenterStore) List(ctx context.Context) ([]models.VCenter, error) {
+	rows, err := s.db.QueryContext(ctx, queryListVCenters)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var vcenters []models.VCenter
+	for rows.Next() {
+		v, err := s.scanVCenter(rows)
+		if err != nil {
+			return nil, err
+		}
+		vcenters = append(vcenters, *v)
+	}
+
+	return vcenters, rows.Err()
+}
+
+// Get retrieves the vCenter with the given name.
+func (s *VCenterStore) Get(ctx context.Context, name string) (*models.VCenter, error) {
+	v, err := s.scanVCenter(s.db.QueryRowContext(ctx, queryGetVCenter, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return v, err
+}
+
+// Create stores a new vCenter. It returns ErrAlreadyExists when a vCenter with the same name exists.
+func (s *VCenterStore) Create(ctx context.Context, v *models.VCenter) error {
+	keyID, password, err := s.keyring.Encrypt([]byte(v.Credentials.Password))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt password: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, queryInsertVCenter,
+		v.Name, v.Credentials.URL, v.Credentials.Username, password, keyID,
+		v.Credentials.CACert, v.Credentials.Thumbprint, string(v.State))
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, ErrAlreadyExists)
+}
+
+// UpdateCredentials replaces the credentials of the vCenter.
+func (s *VCenterStore) UpdateCredentials(ctx context.Context, name string, creds *models.Credentials) error {
+	keyID, password, err := s.keyring.Encrypt([]byte(creds.Password))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt password: %w", err)
+	}
+
+	res, err := s.db.ExecContext(ctx, queryUpdateVCenterCredentials,
+		creds.URL, creds.Username, password, keyID, creds.CACert, creds.Thumbprint, name)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, ErrNotFound)
+}
+
+// SaveState records the collection state of the vCenter, with the error message when the state is error.
+func (s *VCenterStore) SaveState(ctx context.Context, name string, state models.CollectorState, errorMsg string) error {
+	var stateError any
+	if errorMsg != "" {
+		stateError = errorMsg
+	}
+
+	res, err := s.db.ExecContext(ctx, queryUpdateVCenterState, string(state), stateError, name)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res, ErrNotFound)
+}
+
+// GetState retrieves the collection state of the vCenter and its error message, empty unless the state is error.
+func (s *VCenterStore) GetState(ctx context.Context, name string) (models.CollectorState, string, error) {
+	var (
+		state    models.CollectorState
+		errorMsg string
+	)
+	err := s.db.QueryRowContext(ctx, queryGetVCenterState, name).Scan(&state, &errorMsg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", ErrNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return state, errorMsg, nil
+}
+
+// Delete removes the vCenter along with its inventory snapshots and collection runs.
+func (s *VCenterStore) Delete(ctx context.Context, name string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, queryDeleteVCenter, name)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(res, ErrNotFound); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, queryDeleteInventorySnapshots, name); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, queryDeleteCollectionRuns, name); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReEncrypt encrypts the stored passwords with the primary key of the keyring
+// and returns the number of passwords rewritten.
+func (s *VCenterStore) ReEncrypt(ctx context.Context) (int, error) {
+	return s.reEncrypt(ctx, s.db)
+}
+
+func (s *VCenterStore) reEncrypt(ctx context.Context, q querier) (int, error) {
+	type storedPassword struct {
+		name     string
+		password string
+		keyID    sql.NullString
+	}
+
+	rows, err := q.QueryContext(ctx, queryListVCenterPasswords)
+	if err != nil {
+		return 0, err
+	}
+	var stale []storedPassword
+	for rows.Next() {
+		var p storedPassword
+		if err := rows.Scan(&p.name, &p.password, &p.keyID); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		if !p.keyID.Valid || p.keyID.String != s.keyring.PrimaryKeyID() {
+			stale = append(stale, p)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, err
+	}
+	_ = rows.Close()
+
+	for _, p := range stale {
+		password := []byte(p.password)
+		if p.keyID.Valid {
+			password, err = s.keyring.Decrypt(p.keyID.String, p.password)
+			if err != nil {
+				return 0, fmt.Errorf("failed to decrypt password of vcenter %q: %w", p.name, err)
+			}
+		}
+
+		keyID, encrypted, err := s.keyring.Encrypt(password)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encrypt password: %w", err)
+		}
+		if _, err := q.ExecContext(ctx, queryUpdateVCenterPassword, encrypted, keyID, p.name); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(stale), nil
+}
+
+func (s *VCenterStore) scanVCenter(row interface{ Scan(...any) error }) (*models.VCenter, error) {
+	var v models.VCenter
+	var keyID sql.NullString
+	var state string
+	var collectedAt sql.NullTime
+	err := row.Scan(&v.Name, &v.Credentials.URL, &v.Credentials.Username, &v.Credentials.Password, &keyID,
+		&v.Credentials.CACert, &v.Credentials.Thumbprint, &state, &v.Error, &collectedAt, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyID.Valid {
+		password, err := s.keyring.Decrypt(keyID.String, v.Credentials.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt password of vcenter %q: %w", v.Name, err)
+		}
+		v.Credentials.Password = string(password)
+	}
+	v.State = models.CollectorState(state)
+	if collectedAt.Valid {
+		v.CollectedAt = collectedAt.Time
+	}
+	v.Credentials.CreatedAt = v.CreatedAt
+	v.Credentials.UpdatedAt = v.UpdatedAt
+
+	return &v, nil
+}
+
+// expectAffected returns err when the statement did not change any row.
+func expectAffected(res sql.Result, err error) error {
+	n, affectedErr := res.RowsAffected()
+	if affectedErr != nil {
+		return affectedErr
+	}
+	if n == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kubev2v/assisted-migration-agent/internal/models"
+	"github.com/kubev2v/assisted-migration-agent/internal/store"
+	"github.com/kubev2v/assisted-migration-agent/internal/store/migrations"
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VCenterStore", func() {
+	var (
+		ctx context.Context
+		s   *store.Store
+		db  *sql.DB
+		key *encryption.Key
+	)
+
+	newVCenter := func(name string) *models.VCenter {
+		return &models.VCenter{
+			Name: name,
+			Credentials: models.Credentials{
+				URL:        "https://" + name + ".example.com",
+				Username:   "admin",
+				Password:   "secret123",
+				Thumbprint: "AA:BB",
+			},
+			State: models.CollectorStateReady,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		db, err = store.NewDB(":memory:")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrations.Run(ctx, db)
+		Expect(err).NotTo(HaveOccurred())
+
+		key, err = encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		s = store.NewStore(db, encryption.NewKeyring(key))
+	})
+
+	AfterEach(func() {
+		if db != nil {
+			db.Close()
+		}
+	})
+
+	It("should create and get a vCenter", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+
+		v, err := s.VCenters().Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.Name).To(Equal("east"))
+		Expect(v.Credentials.URL).To(Equal("https://east.example.com"))
+		Expect(v.Credentials.Password).To(Equal("secret123"))
+		Expect(v.Credentials.Thumbprint).To(Equal("AA:BB"))
+		Expect(v.State).To(Equal(models.CollectorStateReady))
+		Expect(v.CollectedAt.IsZero()).To(BeTrue())
+	})
+
+	It("should store the password encrypted", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+
+		var stored string
+		Expect(db.QueryRow("SELECT password FROM vcenters WHERE name = 'east'").Scan(&stored)).To(Succeed())
+		Expect(stored).NotTo(Equal("secret123"))
+	})
+
+	It("should reject a duplicate name", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(MatchError(store.ErrAlreadyExists))
+	})
+
+	It("should return ErrNotFound for an unknown vCenter", func() {
+		_, err := s.VCenters().Get(ctx, "unknown")
+		Expect(err).To(MatchError(store.ErrNotFound))
+		Expect(s.VCenters().Delete(ctx, "unknown")).To(MatchError(store.ErrNotFound))
+		Expect(s.VCenters().SaveState(ctx, "unknown", models.CollectorStateReady, "")).To(MatchError(store.ErrNotFound))
+	})
+
+	It("should list the vCenters sorted by name", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("west"))).To(Succeed())
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+
+		vcenters, err := s.VCenters().List(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vcenters).To(HaveLen(2))
+		Expect(vcenters[0].Name).To(Equal("east"))
+		Expect(vcenters[1].Name).To(Equal("west"))
+	})
+
+	It("should update the credentials", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+
+		Expect(s.VCenters().UpdateCredentials(ctx, "east", &models.Credentials{
+			URL:      "https://other.example.com",
+			Username: "root",
+			Password: "changed",
+		})).To(Succeed())
+
+		v, err := s.VCenters().Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.Credentials.URL).To(Equal("https://other.example.com"))
+		Expect(v.Credentials.Username).To(Equal("root"))
+		Expect(v.Credentials.Password).To(Equal("changed"))
+		Expect(v.Credentials.Thumbprint).To(BeEmpty())
+	})
+
+	It("should save the state with its error", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+		Expect(s.VCenters().SaveState(ctx, "east", models.CollectorStateError, "connection refused")).To(Succeed())
+
+		v, err := s.VCenters().Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.State).To(Equal(models.CollectorStateError))
+		Expect(v.Error).To(Equal("connection refused"))
+
+		state, errorMsg, err := s.VCenters().GetState(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(models.CollectorStateError))
+		Expect(errorMsg).To(Equal("connection refused"))
+	})
+
+	It("should report the completion of the latest snapshot as the collection time", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+
+		completedAt := time.Now().UTC().Truncate(time.Second)
+		_, err := s.Inventory().VCenter("east").Save(ctx, &models.Inventory{
+			StartedAt:   completedAt.Add(-time.Minute),
+			CompletedAt: completedAt,
+			Data:        []byte(`{"vcenter_id":"1"}`),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = s.Inventory().Save(ctx, &models.Inventory{
+			StartedAt:   completedAt,
+			CompletedAt: completedAt.Add(time.Hour),
+			Data:        []byte(`{"vcenter_id":"2"}`),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		v, err := s.VCenters().Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.CollectedAt.Equal(completedAt)).To(BeTrue())
+	})
+
+	It("should delete the vCenter along with its snapshots and runs", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+		_, err := s.Inventory().VCenter("east").Save(ctx, &models.Inventory{StartedAt: time.Now(), CompletedAt: time.Now(), Data: []byte("{}")})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = s.CollectionRuns().VCenter("east").Create(ctx, &models.CollectionRun{
+			Trigger: models.CollectionTriggerManual, State: models.CollectionRunStateRunning, StartedAt: time.Now(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(s.VCenters().Delete(ctx, "east")).To(Succeed())
+
+		_, err = s.VCenters().Get(ctx, "east")
+		Expect(err).To(MatchError(store.ErrNotFound))
+		_, err = s.Inventory().VCenter("east").Latest(ctx)
+		Expect(err).To(MatchError(store.ErrNotFound))
+		_, total, err := s.CollectionRuns().VCenter("east").List(ctx, 20, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(total).To(BeZero())
+	})
+
+	It("should re-encrypt the passwords with a new key", func() {
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+		Expect(s.VCenters().Create(ctx, newVCenter("west"))).To(Succeed())
+
+		newKey, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		rotated := store.NewStore(db, encryption.NewKeyring(newKey, key))
+
+		updated, err := rotated.VCenters().ReEncrypt(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(Equal(2))
+
+		updated, err = rotated.VCenters().ReEncrypt(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeZero())
+
+		v, err := store.NewStore(db, encryption.NewKeyring(newKey)).VCenters().Get(ctx, "west")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.Credentials.Password).To(Equal("secret123"))
+	})
+
+	It("should re-encrypt the credentials and the vCenters in one transaction", func() {
+		Expect(s.Credentials().Save(ctx, &models.Credentials{URL: "https://primary.example.com", Username: "admin", Password: "primary"})).To(Succeed())
+		Expect(s.VCenters().Create(ctx, newVCenter("east"))).To(Succeed())
+
+		other, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.NewStore(db, encryption.NewKeyring(other)).VCenters().Create(ctx, newVCenter("west"))).To(Succeed())
+
+		newKey, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		// the password of west cannot be decrypted, nothing is rewritten
+		_, _, err = store.NewStore(db, encryption.NewKeyring(newKey, key)).ReEncrypt(ctx)
+		Expect(err).To(MatchError(encryption.ErrUnknownKey))
+
+		creds, err := s.Credentials().Get(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.Password).To(Equal("primary"))
+
+		credentials, vcenters, err := store.NewStore(db, encryption.NewKeyring(newKey, key, other)).ReEncrypt(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentials).To(BeTrue())
+		Expect(vcenters).To(Equal(2))
+
+		v, err := store.NewStore(db, encryption.NewKeyring(newKey)).VCenters().Get(ctx, "east")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.Credentials.Password).To(Equal("secret123"))
+	})
+})
@@ -33,9 +33,16 @@ func main() {
 			ID: uuid.NewString(),
 		}),
 		config.WithAgent(config.Agent{
-			NumWorkers:     3,
-			Mode:           "disconnected",
-			UpdateInterval: 5 * time.Second,
+			NumWorkers:          3,
+			Mode:                "disconnected",
+			UpdateInterval:      5 * time.Second,
+			BackoffInitial:      5 * time.Second,
+			BackoffMax:          5 * time.Minute,
+			BackoffMultiplier:   2,
+			BackoffJitter:       0.2,
+			InventoryMaxRetries: 10,
+			SnapshotRetention:   10,
+			CollectorTimeout:    5 * time.Minute,
 		}),
 		config.WithAuth(config.Authentication{Enabled: false}),
 		config.WithLogFormat("console"),
@@ -55,6 +62,7 @@ func main() {
 	defer undo()
 
 	rootCmd.AddCommand(cmd.NewRunCommand(cfg))
+	rootCmd.AddCommand(cmd.NewRotateKeyCommand(cfg))
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Printf("%s", err)
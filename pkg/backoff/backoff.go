@@ -0,0 +1,76 @@
+package backoff
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy configures the delay before the next attempt of an operation after consecutive failures.
+// The delay starts at Initial and is multiplied by Multiplier after each failure, up to Max.
+// A zero policy does not delay the attempts.
+type Policy struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	// Jitter is the fraction of the delay randomly added or removed, between 0 and 1,
+	// so that agents failing at the same time do not retry at the same time.
+	Jitter float64
+}
+
+// Backoff tracks the consecutive failures of an operation and when it can be attempted again.
+// It is not safe for concurrent use.
+type Backoff struct {
+	policy   Policy
+	failures int
+	delay    time.Duration
+	next     time.Time
+}
+
+func New(policy Policy) *Backoff {
+	return &Backoff{policy: policy}
+}
+
+// Failure records a failed attempt and returns the delay before the next one.
+func (b *Backoff) Failure() time.Duration {
+	b.failures++
+
+	delay := float64(b.policy.Initial) * math.Pow(max(b.policy.Multiplier, 1), float64(b.failures-1))
+	if b.policy.Jitter > 0 {
+		delay += delay * b.policy.Jitter * (2*rand.Float64() - 1)
+	}
+	if b.policy.Max > 0 {
+		delay = min(delay, float64(b.policy.Max))
+	}
+
+	b.delay = time.Duration(delay)
+	b.next = time.Now().Add(b.delay)
+	return b.delay
+}
+
+// Success records a successful attempt, which resets the backoff.
+func (b *Backoff) Success() {
+	b.failures = 0
+	b.delay = 0
+	b.next = time.Time{}
+}
+
+// Ready reports whether the delay following the last failure has elapsed.
+func (b *Backoff) Ready() bool {
+	return !time.Now().Before(b.next)
+}
+
+// Failures returns the number of consecutive failures.
+func (b *Backoff) Failures() int {
+	return b.failures
+}
+
+// Delay returns the current delay, zero when the last attempt succeeded.
+func (b *Backoff) Delay() time.Duration {
+	return b.delay
+}
+
+// NextAttempt returns the time from which the operation can be attempted again, zero when the last attempt succeeded.
+func (b *Backoff) NextAttempt() time.Time {
+	return b.next
+}
@@ -0,0 +1,60 @@
+package backoff_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/backoff"
+)
+
+var _ = Describe("Backoff", func() {
+	It("should multiply the delay after each failure up to the max", func() {
+		b := backoff.New(backoff.Policy{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2})
+
+		Expect(b.Failure()).To(Equal(time.Second))
+		Expect(b.Failure()).To(Equal(2 * time.Second))
+		Expect(b.Failure()).To(Equal(4 * time.Second))
+		Expect(b.Failure()).To(Equal(5 * time.Second))
+		Expect(b.Failures()).To(Equal(4))
+		Expect(b.Delay()).To(Equal(5 * time.Second))
+	})
+
+	It("should not be ready before the delay elapsed", func() {
+		b := backoff.New(backoff.Policy{Initial: time.Hour, Multiplier: 2})
+		Expect(b.Ready()).To(BeTrue())
+
+		b.Failure()
+		Expect(b.Ready()).To(BeFalse())
+		Expect(b.NextAttempt()).To(BeTemporally("~", time.Now().Add(time.Hour), time.Second))
+	})
+
+	It("should reset on success", func() {
+		b := backoff.New(backoff.Policy{Initial: time.Hour, Multiplier: 2})
+		b.Failure()
+		b.Failure()
+
+		b.Success()
+		Expect(b.Ready()).To(BeTrue())
+		Expect(b.Failures()).To(BeZero())
+		Expect(b.Delay()).To(BeZero())
+		Expect(b.NextAttempt().IsZero()).To(BeTrue())
+		Expect(b.Failure()).To(Equal(time.Hour))
+	})
+
+	It("should keep the jittered delay within the jitter fraction", func() {
+		for range 100 {
+			b := backoff.New(backoff.Policy{Initial: 10 * time.Second, Multiplier: 2, Jitter: 0.2})
+			Expect(b.Failure()).To(BeNumerically("~", 10*time.Second, 2*time.Second))
+		}
+	})
+
+	It("should not delay the attempts with a zero policy", func() {
+		b := backoff.New(backoff.Policy{})
+
+		Expect(b.Failure()).To(BeZero())
+		Expect(b.Ready()).To(BeTrue())
+		Expect(b.Failures()).To(Equal(1))
+	})
+})
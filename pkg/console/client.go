@@ -1,44 +1,109 @@
 package console
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/google/uuid"
 	apiAgent "github.com/kubev2v/migration-planner/api/v1alpha1/agent"
 	agentClient "github.com/kubev2v/migration-planner/pkg/client"
+	"go.uber.org/zap"
 
 	"github.com/kubev2v/assisted-migration-agent/internal/models"
 	serviceErrs "github.com/kubev2v/assisted-migration-agent/pkg/errors"
 )
 
+// errUnsupportedMediaType is returned when console rejects the compressed inventory.
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
 type Client struct {
 	baseURL    string
 	httpClient *agentClient.Client
+	jwt        string
+	// jwtFile replaces jwt when set, reloaded when console rejects the JWT
+	jwtFile *JWTFile
+	// compress is cleared when console does not accept compressed inventories
+	compress atomic.Bool
 }
 
-func NewConsoleClient(baseURL string, jwt string) (*Client, error) {
-	httpClient, err := agentClient.NewClient(baseURL, agentClient.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
-		if jwt == "" {
-			return nil
-		}
-		req.Header.Add("Authorization", fmt.Sprintf("Bearer: %s", jwt))
-		return nil
-	}))
+// ClientOption configures the console client.
+type ClientOption func(*Client)
+
+// WithCompression sends the inventory compressed with gzip.
+// The client falls back to uncompressed inventories if console responds 415 Unsupported Media Type.
+func WithCompression(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.compress.Store(enabled)
+	}
+}
+
+// WithJWTFile authenticates the agent with the JWT of the file instead of a static JWT.
+// When console rejects the JWT, the file is read again and the request is retried once with the new JWT.
+func WithJWTFile(f *JWTFile) ClientOption {
+	return func(c *Client) {
+		c.jwtFile = f
+	}
+}
+
+func NewConsoleClient(baseURL string, jwt string, opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		baseURL: baseURL,
+		jwt:     jwt,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	httpClient, err := agentClient.NewClient(baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize console client: %v", err)
 	}
-	return &Client{
-		baseURL:    baseURL,
-		httpClient: httpClient,
-	}, nil
+	c.httpClient = httpClient
+
+	return c, nil
 }
 
-// UpdateAgentStatus sends agent status to console.redhat.com
-// PUT /api/v1/agents/{id}/status
-func (c *Client) UpdateAgentStatus(ctx context.Context, agentID uuid.UUID, sourceID uuid.UUID, version string, collectorStatus models.CollectorStatusType) error {
+func (c *Client) authorize(ctx context.Context, req *http.Request) error {
+	jwt := c.jwt
+	if c.jwtFile != nil {
+		jwt = c.jwtFile.Token()
+	}
+	if jwt == "" {
+		return nil
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer: %s", jwt))
+	return nil
+}
+
+// send sends the request, and sends it again if console rejects the JWT and the reloaded JWT is a new one.
+func (c *Client) send(send func() (*http.Response, error)) (*http.Response, error) {
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || c.jwtFile == nil {
+		return resp, err
+	}
+
+	changed, err := c.jwtFile.Reload()
+	if err != nil {
+		zap.S().Warnw("failed to reload agent's jwt", "error", err)
+		return resp, nil
+	}
+	if !changed {
+		return resp, nil
+	}
+
+	zap.S().Info("console rejected the agent's jwt, retrying with the reloaded jwt")
+	_ = resp.Body.Close()
+	return send()
+}
+
+// NewAgentStatusRequest builds the request UpdateAgentStatus sends, without sending it.
+func (c *Client) NewAgentStatusRequest(ctx context.Context, agentID uuid.UUID, sourceID uuid.UUID, version string, collectorStatus models.CollectorStatusType) (*http.Request, error) {
 	body := apiAgent.AgentStatusUpdate{
 		Status:     string(collectorStatus),
 		StatusInfo: string(collectorStatus),
@@ -46,7 +111,27 @@ func (c *Client) UpdateAgentStatus(ctx context.Context, agentID uuid.UUID, sourc
 		Version:    version,
 	}
 
-	resp, err := c.httpClient.UpdateAgentStatus(ctx, agentID, body)
+	req, err := agentClient.NewUpdateAgentStatusRequest(c.baseURL, agentID, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// UpdateAgentStatus sends agent status to console.redhat.com
+// PUT /api/v1/agents/{id}/status
+func (c *Client) UpdateAgentStatus(ctx context.Context, agentID uuid.UUID, sourceID uuid.UUID, version string, collectorStatus models.CollectorStatusType) error {
+	resp, err := c.send(func() (*http.Response, error) {
+		req, err := c.NewAgentStatusRequest(ctx, agentID, sourceID, version, collectorStatus)
+		if err != nil {
+			return nil, err
+		}
+		return c.httpClient.Client.Do(req)
+	})
 	if err != nil {
 		return err
 	}
@@ -71,7 +156,66 @@ func (c *Client) UpdateAgentStatus(ctx context.Context, agentID uuid.UUID, sourc
 // UpdateSourceStatus sends source inventory to console.redhat.com
 // PUT /api/v1/sources/{id}/status
 func (c *Client) UpdateSourceStatus(ctx context.Context, sourceID uuid.UUID, inventory io.Reader) error {
-	resp, err := c.httpClient.UpdateSourceInventoryWithBody(ctx, sourceID, "application/json", inventory)
+	data, err := io.ReadAll(inventory)
+	if err != nil {
+		return fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	if c.compress.Load() {
+		err := c.updateSourceStatus(ctx, sourceID, data, true)
+		if !errors.Is(err, errUnsupportedMediaType) {
+			return err
+		}
+		zap.S().Warnw("console does not accept compressed inventory, sending it uncompressed from now on")
+		c.compress.Store(false)
+	}
+
+	return c.updateSourceStatus(ctx, sourceID, data, false)
+}
+
+// NewSourceStatusRequest builds the request UpdateSourceStatus sends next, without sending it.
+// The inventory is compressed unless console rejected compressed inventories.
+func (c *Client) NewSourceStatusRequest(ctx context.Context, sourceID uuid.UUID, data []byte) (*http.Request, error) {
+	return c.newSourceStatusRequest(ctx, sourceID, data, c.compress.Load())
+}
+
+func (c *Client) newSourceStatusRequest(ctx context.Context, sourceID uuid.UUID, data []byte, compress bool) (*http.Request, error) {
+	body := data
+	if compress {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to compress inventory: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress inventory: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := agentClient.NewUpdateSourceInventoryRequestWithBody(c.baseURL, sourceID, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if err := c.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (c *Client) updateSourceStatus(ctx context.Context, sourceID uuid.UUID, data []byte, compress bool) error {
+	resp, err := c.send(func() (*http.Response, error) {
+		req, err := c.newSourceStatusRequest(ctx, sourceID, data, compress)
+		if err != nil {
+			return nil, err
+		}
+		zap.S().Infow("sending inventory to console", "size", len(data), "request_size", req.ContentLength, "compressed", compress)
+		return c.httpClient.Client.Do(req)
+	})
 	if err != nil {
 		return err
 	}
@@ -79,6 +223,10 @@ func (c *Client) UpdateSourceStatus(ctx context.Context, sourceID uuid.UUID, inv
 		defer resp.Body.Close()
 	}
 
+	if compress && resp.StatusCode == http.StatusUnsupportedMediaType {
+		return errUnsupportedMediaType
+	}
+
 	switch resp.StatusCode {
 	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
 		return nil
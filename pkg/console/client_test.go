@@ -0,0 +1,183 @@
+package console_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+	"github.com/kubev2v/assisted-migration-agent/pkg/errors"
+)
+
+// inventoryRequest is an inventory received by the test server.
+type inventoryRequest struct {
+	encoding string
+	body     string
+}
+
+var _ = Describe("Client", func() {
+	const inventory = `{"vms": [{"name": "vm1"}]}`
+
+	var (
+		requests []inventoryRequest
+		// rejectCompressed makes the server respond 415 to compressed inventories
+		rejectCompressed bool
+		rejected         int
+		server           *httptest.Server
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		rejectCompressed = false
+		rejected = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := r.Header.Get("Content-Encoding")
+			if encoding == "gzip" && rejectCompressed {
+				rejected++
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+
+			var body io.Reader = r.Body
+			if encoding == "gzip" {
+				gz, err := gzip.NewReader(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				body = gz
+			}
+			data, err := io.ReadAll(body)
+			Expect(err).NotTo(HaveOccurred())
+
+			requests = append(requests, inventoryRequest{encoding: encoding, body: string(data)})
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should send the inventory uncompressed by default", func() {
+		client, err := console.NewConsoleClient(server.URL, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(client.UpdateSourceStatus(context.Background(), uuid.New(), strings.NewReader(inventory))).To(Succeed())
+
+		Expect(requests).To(Equal([]inventoryRequest{{body: inventory}}))
+	})
+
+	It("should send the inventory compressed with gzip", func() {
+		client, err := console.NewConsoleClient(server.URL, "", console.WithCompression(true))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(client.UpdateSourceStatus(context.Background(), uuid.New(), strings.NewReader(inventory))).To(Succeed())
+
+		Expect(requests).To(Equal([]inventoryRequest{{encoding: "gzip", body: inventory}}))
+	})
+
+	It("should fall back to uncompressed inventories when console does not support them", func() {
+		rejectCompressed = true
+		client, err := console.NewConsoleClient(server.URL, "", console.WithCompression(true))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(client.UpdateSourceStatus(context.Background(), uuid.New(), strings.NewReader(inventory))).To(Succeed())
+		Expect(client.UpdateSourceStatus(context.Background(), uuid.New(), strings.NewReader(inventory))).To(Succeed())
+
+		Expect(requests).To(Equal([]inventoryRequest{{body: inventory}, {body: inventory}}))
+		Expect(rejected).To(Equal(1))
+	})
+
+	It("should build the requests without sending them", func() {
+		client, err := console.NewConsoleClient(server.URL, "token", console.WithCompression(true))
+		Expect(err).NotTo(HaveOccurred())
+		sourceID := uuid.New()
+
+		req, err := client.NewSourceStatusRequest(context.Background(), sourceID, []byte(inventory))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(req.Method).To(Equal(http.MethodPut))
+		Expect(req.URL.String()).To(Equal(server.URL + "/api/v1/sources/" + sourceID.String() + "/status"))
+		Expect(req.Header.Get("Content-Encoding")).To(Equal("gzip"))
+		Expect(req.Header.Get("Authorization")).To(Equal("Bearer: token"))
+
+		gz, err := gzip.NewReader(req.Body)
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(gz)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(inventory))
+
+		req, err = client.NewAgentStatusRequest(context.Background(), uuid.New(), sourceID, "v1.0.0", "collected")
+		Expect(err).NotTo(HaveOccurred())
+		body, err = io.ReadAll(req.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring(`"version":"v1.0.0"`))
+
+		Expect(requests).To(BeEmpty())
+	})
+
+	Describe("authentication", func() {
+		var (
+			jwtPath string
+			// authorized is the Authorization header accepted by the server
+			authorized string
+			received   []string
+		)
+
+		BeforeEach(func() {
+			received = nil
+			authorized = "Bearer: token-2"
+			jwtPath = filepath.Join(GinkgoT().TempDir(), "jwt")
+			Expect(os.WriteFile(jwtPath, []byte("token-1"), 0o600)).To(Succeed())
+
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = append(received, r.Header.Get("Authorization"))
+				if r.Header.Get("Authorization") != authorized {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+		})
+
+		newClient := func() *console.Client {
+			jwtFile, err := console.NewJWTFile(jwtPath)
+			Expect(err).NotTo(HaveOccurred())
+			client, err := console.NewConsoleClient(server.URL, "", console.WithJWTFile(jwtFile))
+			Expect(err).NotTo(HaveOccurred())
+			return client
+		}
+
+		It("should retry with the reloaded jwt when console rejects the jwt", func() {
+			client := newClient()
+			Expect(os.WriteFile(jwtPath, []byte("token-2"), 0o600)).To(Succeed())
+
+			Expect(client.UpdateAgentStatus(context.Background(), uuid.New(), uuid.New(), "v1", "ready")).To(Succeed())
+			Expect(received).To(Equal([]string{"Bearer: token-1", "Bearer: token-2"}))
+
+			By("sending the next requests with the reloaded jwt")
+			Expect(client.UpdateSourceStatus(context.Background(), uuid.New(), strings.NewReader(inventory))).To(Succeed())
+			Expect(received).To(HaveLen(3))
+		})
+
+		It("should give up when the reloaded jwt is rejected too", func() {
+			client := newClient()
+			Expect(os.WriteFile(jwtPath, []byte("token-3"), 0o600)).To(Succeed())
+
+			err := client.UpdateSourceStatus(context.Background(), uuid.New(), strings.NewReader(inventory))
+			Expect(errors.IsAgentUnauthorizedError(err)).To(BeTrue())
+			Expect(received).To(Equal([]string{"Bearer: token-1", "Bearer: token-3"}))
+		})
+
+		It("should not retry when the jwt did not change", func() {
+			client := newClient()
+
+			err := client.UpdateAgentStatus(context.Background(), uuid.New(), uuid.New(), "v1", "ready")
+			Expect(errors.IsAgentUnauthorizedError(err)).To(BeTrue())
+			Expect(received).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package console_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConsole(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Console Suite")
+}
@@ -0,0 +1,102 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// JWTFile holds the agent JWT read from a file.
+// The token is reloaded when the file changes or when console rejects it,
+// so the JWT can be rotated without restarting the agent.
+type JWTFile struct {
+	path string
+
+	mu    sync.RWMutex
+	token string
+}
+
+// NewJWTFile reads the JWT from the file.
+func NewJWTFile(path string) (*JWTFile, error) {
+	f := &JWTFile{path: path}
+	if _, err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Token returns the last JWT read from the file.
+func (f *JWTFile) Token() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.token
+}
+
+// Reload reads the file again and reports whether the JWT changed.
+// The previous JWT is kept when the file cannot be read or is empty.
+func (f *JWTFile) Reload() (bool, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read agent's jwt: %w", err)
+	}
+	token := strings.TrimSpace(string(data)) // we assume the jwt is valid at this point
+	if token == "" {
+		return false, errors.New("failed to read agent's jwt. the JWT is empty")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	changed := token != f.token
+	f.token = token
+	return changed, nil
+}
+
+// Watch reloads the JWT whenever the folder of the file changes, until the context is done.
+// The folder is watched rather than the file since the file may be replaced, e.g. when a
+// Kubernetes secret is updated.
+func (f *JWTFile) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create jwt watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(f.path)); err != nil {
+		return fmt.Errorf("failed to watch agent's jwt: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			changed, err := f.Reload()
+			if err != nil {
+				// the file may be removed while it is replaced
+				zap.S().Debugw("failed to reload agent's jwt", "event", event, "error", err)
+				continue
+			}
+			if changed {
+				zap.S().Info("agent's jwt reloaded")
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			zap.S().Warnw("agent's jwt watcher failed", "error", err)
+		}
+	}
+}
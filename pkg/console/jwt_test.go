@@ -0,0 +1,83 @@
+package console_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/console"
+)
+
+var _ = Describe("JWTFile", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "jwt")
+		Expect(os.WriteFile(path, []byte("token-1\n"), 0o600)).To(Succeed())
+	})
+
+	It("should read the jwt of the file", func() {
+		jwtFile, err := console.NewJWTFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jwtFile.Token()).To(Equal("token-1"))
+	})
+
+	It("should fail when the file is empty", func() {
+		Expect(os.WriteFile(path, []byte("\n"), 0o600)).To(Succeed())
+
+		_, err := console.NewJWTFile(path)
+		Expect(err).To(MatchError(ContainSubstring("the JWT is empty")))
+	})
+
+	It("should report whether the reloaded jwt changed", func() {
+		jwtFile, err := console.NewJWTFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		changed, err := jwtFile.Reload()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+
+		Expect(os.WriteFile(path, []byte("token-2"), 0o600)).To(Succeed())
+		changed, err = jwtFile.Reload()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(jwtFile.Token()).To(Equal("token-2"))
+	})
+
+	It("should keep the jwt when the file is removed", func() {
+		jwtFile, err := console.NewJWTFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Remove(path)).To(Succeed())
+		_, err = jwtFile.Reload()
+		Expect(err).To(HaveOccurred())
+		Expect(jwtFile.Token()).To(Equal("token-1"))
+	})
+
+	It("should reload the jwt when the file changes", func() {
+		jwtFile, err := console.NewJWTFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- jwtFile.Watch(ctx)
+		}()
+		DeferCleanup(func() {
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		})
+
+		// replace the file the way secrets are usually rotated
+		tmp := path + ".tmp"
+		Eventually(func() string {
+			Expect(os.WriteFile(tmp, []byte("token-2"), 0o600)).To(Succeed())
+			Expect(os.Rename(tmp, path)).To(Succeed())
+			return jwtFile.Token()
+		}, 2*time.Second, 100*time.Millisecond).Should(Equal("token-2"))
+	})
+})
@@ -0,0 +1,227 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+const (
+	// KeySize is the size in bytes of the AES-256 keys.
+	KeySize = 32
+
+	saltSize         = 16
+	pbkdf2Iterations = 600000
+)
+
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Key is a key encryption key identified by the hash of its material.
+type Key struct {
+	ID       string
+	material []byte
+}
+
+func NewKey(material []byte) (*Key, error) {
+	if len(material) != KeySize {
+		return nil, fmt.Errorf("invalid key size %d: must be %d bytes", len(material), KeySize)
+	}
+	sum := sha256.Sum256(material)
+	return &Key{
+		ID:       hex.EncodeToString(sum[:8]),
+		material: material,
+	}, nil
+}
+
+// GenerateKey returns a new random key.
+func GenerateKey() (*Key, error) {
+	material := make([]byte, KeySize)
+	if _, err := rand.Read(material); err != nil {
+		return nil, err
+	}
+	return NewKey(material)
+}
+
+// DeriveKey derives a key from a passphrase using PBKDF2-SHA256.
+func DeriveKey(passphrase string, salt []byte) (*Key, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+	material, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(material)
+}
+
+// LoadKeyFile reads a base64 encoded key from path.
+func LoadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	material, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key file: %w", err)
+	}
+	return NewKey(material)
+}
+
+// LoadOrCreateKeyFile reads the key from path, generating and writing a new one if the file does not exist.
+func LoadOrCreateKeyFile(path string) (*Key, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return LoadKeyFile(path)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key.material)), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write key file: %w", err)
+	}
+	return key, nil
+}
+
+// LoadOrCreateSalt reads the passphrase salt from path, generating and writing a new one if the file does not exist.
+func LoadOrCreateSalt(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return data, nil
+	}
+	// a salt which cannot be read must not be replaced, the secrets encrypted with it would be lost
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read salt file: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, salt, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write salt file: %w", err)
+	}
+	return salt, nil
+}
+
+// Keyring encrypts with its primary key and decrypts with any of its keys.
+//
+// Values are envelope encrypted: each value is sealed with a random data key,
+// which is itself sealed with the primary key.
+type Keyring struct {
+	primary *Key
+	keys    map[string]*Key
+}
+
+func NewKeyring(primary *Key, previous ...*Key) *Keyring {
+	k := &Keyring{
+		primary: primary,
+		keys:    map[string]*Key{primary.ID: primary},
+	}
+	for _, key := range previous {
+		k.keys[key.ID] = key
+	}
+	return k
+}
+
+// PrimaryKeyID returns the id of the key used for encryption.
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary.ID
+}
+
+// Encrypt seals plaintext and returns the id of the key used along with the encoded envelope.
+func (k *Keyring) Encrypt(plaintext []byte) (keyID string, ciphertext string, err error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", "", err
+	}
+
+	sealedKey, err := seal(k.primary.material, dataKey)
+	if err != nil {
+		return "", "", err
+	}
+	sealedData, err := seal(dataKey, plaintext)
+	if err != nil {
+		return "", "", err
+	}
+
+	envelope := base64.StdEncoding.EncodeToString(sealedKey) + "." + base64.StdEncoding.EncodeToString(sealedData)
+	return k.primary.ID, envelope, nil
+}
+
+// Decrypt opens an envelope produced by Encrypt with the key identified by keyID.
+func (k *Keyring) Decrypt(keyID string, ciphertext string) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	encodedKey, encodedData, ok := strings.Cut(ciphertext, ".")
+	if !ok {
+		return nil, errors.New("invalid envelope")
+	}
+	sealedKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid envelope: %w", err)
+	}
+	sealedData, err := base64.StdEncoding.DecodeString(encodedData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid envelope: %w", err)
+	}
+
+	dataKey, err := open(key.material, sealedKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, sealedData)
+}
+
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, data := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
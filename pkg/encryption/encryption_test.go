@@ -0,0 +1,133 @@
+package encryption_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubev2v/assisted-migration-agent/pkg/encryption"
+)
+
+var _ = Describe("Keyring", func() {
+	var key *encryption.Key
+
+	BeforeEach(func() {
+		var err error
+		key, err = encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should decrypt what it encrypts", func() {
+		keyring := encryption.NewKeyring(key)
+
+		keyID, ciphertext, err := keyring.Encrypt([]byte("secret"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keyID).To(Equal(key.ID))
+		Expect(ciphertext).NotTo(ContainSubstring("secret"))
+
+		plaintext, err := keyring.Decrypt(keyID, ciphertext)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plaintext)).To(Equal("secret"))
+	})
+
+	It("should decrypt with a previous key and encrypt with the primary key", func() {
+		keyID, ciphertext, err := encryption.NewKeyring(key).Encrypt([]byte("secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		newKey, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+		keyring := encryption.NewKeyring(newKey, key)
+
+		plaintext, err := keyring.Decrypt(keyID, ciphertext)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plaintext)).To(Equal("secret"))
+
+		Expect(keyring.PrimaryKeyID()).To(Equal(newKey.ID))
+	})
+
+	It("should fail with an unknown key", func() {
+		keyID, ciphertext, err := encryption.NewKeyring(key).Encrypt([]byte("secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		other, err := encryption.GenerateKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = encryption.NewKeyring(other).Decrypt(keyID, ciphertext)
+		Expect(err).To(MatchError(encryption.ErrUnknownKey))
+	})
+
+	It("should fail on a tampered ciphertext", func() {
+		keyring := encryption.NewKeyring(key)
+		keyID, ciphertext, err := keyring.Encrypt([]byte("secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		tampered := []byte(ciphertext)
+		tampered[len(tampered)-2] ^= 1
+
+		_, err = keyring.Decrypt(keyID, string(tampered))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Keys", func() {
+	It("should derive the same key from the same passphrase and salt", func() {
+		k1, err := encryption.DeriveKey("passphrase", []byte("salt"))
+		Expect(err).NotTo(HaveOccurred())
+		k2, err := encryption.DeriveKey("passphrase", []byte("salt"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k1.ID).To(Equal(k2.ID))
+
+		k3, err := encryption.DeriveKey("passphrase", []byte("other"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k3.ID).NotTo(Equal(k1.ID))
+	})
+
+	It("should reject a key of the wrong size", func() {
+		_, err := encryption.NewKey([]byte("short"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should create the key file once and load it afterwards", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "encryption.key")
+
+		created, err := encryption.LoadOrCreateKeyFile(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		loaded, err := encryption.LoadOrCreateKeyFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.ID).To(Equal(created.ID))
+	})
+
+	It("should create the salt file once and load it afterwards", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "encryption.salt")
+
+		created, err := encryption.LoadOrCreateSalt(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		loaded, err := encryption.LoadOrCreateSalt(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(created))
+	})
+
+	It("should not replace a salt file which cannot be read", func() {
+		dir := GinkgoT().TempDir()
+		path := filepath.Join(dir, "encryption.salt")
+		Expect(os.WriteFile(path, []byte("salt"), 0600)).To(Succeed())
+
+		_, err := encryption.LoadOrCreateSalt(filepath.Join(path, "nested"))
+		Expect(err).To(MatchError(ContainSubstring("failed to read salt file")))
+
+		_, err = encryption.LoadOrCreateSalt(dir)
+		Expect(err).To(MatchError(ContainSubstring("failed to read salt file")))
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("salt"))
+	})
+})
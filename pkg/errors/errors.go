@@ -1,10 +1,14 @@
 package errors
 
 import (
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/vmware/govmomi/vim25/soap"
 )
 
 // SourceGoneError indicates the source has been deleted or is no longer available.
@@ -42,3 +46,177 @@ func IsAgentUnauthorizedError(err error) bool {
 	var e *AgentUnauthorizedError
 	return errors.As(err, &e)
 }
+
+// CertificateError indicates the vCenter certificate could not be verified.
+// It carries the certificate presented by vCenter so it can be trusted explicitly.
+type CertificateError struct {
+	Host string
+	// Thumbprint is the SHA-1 thumbprint of the presented certificate, as used by vSphere
+	Thumbprint string
+	// SHA256 is the SHA-256 fingerprint of the presented certificate
+	SHA256   string
+	Subject  string
+	Issuer   string
+	NotAfter time.Time
+	Err      error
+}
+
+func NewCertificateError(host string, cert *x509.Certificate, err error) *CertificateError {
+	return &CertificateError{
+		Host:       host,
+		Thumbprint: soap.ThumbprintSHA1(cert),
+		SHA256:     soap.ThumbprintSHA256(cert),
+		Subject:    cert.Subject.String(),
+		Issuer:     cert.Issuer.String(),
+		NotAfter:   cert.NotAfter,
+		Err:        err,
+	}
+}
+
+func (e *CertificateError) Error() string {
+	return fmt.Sprintf("failed to verify certificate of %s (thumbprint %s): %v", e.Host, e.Thumbprint, e.Err)
+}
+
+func (e *CertificateError) Unwrap() error {
+	return e.Err
+}
+
+// IsCertificateError checks if the error is a CertificateError.
+func IsCertificateError(err error) bool {
+	var e *CertificateError
+	return errors.As(err, &e)
+}
+
+// VCenterUnreachableError indicates the vCenter host could not be connected to.
+type VCenterUnreachableError struct {
+	Host string
+	Err  error
+}
+
+func NewVCenterUnreachableError(host string, err error) *VCenterUnreachableError {
+	return &VCenterUnreachableError{Host: host, Err: err}
+}
+
+func (e *VCenterUnreachableError) Error() string {
+	return fmt.Sprintf("vCenter %s is unreachable: %v", e.Host, e.Err)
+}
+
+func (e *VCenterUnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// IsVCenterUnreachableError checks if the error is a VCenterUnreachableError.
+func IsVCenterUnreachableError(err error) bool {
+	var e *VCenterUnreachableError
+	return errors.As(err, &e)
+}
+
+// VCenterDNSError indicates the vCenter host name could not be resolved.
+type VCenterDNSError struct {
+	Host string
+	Err  error
+}
+
+func NewVCenterDNSError(host string, err error) *VCenterDNSError {
+	return &VCenterDNSError{Host: host, Err: err}
+}
+
+func (e *VCenterDNSError) Error() string {
+	return fmt.Sprintf("failed to resolve vCenter host %s: %v", e.Host, e.Err)
+}
+
+func (e *VCenterDNSError) Unwrap() error {
+	return e.Err
+}
+
+// IsVCenterDNSError checks if the error is a VCenterDNSError.
+func IsVCenterDNSError(err error) bool {
+	var e *VCenterDNSError
+	return errors.As(err, &e)
+}
+
+// VCenterTimeoutError indicates vCenter did not answer in time.
+type VCenterTimeoutError struct {
+	Host string
+	Err  error
+}
+
+func NewVCenterTimeoutError(host string, err error) *VCenterTimeoutError {
+	return &VCenterTimeoutError{Host: host, Err: err}
+}
+
+func (e *VCenterTimeoutError) Error() string {
+	return fmt.Sprintf("timed out connecting to vCenter %s: %v", e.Host, e.Err)
+}
+
+func (e *VCenterTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// IsVCenterTimeoutError checks if the error is a VCenterTimeoutError.
+func IsVCenterTimeoutError(err error) bool {
+	var e *VCenterTimeoutError
+	return errors.As(err, &e)
+}
+
+// InsufficientPrivilegesError indicates the vCenter user lacks privileges required by the agent.
+type InsufficientPrivilegesError struct {
+	User string
+	// Privileges lists the missing privileges, when known
+	Privileges []string
+	Err        error
+}
+
+func NewInsufficientPrivilegesError(user string, privileges []string, err error) *InsufficientPrivilegesError {
+	return &InsufficientPrivilegesError{User: user, Privileges: privileges, Err: err}
+}
+
+func (e *InsufficientPrivilegesError) Error() string {
+	msg := fmt.Sprintf("user %s has insufficient privileges", e.User)
+	if len(e.Privileges) > 0 {
+		msg = fmt.Sprintf("user %s is missing privileges %s", e.User, strings.Join(e.Privileges, ", "))
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *InsufficientPrivilegesError) Unwrap() error {
+	return e.Err
+}
+
+// IsInsufficientPrivilegesError checks if the error is an InsufficientPrivilegesError.
+func IsInsufficientPrivilegesError(err error) bool {
+	var e *InsufficientPrivilegesError
+	return errors.As(err, &e)
+}
+
+// NotVCenterError indicates the endpoint is not a vCenter, e.g. a standalone ESXi host.
+type NotVCenterError struct {
+	Host string
+	// APIType is the API type reported by the endpoint, empty if it is not a vSphere endpoint
+	APIType string
+	Err     error
+}
+
+func NewNotVCenterError(host string, apiType string, err error) *NotVCenterError {
+	return &NotVCenterError{Host: host, APIType: apiType, Err: err}
+}
+
+func (e *NotVCenterError) Error() string {
+	if e.APIType != "" {
+		return fmt.Sprintf("%s is not a vCenter: api type is %s", e.Host, e.APIType)
+	}
+	return fmt.Sprintf("%s is not a vSphere endpoint: %v", e.Host, e.Err)
+}
+
+func (e *NotVCenterError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotVCenterError checks if the error is a NotVCenterError.
+func IsNotVCenterError(err error) bool {
+	var e *NotVCenterError
+	return errors.As(err, &e)
+}